@@ -13,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
+	"github.com/argoproj/argo-rollouts/metricproviders/webmetric"
 	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
 	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
 	"github.com/argoproj/argo-rollouts/utils/defaults"
@@ -67,6 +68,9 @@ func (c *Controller) reconcileAnalysisRun(origRun *v1alpha1.AnalysisRun) *v1alph
 	}
 
 	err = analysisutil.ValidateMetrics(resolvedMetrics)
+	if err == nil {
+		err = webmetric.ValidateMetrics(resolvedMetrics)
+	}
 	if err != nil {
 		message := fmt.Sprintf("Analysis spec invalid: %v", err)
 		logger.Warn(message)
@@ -642,6 +646,11 @@ func assessMetricFailureInconclusiveOrError(metric v1alpha1.Metric, result v1alp
 		phase = v1alpha1.AnalysisPhaseError
 		message = fmt.Sprintf("consecutiveErrors (%d) > consecutiveErrorLimit (%d)", result.ConsecutiveError, consecutiveErrorLimit)
 	}
+
+	if len(result.Measurements) > 0 && analysisutil.IsAbortMeasurement(result.Measurements[len(result.Measurements)-1]) {
+		phase = v1alpha1.AnalysisPhaseFailed
+		message = "a measurement aborted the run"
+	}
 	return phase, message
 }
 
@@ -25,6 +25,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
 	"github.com/argoproj/argo-rollouts/utils/defaults"
 	logutil "github.com/argoproj/argo-rollouts/utils/log"
 )
@@ -594,6 +595,34 @@ func TestAssessMetricStatusFailureLimit(t *testing.T) { // max failures
 	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, assessMetricStatus(metric, result, true))
 }
 
+func TestAssessMetricStatusAbort(t *testing.T) {
+	failureLimit := intstr.FromInt(2)
+	metric := v1alpha1.Metric{
+		Name:         "success-rate",
+		FailureLimit: &failureLimit,
+		Interval:     "60s",
+	}
+	result := v1alpha1.MetricResult{
+		Failed: 1,
+		Count:  1,
+		Measurements: []v1alpha1.Measurement{{
+			Value:      "99",
+			Phase:      v1alpha1.AnalysisPhaseFailed,
+			Metadata:   map[string]string{analysisutil.AbortMetadataKey: "true"},
+			StartedAt:  timePtr(metav1.NewTime(time.Now().Add(-60 * time.Second))),
+			FinishedAt: timePtr(metav1.NewTime(time.Now().Add(-60 * time.Second))),
+		}},
+	}
+	assert.Equal(t, v1alpha1.AnalysisPhaseFailed, assessMetricStatus(metric, result, false))
+	phase, message := assessMetricFailureInconclusiveOrError(metric, result)
+	assert.Equal(t, v1alpha1.AnalysisPhaseFailed, phase)
+	assert.Equal(t, "a measurement aborted the run", message)
+
+	// without the abort marker, the failure is within the failureLimit
+	result.Measurements[0].Metadata = nil
+	assert.Equal(t, v1alpha1.AnalysisPhaseRunning, assessMetricStatus(metric, result, false))
+}
+
 func TestAssessMetricStatusInconclusiveLimit(t *testing.T) {
 	inconclusiveLimit := intstr.FromInt(2)
 	metric := v1alpha1.Metric{
@@ -1952,6 +1981,36 @@ func TestInvalidDryRunConfigThrowsError(t *testing.T) {
 	assert.Equal(t, "Analysis spec invalid: dryRun[0]: Rule didn't match any metric name(s)", newRun.Status.Message)
 }
 
+func TestInvalidWebMetricRetryOnInconclusiveThrowsError(t *testing.T) {
+	f := newFixture(t)
+	defer f.Close()
+	c, _, _ := f.newController(noResyncPeriodFunc)
+
+	now := metav1.Now()
+	run := &v1alpha1.AnalysisRun{
+		Spec: v1alpha1.AnalysisRunSpec{
+			Metrics: []v1alpha1.Metric{{
+				Name:             "success-rate",
+				Interval:         "20s",
+				SuccessCondition: "result[0] > 0.90",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						TimeoutSeconds:      20,
+						RetryOnInconclusive: &v1alpha1.WebMetricRetryOnInconclusive{Count: 3, Delay: "10s"},
+					},
+				},
+			}},
+		},
+		Status: v1alpha1.AnalysisRunStatus{
+			StartedAt: &now,
+			Phase:     v1alpha1.AnalysisPhaseRunning,
+		},
+	}
+	newRun := c.reconcileAnalysisRun(run)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, newRun.Status.Phase)
+	assert.Equal(t, "Analysis spec invalid: metrics[0]: retryOnInconclusive delays of 30s must add up to less than the timeoutSeconds of 20s", newRun.Status.Message)
+}
+
 func TestInvalidMeasurementsRetentionConfigThrowsError(t *testing.T) {
 	f := newFixture(t)
 	defer f.Close()
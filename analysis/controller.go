@@ -18,6 +18,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	batchinformers "k8s.io/client-go/informers/batch/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -78,6 +79,7 @@ type ControllerConfig struct {
 	ArgoProjClientset    clientset.Interface
 	AnalysisRunInformer  informers.AnalysisRunInformer
 	JobInformer          batchinformers.JobInformer
+	ServiceInformer      coreinformers.ServiceInformer
 	ResyncPeriod         time.Duration
 	AnalysisRunWorkQueue workqueue.RateLimitingInterface
 	MetricsServer        *metrics.MetricsServer
@@ -107,8 +109,10 @@ func NewController(cfg ControllerConfig) *Controller {
 	}
 
 	providerFactory := metricproviders.ProviderFactory{
-		KubeClient: controller.kubeclientset,
-		JobLister:  cfg.JobInformer.Lister(),
+		KubeClient:        controller.kubeclientset,
+		JobLister:         cfg.JobInformer.Lister(),
+		AnalysisRunLister: cfg.AnalysisRunInformer.Lister(),
+		ServiceLister:     cfg.ServiceInformer.Lister(),
 	}
 	controller.newProvider = providerFactory.NewProvider
 
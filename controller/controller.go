@@ -202,11 +202,14 @@ func NewAnalysisManager(
 	healthzServer := NewHealthzServer(fmt.Sprintf(listenAddr, healthzPort))
 	analysisRunWorkqueue := workqueue.NewNamedRateLimitingQueue(queue.DefaultArgoRolloutsRateLimiter(), "AnalysisRuns")
 	recorder := record.NewEventRecorder(kubeclientset, metrics.MetricRolloutEventsTotal, metrics.MetricNotificationFailedTotal, metrics.MetricNotificationSuccessTotal, metrics.MetricNotificationSend, nil)
+	// the web metrics read the Services of their ServiceRef from the informer cache
+	servicesInformer := kubeInformerFactory.Core().V1().Services()
 	analysisController := analysis.NewController(analysis.ControllerConfig{
 		KubeClientSet:        kubeclientset,
 		ArgoProjClientset:    argoprojclientset,
 		AnalysisRunInformer:  analysisRunInformer,
 		JobInformer:          jobInformer,
+		ServiceInformer:      servicesInformer,
 		ResyncPeriod:         resyncPeriod,
 		AnalysisRunWorkQueue: analysisRunWorkqueue,
 		MetricsServer:        metricsServer,
@@ -218,6 +221,7 @@ func NewAnalysisManager(
 		metricsServer:                 metricsServer,
 		healthzServer:                 healthzServer,
 		jobSynced:                     jobInformer.Informer().HasSynced,
+		serviceSynced:                 servicesInformer.Informer().HasSynced,
 		analysisRunSynced:             analysisRunInformer.Informer().HasSynced,
 		analysisTemplateSynced:        analysisTemplateInformer.Informer().HasSynced,
 		clusterAnalysisTemplateSynced: clusterAnalysisTemplateInformer.Informer().HasSynced,
@@ -368,6 +372,7 @@ func NewManager(
 		ArgoProjClientset:    argoprojclientset,
 		AnalysisRunInformer:  analysisRunInformer,
 		JobInformer:          jobInformer,
+		ServiceInformer:      servicesInformer,
 		ResyncPeriod:         resyncPeriod,
 		AnalysisRunWorkQueue: analysisRunWorkqueue,
 		MetricsServer:        metricsServer,
@@ -558,7 +563,7 @@ func (c *Manager) startLeading(ctx context.Context, rolloutThreadiness, serviceT
 
 	if c.onlyAnalysisMode {
 		log.Info("Waiting for controller's informer caches to sync")
-		if ok := cache.WaitForCacheSync(ctx.Done(), c.analysisRunSynced, c.analysisTemplateSynced, c.jobSynced); !ok {
+		if ok := cache.WaitForCacheSync(ctx.Done(), c.analysisRunSynced, c.analysisTemplateSynced, c.jobSynced, c.serviceSynced); !ok {
 			log.Fatalf("failed to wait for caches to sync, exiting")
 		}
 		// only wait for cluster scoped informers to sync if we are running in cluster-wide mode
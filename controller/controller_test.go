@@ -160,6 +160,7 @@ func (f *fixture) newManager(t *testing.T) *Manager {
 		ArgoProjClientset:    f.client,
 		AnalysisRunInformer:  i.Argoproj().V1alpha1().AnalysisRuns(),
 		JobInformer:          k8sI.Batch().V1().Jobs(),
+		ServiceInformer:      k8sI.Core().V1().Services(),
 		ResyncPeriod:         noResyncPeriodFunc(),
 		AnalysisRunWorkQueue: analysisRunWorkqueue,
 		MetricsServer:        cm.metricsServer,
@@ -267,7 +268,7 @@ func TestNewManager(t *testing.T) {
 		nil,
 		nil,
 		false,
-		nil,
+		k8sI,
 		nil,
 	)
 
@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/pprof"
+
+	"github.com/argoproj/argo-rollouts/metricproviders/webmetric"
 )
 
 const (
 	ProfilingPath = "/debug/pprof"
 )
 
-// NewPProfServer returns a new pprof server to gather runtime profiling data
+// NewPProfServer returns a new pprof server to gather runtime profiling data, which also serves the request logs of
+// the web metrics
 func NewPProfServer() *http.ServeMux {
 	mux := http.NewServeMux()
 
@@ -19,6 +22,7 @@ func NewPProfServer() *http.ServeMux {
 	mux.HandleFunc(fmt.Sprintf("%s/profile", ProfilingPath), pprof.Profile)
 	mux.HandleFunc(fmt.Sprintf("%s/symbol", ProfilingPath), pprof.Symbol)
 	mux.HandleFunc(fmt.Sprintf("%s/trace", ProfilingPath), pprof.Trace)
+	mux.HandleFunc(webmetric.RequestLogPath, webmetric.ServeRequestLogs)
 
 	return mux
 }
@@ -22,11 +22,13 @@ import (
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
 	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 
 	"github.com/argoproj/argo-rollouts/metricproviders/job"
 	"github.com/argoproj/argo-rollouts/metricproviders/prometheus"
 
 	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	listers "github.com/argoproj/argo-rollouts/pkg/client/listers/rollouts/v1alpha1"
 )
 
 const (
@@ -36,8 +38,10 @@ const (
 )
 
 type ProviderFactory struct {
-	KubeClient kubernetes.Interface
-	JobLister  batchlisters.JobLister
+	KubeClient        kubernetes.Interface
+	JobLister         batchlisters.JobLister
+	AnalysisRunLister listers.AnalysisRunLister
+	ServiceLister     corelisters.ServiceLister
 }
 
 type ProviderFactoryFunc func(logCtx log.Entry, metric v1alpha1.Metric) (metric.Provider, error)
@@ -70,7 +74,11 @@ func (f *ProviderFactory) NewProvider(logCtx log.Entry, namespace string, metric
 		if err != nil {
 			return nil, err
 		}
-		return webmetric.NewWebMetricProvider(logCtx, c, p), nil
+		provider := webmetric.NewWebMetricProvider(logCtx, c, p)
+		provider.SetAnalysisRunLister(f.AnalysisRunLister)
+		provider.SetKubeClient(f.KubeClient)
+		provider.SetServiceLister(f.ServiceLister)
+		return provider, nil
 	case datadog.ProviderType:
 		return datadog.NewDatadogProvider(logCtx, f.KubeClient, namespace, metric)
 	case wavefront.ProviderType:
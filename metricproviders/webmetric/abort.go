@@ -0,0 +1,38 @@
+package webmetric
+
+import (
+	"encoding/json"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+	"github.com/argoproj/argo-rollouts/utils/evaluate"
+	timeutil "github.com/argoproj/argo-rollouts/utils/time"
+)
+
+// checkAbortCondition evaluates the AbortCondition of the metric against the whole body of a JSON response. The
+// responses which are not JSON never match it
+func checkAbortCondition(web *v1alpha1.WebMetric, response *webResponse) (bool, error) {
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return false, nil
+	}
+	vars := map[string]any{
+		"statusCode": response.statusCode,
+		"body":       data,
+	}
+	abort, err := evaluate.EvalConditionWithVars(data, vars, web.AbortCondition)
+	if err != nil {
+		return false, asEvaluationError(err)
+	}
+	return abort, nil
+}
+
+// abortMeasurement fails the measurement, marking it so the analysis run is aborted
+func abortMeasurement(measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	measurement.Phase = v1alpha1.AnalysisPhaseFailed
+	measurement.Message = "received a response matching the abortCondition"
+	measurement.Metadata = map[string]string{analysisutil.AbortMetadataKey: "true"}
+	finishedTime := timeutil.MetaNow()
+	measurement.FinishedAt = &finishedTime
+	return measurement
+}
@@ -0,0 +1,86 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+)
+
+func TestAbortCondition(t *testing.T) {
+	tests := []struct {
+		name            string
+		abortCondition  string
+		response        string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedAbort   bool
+		expectedMessage string
+	}{
+		{
+			name:            "abort marker",
+			abortCondition:  "result.abort == true",
+			response:        `{"abort": true, "value": 0.99}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+			expectedAbort:   true,
+			expectedMessage: "received a response matching the abortCondition",
+		},
+		{
+			name:           "normal response",
+			abortCondition: "result.abort == true",
+			response:       `{"abort": false, "value": 0.99}`,
+			expectedPhase:  v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:           "normal failed response",
+			abortCondition: "result.abort == true",
+			response:       `{"value": 0.5}`,
+			expectedPhase:  v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			name:           "invalid abort condition",
+			abortCondition: "result.abort ==",
+			response:       `{"value": 0.99}`,
+			expectedPhase:  v1alpha1.AnalysisPhaseError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result > 0.9",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:            server.URL,
+						JSONPath:       "{$.value}",
+						AbortCondition: test.abortCondition,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedAbort, analysisutil.IsAbortMeasurement(measurement))
+			if test.expectedMessage != "" {
+				assert.Equal(t, test.expectedMessage, measurement.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// percentileAggregation matches the percentile(n) aggregation
+var percentileAggregation = regexp.MustCompile(`^percentile\(\s*([0-9]+(?:\.[0-9]+)?)\s*\)$`)
+
+// aggregate applies the aggregation to the value selected from the response
+func aggregate(aggregation v1alpha1.WebMetricAggregation, val any) (any, string, error) {
+	if match := percentileAggregation.FindStringSubmatch(string(aggregation)); match != nil {
+		n, _ := strconv.ParseFloat(match[1], 64)
+		result, err := percentile(n, val)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, strconv.FormatFloat(result, 'f', -1, 64), nil
+	}
+	switch aggregation {
+	case v1alpha1.WebMetricAggregationCount, v1alpha1.WebMetricAggregationSize:
+		var count int
+		switch v := val.(type) {
+		case map[string]any:
+			count = len(v)
+		case []any:
+			count = len(v)
+		default:
+			return nil, "", fmt.Errorf("%s aggregation requires a map or an array, got: %v", aggregation, val)
+		}
+		return count, strconv.Itoa(count), nil
+	case v1alpha1.WebMetricAggregationMatches:
+		// the values are all the matches of the JSONPath
+		matches, ok := val.([]any)
+		if !ok {
+			return nil, "", fmt.Errorf("%s aggregation requires a jsonPath", aggregation)
+		}
+		return len(matches), strconv.Itoa(len(matches)), nil
+	case v1alpha1.WebMetricAggregationLength:
+		var length int
+		switch v := val.(type) {
+		case nil:
+			length = 0
+		case string:
+			length = utf8.RuneCountInString(v)
+		case map[string]any:
+			length = len(v)
+		case []any:
+			length = len(v)
+		default:
+			return nil, "", fmt.Errorf("%s aggregation requires a string, a map or an array, got: %v", aggregation, val)
+		}
+		return length, strconv.Itoa(length), nil
+	default:
+		return nil, "", fmt.Errorf("unsupported aggregation: %s", aggregation)
+	}
+}
+
+// percentile returns the nearest-rank nth percentile of the numeric samples, which can be a single number
+func percentile(n float64, val any) (float64, error) {
+	if n < 0 || n > 100 {
+		return 0, fmt.Errorf("percentile must be between 0 and 100, got: %v", n)
+	}
+	var samples []float64
+	switch v := val.(type) {
+	case float64:
+		samples = []float64{v}
+	case []any:
+		samples = make([]float64, 0, len(v))
+		for _, sample := range v {
+			number, ok := sample.(float64)
+			if !ok {
+				return 0, fmt.Errorf("percentile aggregation requires numeric samples, got: %v", sample)
+			}
+			samples = append(samples, number)
+		}
+	default:
+		return 0, fmt.Errorf("percentile aggregation requires an array of numbers, got: %v", val)
+	}
+	if len(samples) == 0 {
+		return 0, errors.New("percentile aggregation requires at least one sample")
+	}
+	sort.Float64s(samples)
+	rank := int(math.Ceil(n / 100 * float64(len(samples))))
+	if rank < 1 {
+		rank = 1
+	}
+	return samples[rank-1], nil
+}
@@ -0,0 +1,347 @@
+package webmetric
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestCountAggregation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"unhealthyPods": {"pod-a": "CrashLoopBackOff", "pod-b": "OOMKilled", "pod-c": "Pending"}, "errors": ["timeout"], "ready": 3, "message": "", "error": "unavailable", "warning": null}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		jsonPath        string
+		aggregation     v1alpha1.WebMetricAggregation
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "count a map",
+			jsonPath:      "{$.unhealthyPods}",
+			aggregation:   v1alpha1.WebMetricAggregationCount,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "3",
+		},
+		{
+			name:          "size of an array",
+			jsonPath:      "{$.errors}",
+			aggregation:   v1alpha1.WebMetricAggregationSize,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "1",
+		},
+		{
+			name:            "count a scalar",
+			jsonPath:        "{$.ready}",
+			aggregation:     v1alpha1.WebMetricAggregationCount,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "count aggregation requires a map or an array, got: 3",
+		},
+		{
+			name:          "length of an empty string",
+			jsonPath:      "{$.message}",
+			aggregation:   v1alpha1.WebMetricAggregationLength,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0",
+		},
+		{
+			name:          "length of a non empty string",
+			jsonPath:      "{$.error}",
+			aggregation:   v1alpha1.WebMetricAggregationLength,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "11",
+		},
+		{
+			name:          "length of null",
+			jsonPath:      "{$.warning}",
+			aggregation:   v1alpha1.WebMetricAggregationLength,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0",
+		},
+		{
+			name:          "length of a map",
+			jsonPath:      "{$.unhealthyPods}",
+			aggregation:   v1alpha1.WebMetricAggregationLength,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "3",
+		},
+		{
+			name:            "length of a number",
+			jsonPath:        "{$.ready}",
+			aggregation:     v1alpha1.WebMetricAggregationLength,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "length aggregation requires a string, a map or an array, got: 3",
+		},
+		{
+			name:            "unsupported aggregation",
+			jsonPath:        "{$.errors}",
+			aggregation:     "avg",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "unsupported aggregation: avg",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result <= 2",
+				FailureCondition: "result > 2",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:         server.URL,
+						JSONPath:    test.jsonPath,
+						Aggregation: test.aggregation,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
+
+func TestLenCondition(t *testing.T) {
+	tests := []struct {
+		response      string
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		{response: `{"error": ""}`, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{response: `{"error": "unavailable"}`, expectedPhase: v1alpha1.AnalysisPhaseFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.response, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "len(result) == 0",
+				FailureCondition: "len(result) > 0",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						JSONPath: "{$.error}",
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+		})
+	}
+}
+
+func TestMatchesAggregation(t *testing.T) {
+	tests := []struct {
+		name            string
+		response        string
+		jsonPointer     string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "no match",
+			response:      `{"alerts": [{"name": "disk", "severity": "warning"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0",
+		},
+		{
+			name:          "a single match is counted rather than its fields",
+			response:      `{"alerts": [{"name": "disk", "severity": "warning"}, {"name": "latency", "severity": "critical"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "1",
+		},
+		{
+			name:          "several matches",
+			response:      `{"alerts": [{"name": "errors", "severity": "critical"}, {"name": "disk", "severity": "warning"}, {"name": "latency", "severity": "critical"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "2",
+		},
+		{
+			name:            "json pointer",
+			response:        `{"alerts": []}`,
+			jsonPointer:     "/alerts",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "matches aggregation requires a jsonPath",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == 0",
+				FailureCondition: "result > 0",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:         server.URL,
+						JSONPath:    "{$.alerts[?(@.severity=='critical')]}",
+						Aggregation: v1alpha1.WebMetricAggregationMatches,
+					},
+				},
+			}
+			if test.jsonPointer != "" {
+				metric.Provider.Web.JSONPath = ""
+				metric.Provider.Web.JSONPointer = test.jsonPointer
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
+
+func TestPercentileAggregation(t *testing.T) {
+	samples := make([]string, 0, 100)
+	entries := make([]string, 0, 100)
+	for _, i := range rand.Perm(100) {
+		samples = append(samples, strconv.Itoa(i+1))
+		entries = append(entries, fmt.Sprintf(`{"latency": %d}`, i+1))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"samples": [%s], "requests": [%s], "single": [250], "empty": [], "invalid": [1, "slow"]}`,
+			strings.Join(samples, ","), strings.Join(entries, ","))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		jsonPath        string
+		aggregation     v1alpha1.WebMetricAggregation
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "p50",
+			jsonPath:      "{$.samples}",
+			aggregation:   "percentile(50)",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "50",
+		},
+		{
+			name:          "p95",
+			jsonPath:      "{$.samples}",
+			aggregation:   "percentile(95)",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "95",
+		},
+		{
+			name:          "p99 of the matched results",
+			jsonPath:      "{$.requests[*].latency}",
+			aggregation:   "percentile(99)",
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "99",
+		},
+		{
+			name:          "p99.9",
+			jsonPath:      "{$.samples}",
+			aggregation:   "percentile(99.9)",
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "100",
+		},
+		{
+			name:          "single sample",
+			jsonPath:      "{$.single}",
+			aggregation:   "percentile(95)",
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "250",
+		},
+		{
+			name:          "single matched result",
+			jsonPath:      "{$.single[*]}",
+			aggregation:   "percentile(50)",
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "250",
+		},
+		{
+			name:            "no samples",
+			jsonPath:        "{$.empty}",
+			aggregation:     "percentile(95)",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "percentile aggregation requires at least one sample",
+		},
+		{
+			name:            "non numeric sample",
+			jsonPath:        "{$.invalid}",
+			aggregation:     "percentile(95)",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "percentile aggregation requires numeric samples, got: slow",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result <= 95",
+				FailureCondition: "result > 95",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:         server.URL,
+						JSONPath:    test.jsonPath,
+						Aggregation: test.aggregation,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
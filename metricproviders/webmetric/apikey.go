@@ -0,0 +1,61 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const redactedAPIKey = "*****"
+
+func validateAPIKey(apiKey *v1alpha1.APIKeyConfig) error {
+	if apiKey.Key == "" || apiKey.Name == "" {
+		return errors.New("missing mandatory parameter in metric for API key setup")
+	}
+	switch apiKey.In {
+	case "", v1alpha1.APIKeyLocationHeader, v1alpha1.APIKeyLocationQuery:
+		return nil
+	default:
+		return fmt.Errorf("unsupported API key location: %s", apiKey.In)
+	}
+}
+
+// setAPIKey adds the API key to a request to the metric endpoint. It is not set by the transport of the client so the
+// key is only sent to the metric endpoint, and not to e.g. the failure webhook
+func setAPIKey(request *http.Request, apiKey *v1alpha1.APIKeyConfig) {
+	if apiKey == nil {
+		return
+	}
+	if apiKey.In == v1alpha1.APIKeyLocationQuery {
+		query := request.URL.Query()
+		query.Set(apiKey.Name, apiKey.Key)
+		request.URL.RawQuery = query.Encode()
+		return
+	}
+	request.Header.Set(apiKey.Name, apiKey.Key)
+}
+
+// redactAPIKey removes the API key from an error, since the errors of a request include its URL
+func redactAPIKey(err error, apiKey *v1alpha1.APIKeyConfig) error {
+	if err == nil {
+		return err
+	}
+	message := redactAPIKeyString(err.Error(), apiKey)
+	if message == err.Error() {
+		return err
+	}
+	return errors.New(message)
+}
+
+// redactAPIKeyString removes the API key from a string, such as a request URL
+func redactAPIKeyString(s string, apiKey *v1alpha1.APIKeyConfig) string {
+	if apiKey == nil || apiKey.Key == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, apiKey.Key, redactedAPIKey)
+	return strings.ReplaceAll(s, url.QueryEscape(apiKey.Key), redactedAPIKey)
+}
@@ -0,0 +1,132 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const apiKey = "my-secret/api+key"
+
+func TestRunWithAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-API-Key") != apiKey && req.URL.Query().Get("api_key") != apiKey {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// query parameters of the metric URL are kept
+		assert.Equal(t, "bar", req.URL.Query().Get("foo"))
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		apiKey        *v1alpha1.APIKeyConfig
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		{
+			name:          "no API key",
+			expectedPhase: v1alpha1.AnalysisPhaseError,
+		},
+		{
+			name:          "header",
+			apiKey:        &v1alpha1.APIKeyConfig{Key: apiKey, Name: "X-API-Key"},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "explicit header",
+			apiKey:        &v1alpha1.APIKeyConfig{Key: apiKey, In: v1alpha1.APIKeyLocationHeader, Name: "X-API-Key"},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "query",
+			apiKey:        &v1alpha1.APIKeyConfig{Key: apiKey, In: v1alpha1.APIKeyLocationQuery, Name: "api_key"},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result.ok",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:            server.URL + "?foo=bar",
+						Preflight:      server.URL + "?foo=bar",
+						Authentication: v1alpha1.Authentication{APIKey: test.apiKey},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			if test.apiKey == nil {
+				// the preflight check is not authenticated either
+				assert.Equal(t, v1alpha1.AnalysisPhaseInconclusive, measurement.Phase)
+				return
+			}
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+		})
+	}
+}
+
+func TestAPIKeyIsRedacted(t *testing.T) {
+	for _, in := range []v1alpha1.APIKeyLocation{v1alpha1.APIKeyLocationHeader, v1alpha1.APIKeyLocationQuery} {
+		metric := v1alpha1.Metric{
+			Name: "foo",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					// unreachable, so the error includes the request URL
+					URL:            "http://127.0.0.1:0/",
+					Authentication: v1alpha1.Authentication{APIKey: &v1alpha1.APIKeyConfig{Key: apiKey, In: in, Name: "api_key"}},
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+		assert.Contains(t, measurement.Message, "127.0.0.1:0")
+		if in == v1alpha1.APIKeyLocationQuery {
+			assert.Contains(t, measurement.Message, "api_key="+redactedAPIKey)
+		}
+		assert.NotContains(t, measurement.Message, "my-secret")
+	}
+}
+
+func TestAPIKeyInvalidConfig(t *testing.T) {
+	newMetric := func(apiKey v1alpha1.APIKeyConfig) v1alpha1.Metric {
+		return v1alpha1.Metric{
+			Name: "foo",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{Authentication: v1alpha1.Authentication{APIKey: &apiKey}},
+			},
+		}
+	}
+
+	_, err := NewWebMetricHttpClient(newMetric(v1alpha1.APIKeyConfig{Name: "X-API-Key"}))
+	assert.EqualError(t, err, "missing mandatory parameter in metric for API key setup")
+
+	_, err = NewWebMetricHttpClient(newMetric(v1alpha1.APIKeyConfig{Key: apiKey}))
+	assert.EqualError(t, err, "missing mandatory parameter in metric for API key setup")
+
+	_, err = NewWebMetricHttpClient(newMetric(v1alpha1.APIKeyConfig{Key: apiKey, Name: "X-API-Key", In: "cookie"}))
+	assert.EqualError(t, err, "unsupported API key location: cookie")
+}
@@ -0,0 +1,60 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasttemplate"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const (
+	argsOpenBracket  = "{{"
+	argsCloseBracket = "}}"
+	argsPrefix       = "args."
+)
+
+// ValidateArgPlaceholders validates that the {{args.<name>}} placeholders of the web metric, including those of its
+// Body and JSONBody, reference the declared args. It only requires the args to be declared, not their values, so
+// misspelled args are all reported when the AnalysisTemplates of a rollout are validated instead of failing the
+// measurements
+func ValidateArgPlaceholders(metric v1alpha1.Metric, args []v1alpha1.Argument) error {
+	if metric.Provider.Web == nil {
+		return nil
+	}
+	webBytes, err := json.Marshal(metric.Provider.Web)
+	if err != nil {
+		return err
+	}
+	t, err := fasttemplate.NewTemplate(string(webBytes), argsOpenBracket, argsCloseBracket)
+	if err != nil {
+		return fmt.Errorf("invalid template in web metric %s: %v", metric.Name, err)
+	}
+
+	declared := make(map[string]bool, len(args))
+	for _, arg := range args {
+		declared[arg.Name] = true
+	}
+	unknown := map[string]bool{}
+	t.ExecuteFuncString(func(w io.Writer, tag string) (int, error) {
+		name, ok := strings.CutPrefix(strings.TrimSpace(tag), argsPrefix)
+		if !ok || !declared[name] {
+			unknown[argsOpenBracket+tag+argsCloseBracket] = true
+		}
+		return 0, nil
+	})
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(unknown))
+	for placeholder := range unknown {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+	return fmt.Errorf("web metric %s references undeclared args: %s", metric.Name, strings.Join(placeholders, ", "))
+}
@@ -0,0 +1,86 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestValidateArgPlaceholders(t *testing.T) {
+	value := "value"
+	args := []v1alpha1.Argument{
+		{Name: "service-name", Value: &value},
+		// args supplied by the rollout have no value in the template
+		{Name: "canary-hash"},
+	}
+
+	tests := []struct {
+		name          string
+		web           *v1alpha1.WebMetric
+		expectedError string
+	}{
+		{
+			name: "valid templates",
+			web: &v1alpha1.WebMetric{
+				URL:      "http://my-server.com/api?service={{ args.service-name }}",
+				Method:   v1alpha1.WebMetricMethodPost,
+				JSONBody: json.RawMessage(`{"service": "{{args.service-name}}", "hash": "{{ args.canary-hash }}"}`),
+				Headers:  []v1alpha1.WebMetricHeader{{Key: "X-Service", Value: "{{args.service-name}}"}},
+			},
+		},
+		{
+			name: "provider placeholders are not args",
+			web: &v1alpha1.WebMetric{
+				URL:    "http://my-server.com/api",
+				Method: v1alpha1.WebMetricMethodPost,
+				Body:   `{"cursor": "$(pagination.cursor)", "run": "$(analysisRun.labels.team)"}`,
+			},
+		},
+		{
+			name: "unknown arg in body",
+			web: &v1alpha1.WebMetric{
+				URL:    "http://my-server.com/api",
+				Method: v1alpha1.WebMetricMethodPost,
+				Body:   `{"service": "{{ args.service-nmae }}"}`,
+			},
+			expectedError: "web metric foo references undeclared args: {{ args.service-nmae }}",
+		},
+		{
+			name: "unknown args in json body",
+			web: &v1alpha1.WebMetric{
+				URL:      "http://my-server.com/api?service={{args.service-name}}",
+				Method:   v1alpha1.WebMetricMethodPost,
+				JSONBody: json.RawMessage(`{"service": "{{args.unknown}}", "other": "{{args.another}}", "again": "{{args.unknown}}"}`),
+			},
+			expectedError: "web metric foo references undeclared args: {{args.another}}, {{args.unknown}}",
+		},
+		{
+			name: "placeholder not referencing an arg",
+			web: &v1alpha1.WebMetric{
+				URL: "http://my-server.com/api?service={{service-name}}",
+			},
+			expectedError: "web metric foo references undeclared args: {{service-name}}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:     "foo",
+				Provider: v1alpha1.MetricProvider{Web: test.web},
+			}
+			err := ValidateArgPlaceholders(metric, args)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+
+	// metrics of other providers are not validated
+	assert.NoError(t, ValidateArgPlaceholders(v1alpha1.Metric{Name: "foo"}, nil))
+}
@@ -0,0 +1,122 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// authentications returns the Authentication of the metric followed by the layered Authentications
+func authentications(web *v1alpha1.WebMetric) []v1alpha1.Authentication {
+	return append([]v1alpha1.Authentication{web.Authentication}, web.Authentications...)
+}
+
+// validateAuthentications validates the authentications of the metric, and that at most one of them sets each
+// header or query parameter
+func validateAuthentications(web *v1alpha1.WebMetric) error {
+	// owners are the schemes setting each header or query parameter
+	owners := map[string]string{}
+	claim := func(target, scheme string) error {
+		if owner, ok := owners[target]; ok {
+			return fmt.Errorf("conflicting authentications: %s and %s both set the %s", owner, scheme, target)
+		}
+		owners[target] = scheme
+		return nil
+	}
+	for _, auth := range authentications(web) {
+		if auth.OAuth2.TokenURL != "" {
+			if err := claim("Authorization header", "OAuth2"); err != nil {
+				return err
+			}
+		}
+		if auth.Basic != nil {
+			if auth.Basic.Username == "" {
+				return errors.New("missing mandatory parameter in metric for Basic authentication setup")
+			}
+			if err := claim("Authorization header", "Basic"); err != nil {
+				return err
+			}
+		}
+		if auth.Bearer != nil {
+			if auth.Bearer.TokenFile == "" {
+				return errors.New("missing mandatory parameter in metric for Bearer authentication setup")
+			}
+			if err := claim("Authorization header", "Bearer"); err != nil {
+				return err
+			}
+		}
+		if auth.APIKey != nil {
+			if err := validateAPIKey(auth.APIKey); err != nil {
+				return err
+			}
+			target := fmt.Sprintf("%s header", http.CanonicalHeaderKey(auth.APIKey.Name))
+			if auth.APIKey.In == v1alpha1.APIKeyLocationQuery {
+				target = fmt.Sprintf("%s query parameter", auth.APIKey.Name)
+			}
+			if err := claim(target, "API key"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// oauth2Config returns the OAuth2 config of the authentications, if any
+func oauth2Config(web *v1alpha1.WebMetric) *v1alpha1.OAuth2Config {
+	for _, auth := range authentications(web) {
+		if auth.OAuth2.TokenURL != "" {
+			return &auth.OAuth2
+		}
+	}
+	return nil
+}
+
+// oauth2Scopes returns the scopes of the OAuth2 config. An entry may hold several scopes separated by spaces, so an
+// argument can provide the scopes of an environment, whatever their number. The empty entries, e.g. of an argument
+// without scopes, are dropped
+func oauth2Scopes(oauth2Cfg *v1alpha1.OAuth2Config) []string {
+	var scopes []string
+	for _, entry := range oauth2Cfg.Scopes {
+		scopes = append(scopes, strings.Fields(entry)...)
+	}
+	return scopes
+}
+
+// setCredentials adds the API keys, Basic credentials and bearer tokens of the authentications to a request to the
+// metric endpoint. Like the API keys, the Basic credentials and bearer tokens are not set by the transport of the
+// client, so they are only sent to the metric endpoint
+func setCredentials(request *http.Request, web *v1alpha1.WebMetric) error {
+	for _, auth := range authentications(web) {
+		setAPIKey(request, auth.APIKey)
+		if auth.Basic != nil {
+			request.SetBasicAuth(auth.Basic.Username, auth.Basic.Password)
+		}
+		if auth.Bearer != nil {
+			token, err := tokenFiles.token(auth.Bearer.TokenFile)
+			if err != nil {
+				return err
+			}
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return nil
+}
+
+// redactCredentials removes the API keys of the authentications from an error
+func redactCredentials(err error, web *v1alpha1.WebMetric) error {
+	for _, auth := range authentications(web) {
+		err = redactAPIKey(err, auth.APIKey)
+	}
+	return err
+}
+
+// redactCredentialsString removes the API keys of the authentications from a string, such as a request URL
+func redactCredentialsString(s string, web *v1alpha1.WebMetric) string {
+	for _, auth := range authentications(web) {
+		s = redactAPIKeyString(s, auth.APIKey)
+	}
+	return s
+}
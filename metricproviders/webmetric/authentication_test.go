@@ -0,0 +1,197 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+)
+
+func TestLayeredAuthentications(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != "gateway" || password != "gateway-secret" {
+			rw.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		if req.Header.Get("X-API-Key") != "app-secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result == true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.ok}",
+				Authentication: v1alpha1.Authentication{
+					Basic: &v1alpha1.BasicAuthConfig{Username: "gateway", Password: "gateway-secret"},
+				},
+				Authentications: []v1alpha1.Authentication{{
+					APIKey: &v1alpha1.APIKeyConfig{Key: "app-secret", Name: "X-API-Key"},
+				}},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "true", measurement.Value)
+}
+
+func TestConflictingAuthentications(t *testing.T) {
+	basic := v1alpha1.Authentication{Basic: &v1alpha1.BasicAuthConfig{Username: "user", Password: "password"}}
+	oauth2 := v1alpha1.Authentication{OAuth2: v1alpha1.OAuth2Config{TokenURL: "http://tokenurl", ClientID: "id", ClientSecret: "secret"}}
+	apiKey := func(name string, in v1alpha1.APIKeyLocation) v1alpha1.Authentication {
+		return v1alpha1.Authentication{APIKey: &v1alpha1.APIKeyConfig{Key: "key", Name: name, In: in}}
+	}
+
+	tests := []struct {
+		name            string
+		authentication  v1alpha1.Authentication
+		authentications []v1alpha1.Authentication
+		expectedError   string
+	}{
+		{
+			name:            "Basic and OAuth2",
+			authentication:  basic,
+			authentications: []v1alpha1.Authentication{oauth2},
+			expectedError:   "conflicting authentications: Basic and OAuth2 both set the Authorization header",
+		},
+		{
+			name:            "Basic and an API key in the Authorization header",
+			authentication:  basic,
+			authentications: []v1alpha1.Authentication{apiKey("authorization", v1alpha1.APIKeyLocationHeader)},
+			expectedError:   "conflicting authentications: Basic and API key both set the Authorization header",
+		},
+		{
+			name:            "two API keys in the same query parameter",
+			authentications: []v1alpha1.Authentication{apiKey("key", v1alpha1.APIKeyLocationQuery), apiKey("key", v1alpha1.APIKeyLocationQuery)},
+			expectedError:   "conflicting authentications: API key and API key both set the key query parameter",
+		},
+		{
+			name:            "API keys in a header and a query parameter of the same name",
+			authentications: []v1alpha1.Authentication{apiKey("key", v1alpha1.APIKeyLocationHeader), apiKey("key", v1alpha1.APIKeyLocationQuery)},
+		},
+		{
+			name:            "Basic without a username",
+			authentications: []v1alpha1.Authentication{{Basic: &v1alpha1.BasicAuthConfig{Password: "password"}}},
+			expectedError:   "missing mandatory parameter in metric for Basic authentication setup",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name: "foo",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:             "http://example.com",
+						Authentication:  test.authentication,
+						Authentications: test.authentications,
+					},
+				},
+			}
+			_, err := NewWebMetricHttpClient(metric)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestTemplatedOAuth2Scopes(t *testing.T) {
+	var scopes []string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.NoError(t, req.ParseForm())
+		scopes = strings.Fields(req.PostForm.Get("scope"))
+		mockOAuthOKResponse(rw, req, AccessToken)
+	}))
+	defer tokenServer.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	template := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result == true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.ok}",
+				Authentication: v1alpha1.Authentication{
+					OAuth2: v1alpha1.OAuth2Config{
+						TokenURL:     tokenServer.URL + "/token",
+						ClientID:     "myClientID",
+						ClientSecret: "mySecret",
+						Scopes:       []string{"metrics:read", "{{ args.env-scopes }}", "{{ args.extra-scopes }}"},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		envScopes      string
+		extraScopes    string
+		expectedScopes []string
+	}{
+		{
+			name:           "single scope",
+			envScopes:      "staging:read",
+			expectedScopes: []string{"metrics:read", "staging:read"},
+		},
+		{
+			name:           "several scopes",
+			envScopes:      "prod:read  prod:write",
+			extraScopes:    "audit",
+			expectedScopes: []string{"metrics:read", "prod:read", "prod:write", "audit"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scopes = nil
+			// the args are resolved by the controller when the analysis run is created
+			metric, err := analysisutil.ResolveMetricArgs(template, []v1alpha1.Argument{
+				{Name: "env-scopes", Value: pointer.StringPtr(test.envScopes)},
+				{Name: "extra-scopes", Value: pointer.StringPtr(test.extraScopes)},
+			})
+			assert.NoError(t, err)
+
+			jsonparser, err := NewWebMetricJsonParser(*metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(*metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), *metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedScopes, scopes)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package webmetric
+
+import (
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// addBand adds the lowerBound and upperBound of the Band of the metric, selected from the data, to the vars of the
+// conditions
+func addBand(vars map[string]any, web *v1alpha1.WebMetric, data any) error {
+	root, err := unwrapRoot(web, data)
+	if err != nil {
+		return err
+	}
+	lower, err := bandBound(web, "lowerBound", web.Band.LowerBoundJsonPath, root)
+	if err != nil {
+		return err
+	}
+	upper, err := bandBound(web, "upperBound", web.Band.UpperBoundJsonPath, root)
+	if err != nil {
+		return err
+	}
+	if lower > upper {
+		return fmt.Errorf("band lowerBound %v is greater than its upperBound %v", lower, upper)
+	}
+	vars["lowerBound"] = lower
+	vars["upperBound"] = upper
+	return nil
+}
+
+func bandBound(web *v1alpha1.WebMetric, name, path string, data any) (float64, error) {
+	parser, err := newJSONParser(web, name, path)
+	if err != nil {
+		return 0, fmt.Errorf("invalid band %sJsonPath: %v", name, err)
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return 0, fmt.Errorf("Could not find band %sJsonPath in body: %s", name, err)
+	}
+	val, _, err := getValue(fullResults)
+	if err != nil {
+		return 0, fmt.Errorf("band %s: %v", name, err)
+	}
+	bound, ok := toFloat(val)
+	if !ok {
+		return 0, fmt.Errorf("band %s must be a number, got: %v", name, val)
+	}
+	return bound, nil
+}
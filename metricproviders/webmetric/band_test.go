@@ -0,0 +1,98 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunWithBand(t *testing.T) {
+	tests := []struct {
+		name            string
+		response        string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMessage string
+	}{
+		{
+			name:          "in band",
+			response:      `{"value": 104, "stats": {"lower": 80, "upper": 120}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "on the bound",
+			response:      `{"value": 120, "stats": {"lower": 80, "upper": 120}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "below the band",
+			response:      `{"value": 79.5, "stats": {"lower": 80, "upper": 120}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			name:          "above the band",
+			response:      `{"value": 150, "stats": {"lower": 80, "upper": 120}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			name:            "missing bound",
+			response:        `{"value": 104, "stats": {"lower": 80}}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "Could not find band upperBoundJsonPath in body: upper is not found",
+		},
+		{
+			name:            "non numeric bound",
+			response:        `{"value": 104, "stats": {"lower": "80", "upper": 120}}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "band lowerBound must be a number, got: 80",
+		},
+		{
+			name:            "inverted band",
+			response:        `{"value": 104, "stats": {"lower": 120, "upper": 80}}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "band lowerBound 120 is greater than its upperBound 80",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result >= lowerBound && result <= upperBound",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						JSONPath: "{$.value}",
+						Band: &v1alpha1.WebMetricBand{
+							LowerBoundJsonPath: "{$.stats.lower}",
+							UpperBoundJsonPath: "{$.stats.upper}",
+						},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			if test.expectedPhase == v1alpha1.AnalysisPhaseError {
+				assert.Equal(t, ErrorCauseParse, measurement.Metadata[ErrorCauseMetadataKey])
+			}
+		})
+	}
+}
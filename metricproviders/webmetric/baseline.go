@@ -0,0 +1,42 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// fetchBaseline sends the baseline request of the metric and returns the value selected from its response
+func (p *Provider) fetchBaseline(metric v1alpha1.Metric, body []byte) (any, error) {
+	baseline := metric.Provider.Web.Baseline
+	jsonPath := baseline.JSONPath
+	if jsonPath == "" {
+		jsonPath = metric.Provider.Web.JSONPath
+	}
+	parser, err := newJSONParser(metric.Provider.Web, "baseline", jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseline jsonPath: %v", err)
+	}
+
+	response, err := p.fetch(metric, baseline.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("baseline request failed: %w", err)
+	}
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return nil, &parseError{err: fmt.Errorf("baseline response is not a JSON document: %v", err)}
+	}
+	if data, err = unwrapRoot(metric.Provider.Web, data); err != nil {
+		return nil, &parseError{err: fmt.Errorf("baseline: %v", err)}
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("Could not find baseline jsonPath in body: %s", err)}
+	}
+	val, _, err := getValue(fullResults)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("baseline: %v", err)}
+	}
+	return val, nil
+}
@@ -0,0 +1,94 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestBaseline(t *testing.T) {
+	tests := []struct {
+		name             string
+		canaryErrorRate  string
+		baselineJSONPath string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedMessage  string
+	}{
+		{
+			name:            "within the tolerance",
+			canaryErrorRate: "0.105",
+			expectedPhase:   v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:            "beyond the tolerance",
+			canaryErrorRate: "0.2",
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			name:             "baseline with its own jsonPath",
+			canaryErrorRate:  "0.105",
+			baselineJSONPath: "{$.baseline.errorRate}",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:             "missing baseline value",
+			canaryErrorRate:  "0.105",
+			baselineJSONPath: "{$.other}",
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMessage:  "Could not find baseline jsonPath in body: other is not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("Authorization") != "Bearer token" {
+					rw.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				rw.Header().Set("Content-Type", "application/json")
+				switch req.URL.Path {
+				case "/canary":
+					fmt.Fprintf(rw, `{"errorRate": %s}`, test.canaryErrorRate)
+				case "/baseline":
+					fmt.Fprint(rw, `{"errorRate": 0.1, "baseline": {"errorRate": 0.1}}`)
+				default:
+					rw.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result <= baseline * 1.1",
+				FailureCondition: "result > baseline * 1.1",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL + "/canary",
+						Headers:  []v1alpha1.WebMetricHeader{{Key: "Authorization", Value: "Bearer token"}},
+						JSONPath: "{$.errorRate}",
+						Baseline: &v1alpha1.WebMetricBaseline{
+							URL:      server.URL + "/baseline",
+							JSONPath: test.baselineJSONPath,
+						},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenFileTTL is how long a token read from a file is used before the file is read again. Like the clients of the
+// Kubernetes API, projected service account tokens are read at most a minute after they rotate, well before the old
+// token expires
+const tokenFileTTL = time.Minute
+
+// tokenFiles caches the tokens read from files by the web metric clients. It is shared by all providers since a
+// provider is created for every measurement
+var tokenFiles = newTokenFileCache(os.ReadFile, os.Stat, time.Now)
+
+type tokenFileCache struct {
+	mu       sync.Mutex
+	entries  map[string]tokenFileCacheEntry
+	readFile func(name string) ([]byte, error)
+	stat     func(name string) (os.FileInfo, error)
+	now      func() time.Time
+}
+
+type tokenFileCacheEntry struct {
+	token   string
+	modTime time.Time
+	readAt  time.Time
+}
+
+func newTokenFileCache(readFile func(name string) ([]byte, error), stat func(name string) (os.FileInfo, error), now func() time.Time) *tokenFileCache {
+	return &tokenFileCache{
+		entries:  map[string]tokenFileCacheEntry{},
+		readFile: readFile,
+		stat:     stat,
+		now:      now,
+	}
+}
+
+// token returns the token held by the file. The file is read again when it is older than the ttl, or when it was
+// modified since it was read, which is how a projected volume rotates it
+func (c *tokenFileCache) token(path string) (string, error) {
+	info, err := c.stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bearer tokenFile: %w", err)
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && c.now().Sub(entry.readAt) < tokenFileTTL {
+		return entry.token, nil
+	}
+
+	data, err := c.readFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bearer tokenFile: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.New("bearer tokenFile is empty")
+	}
+	c.mu.Lock()
+	c.entries[path] = tokenFileCacheEntry{token: token, modTime: info.ModTime(), readAt: c.now()}
+	c.mu.Unlock()
+	return token, nil
+}
@@ -0,0 +1,134 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestTokenFileCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("first\n"), 0600))
+
+	reads := 0
+	now := time.Now()
+	cache := newTokenFileCache(func(name string) ([]byte, error) {
+		reads++
+		return os.ReadFile(name)
+	}, os.Stat, func() time.Time { return now })
+
+	token, err := cache.token(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", token)
+
+	// an unmodified file is not read again until the ttl passes
+	token, err = cache.token(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", token)
+	assert.Equal(t, 1, reads)
+	now = now.Add(tokenFileTTL)
+	_, err = cache.token(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, reads)
+
+	// a modified file is read again
+	assert.NoError(t, os.WriteFile(path, []byte("second"), 0600))
+	assert.NoError(t, os.Chtimes(path, now, now.Add(time.Second)))
+	token, err = cache.token(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", token)
+	assert.Equal(t, 3, reads)
+
+	assert.NoError(t, os.WriteFile(path, []byte(" \n"), 0600))
+	assert.NoError(t, os.Chtimes(path, now, now.Add(2*time.Second)))
+	_, err = cache.token(path)
+	assert.EqualError(t, err, "bearer tokenFile is empty")
+
+	_, err = cache.token(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to read bearer tokenFile: ")
+}
+
+func TestRunWithBearerTokenFile(t *testing.T) {
+	tokenFiles = newTokenFileCache(os.ReadFile, os.Stat, time.Now)
+	defer func() {
+		tokenFiles = newTokenFileCache(os.ReadFile, os.Stat, time.Now)
+	}()
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		authorization = req.Header.Get("Authorization")
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result == true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.ok}",
+				Authentication: v1alpha1.Authentication{
+					Bearer: &v1alpha1.BearerAuthConfig{TokenFile: path},
+				},
+			},
+		},
+	}
+	run := func() v1alpha1.Measurement {
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+		return provider.Run(newAnalysisRun(), metric)
+	}
+
+	measurement := run()
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Contains(t, measurement.Message, "failed to read bearer tokenFile: ")
+
+	assert.NoError(t, os.WriteFile(path, []byte("first-token\n"), 0600))
+	measurement = run()
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "Bearer first-token", authorization)
+
+	// the token rotates between the runs
+	assert.NoError(t, os.WriteFile(path, []byte("second-token\n"), 0600))
+	modTime := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	measurement = run()
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "Bearer second-token", authorization)
+}
+
+func TestBearerAuthenticationValidation(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            "http://example.com",
+				Authentication: v1alpha1.Authentication{Bearer: &v1alpha1.BearerAuthConfig{}},
+			},
+		},
+	}
+	_, err := NewWebMetricHttpClient(metric)
+	assert.EqualError(t, err, "missing mandatory parameter in metric for Bearer authentication setup")
+
+	metric.Provider.Web.Authentication = v1alpha1.Authentication{
+		Basic:  &v1alpha1.BasicAuthConfig{Username: "user", Password: "password"},
+		Bearer: &v1alpha1.BearerAuthConfig{TokenFile: "/var/run/secrets/tokens/metrics"},
+	}
+	_, err = NewWebMetricHttpClient(metric)
+	assert.EqualError(t, err, "conflicting authentications: Basic and Bearer both set the Authorization header")
+}
@@ -0,0 +1,49 @@
+package webmetric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// SetKubeClient sets the client the provider gets the ConfigMaps of the BodyFrom of the metrics with
+func (p *Provider) SetKubeClient(kubeclientset kubernetes.Interface) {
+	p.kubeclientset = kubeclientset
+}
+
+// loadBodyFrom loads the body referenced by the BodyFrom of the metric from the namespace of the AnalysisRun. The
+// ConfigMap is only got when the measurement is taken, so the providers built for other purposes, such as the garbage
+// collection of the measurements, do not read it, and the controller does not watch the ConfigMaps of the cluster. It
+// is a no-op for metrics without a BodyFrom
+func (p *Provider) loadBodyFrom(namespace string, metric v1alpha1.Metric) error {
+	bodyFrom := metric.Provider.Web.BodyFrom
+	if bodyFrom == nil {
+		return nil
+	}
+	if metric.Provider.Web.Body != "" || metric.Provider.Web.JSONBody != nil {
+		return errors.New("use either Body, JSONBody or BodyFrom; they cannot be combined for WebMetric payload")
+	}
+	ref := bodyFrom.ConfigMapKeyRef
+	if ref == nil {
+		return errors.New("BodyFrom of WebMetric requires a configMapKeyRef")
+	}
+	if p.kubeclientset == nil {
+		return errors.New("BodyFrom of WebMetric requires the kube client of the provider")
+	}
+
+	configMap, err := p.kubeclientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	body, ok := configMap.Data[ref.Key]
+	if !ok {
+		return fmt.Errorf("key '%s' does not exist in ConfigMap '%s'", ref.Key, ref.Name)
+	}
+	p.bodyFrom = []byte(body)
+	return nil
+}
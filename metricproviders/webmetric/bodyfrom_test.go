@@ -0,0 +1,166 @@
+package webmetric
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestLoadBodyFrom(t *testing.T) {
+	var requestBody string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		bodyBytes, _ := io.ReadAll(req.Body)
+		requestBody = string(bodyBytes)
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "queries", Namespace: "ns"},
+		Data:       map[string]string{"errors": `{"query": "sum(errors)"}`},
+	}
+	kubeclientset := k8sfake.NewSimpleClientset(configMap)
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				Method: v1alpha1.WebMetricMethodPost,
+				URL:    server.URL,
+				BodyFrom: &v1alpha1.WebMetricBodyFrom{
+					ConfigMapKeyRef: &v1alpha1.ConfigMapKeyRef{Name: "queries", Key: "errors"},
+				},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+	provider.SetKubeClient(kubeclientset)
+	run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+
+	measurement := provider.Run(run, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, `{"query": "sum(errors)"}`, requestBody)
+
+	// the ConfigMap is got at every measurement
+	updated := configMap.DeepCopy()
+	updated.Data["errors"] = `{"query": "sum(errors_total)"}`
+	_, err = kubeclientset.CoreV1().ConfigMaps("ns").Update(context.TODO(), updated, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	measurement = provider.Run(run, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, `{"query": "sum(errors_total)"}`, requestBody)
+}
+
+func TestLoadBodyFromErrors(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "queries", Namespace: "ns"},
+		Data:       map[string]string{"errors": `{"query": "sum(errors)"}`},
+	}
+	kubeclientset := k8sfake.NewSimpleClientset(configMap)
+
+	tests := []struct {
+		name                 string
+		web                  v1alpha1.WebMetric
+		expectedErrorMessage string
+	}{
+		{
+			name: "missing key",
+			web: v1alpha1.WebMetric{
+				Method:   v1alpha1.WebMetricMethodPost,
+				BodyFrom: &v1alpha1.WebMetricBodyFrom{ConfigMapKeyRef: &v1alpha1.ConfigMapKeyRef{Name: "queries", Key: "latency"}},
+			},
+			expectedErrorMessage: "key 'latency' does not exist in ConfigMap 'queries'",
+		},
+		{
+			name: "missing ConfigMap",
+			web: v1alpha1.WebMetric{
+				Method:   v1alpha1.WebMetricMethodPost,
+				BodyFrom: &v1alpha1.WebMetricBodyFrom{ConfigMapKeyRef: &v1alpha1.ConfigMapKeyRef{Name: "other", Key: "errors"}},
+			},
+			expectedErrorMessage: `configmaps "other" not found`,
+		},
+		{
+			name: "missing configMapKeyRef",
+			web: v1alpha1.WebMetric{
+				Method:   v1alpha1.WebMetricMethodPost,
+				BodyFrom: &v1alpha1.WebMetricBodyFrom{},
+			},
+			expectedErrorMessage: "BodyFrom of WebMetric requires a configMapKeyRef",
+		},
+		{
+			name: "combined with an inline body",
+			web: v1alpha1.WebMetric{
+				Method:   v1alpha1.WebMetricMethodPost,
+				Body:     "query",
+				BodyFrom: &v1alpha1.WebMetricBodyFrom{ConfigMapKeyRef: &v1alpha1.ConfigMapKeyRef{Name: "queries", Key: "errors"}},
+			},
+			expectedErrorMessage: "use either Body, JSONBody or BodyFrom; they cannot be combined for WebMetric payload",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			web := test.web
+			metric := v1alpha1.Metric{Name: "foo", Provider: v1alpha1.MetricProvider{Web: &web}}
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), http.DefaultClient, nil)
+			provider.SetKubeClient(kubeclientset)
+			err := provider.loadBodyFrom("ns", metric)
+			assert.EqualError(t, err, test.expectedErrorMessage)
+		})
+	}
+
+	// BodyFrom cannot be sent with a GET request
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{
+			BodyFrom: &v1alpha1.WebMetricBodyFrom{ConfigMapKeyRef: &v1alpha1.ConfigMapKeyRef{Name: "queries", Key: "errors"}},
+		}},
+	}
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), http.DefaultClient, nil)
+	provider.SetKubeClient(kubeclientset)
+	measurement := provider.Run(&v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, "BodyFrom can only be used with POST or PUT WebMetric Method types", measurement.Message)
+}
+
+func TestBodyFromReadOnlyForMeasurements(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{
+			Method:     v1alpha1.WebMetricMethodPost,
+			BodyFrom:   &v1alpha1.WebMetricBodyFrom{ConfigMapKeyRef: &v1alpha1.ConfigMapKeyRef{Name: "queries", Key: "errors"}},
+			URL:        "/api/v1/measurement",
+			ServiceRef: &v1alpha1.WebMetricServiceRef{Name: "metrics"},
+		}},
+	}
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), http.DefaultClient, nil)
+	kubeclientset := k8sfake.NewSimpleClientset()
+	provider.SetKubeClient(kubeclientset)
+	provider.SetServiceLister(newServiceLister(t))
+	run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+
+	// the provider built to garbage collect the measurements does not read the missing ConfigMap and Service
+	assert.NoError(t, provider.GarbageCollect(run, metric, 1))
+	assert.Empty(t, kubeclientset.Actions())
+
+	measurement := provider.Run(run, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, `configmaps "queries" not found`, measurement.Message)
+}
@@ -0,0 +1,29 @@
+package webmetric
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// bodyDeadline closes a response body which is not read to its end before the deadline, so that reading it fails
+// instead of waiting for a slow endpoint until the timeout of the whole request
+type bodyDeadline struct {
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+func startBodyDeadline(body io.Closer, timeout time.Duration) *bodyDeadline {
+	d := &bodyDeadline{}
+	d.timer = time.AfterFunc(timeout, func() {
+		d.expired.Store(true)
+		body.Close()
+	})
+	return d
+}
+
+// stop stops the deadline, and returns whether it had expired
+func (d *bodyDeadline) stop() bool {
+	d.timer.Stop()
+	return d.expired.Load()
+}
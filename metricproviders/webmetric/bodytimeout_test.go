@@ -0,0 +1,83 @@
+package webmetric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestResponseBodyTimeout(t *testing.T) {
+	tests := []struct {
+		name            string
+		drip            time.Duration
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMessage string
+	}{
+		{
+			name:          "body received in time",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:            "body dripped slowly",
+			drip:            500 * time.Millisecond,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "the response body was not received within responseBodyTimeoutSeconds of 1s",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			done := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				// the headers are sent at once, and the body a byte at a time
+				rw.Header().Set("Content-Type", "application/json")
+				rw.WriteHeader(http.StatusOK)
+				rw.(http.Flusher).Flush()
+				for _, b := range []byte(`{"value": 1}`) {
+					select {
+					case <-done:
+						return
+					case <-time.After(test.drip):
+					}
+					rw.Write([]byte{b})
+					rw.(http.Flusher).Flush()
+				}
+			}))
+			defer server.Close()
+			defer close(done)
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == 1",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:                        server.URL,
+						JSONPath:                   "{$.value}",
+						TimeoutSeconds:             30,
+						ResponseBodyTimeoutSeconds: 1,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			start := time.Now()
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.Less(t, time.Since(start), 3*time.Second)
+			if test.expectedPhase == v1alpha1.AnalysisPhaseError {
+				assert.Equal(t, ErrorCauseConnection, measurement.Metadata[ErrorCauseMetadataKey])
+			}
+		})
+	}
+}
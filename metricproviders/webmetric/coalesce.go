@@ -0,0 +1,84 @@
+package webmetric
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// inflight holds the coalesced requests in flight. It is shared by all providers since a provider is created for
+// every measurement
+var inflight singleflight.Group
+
+// requestSignature identifies the requests which can share a response. Besides the request itself, it includes every
+// setting of the metric the client is built from, so a request is never answered by a response received over another
+// transport, e.g. without the TLS pins or the proxy of the metric
+type requestSignature struct {
+	Method                     string                       `json:"method"`
+	URL                        string                       `json:"url"`
+	Header                     http.Header                  `json:"header"`
+	Body                       []byte                       `json:"body"`
+	Authentication             []v1alpha1.Authentication    `json:"authentication"`
+	Insecure                   bool                         `json:"insecure"`
+	InsecureHosts              []string                     `json:"insecureHosts"`
+	TLSConfig                  *v1alpha1.WebMetricTLSConfig `json:"tlsConfig"`
+	ProxyUrl                   string                       `json:"proxyUrl"`
+	ForceHttp1                 bool                         `json:"forceHttp1"`
+	DisableKeepAlives          bool                         `json:"disableKeepAlives"`
+	DNSCacheTTLSeconds         int64                        `json:"dnsCacheTTLSeconds"`
+	DialTimeoutSeconds         int64                        `json:"dialTimeoutSeconds"`
+	TLSHandshakeTimeoutSeconds int64                        `json:"tlsHandshakeTimeoutSeconds"`
+	TimeoutSeconds             int64                        `json:"timeoutSeconds"`
+	MaxRedirects               *int64                       `json:"maxRedirects"`
+	Location                   bool                         `json:"location"`
+}
+
+// coalescedDo sends the request, unless an identical request is already in flight, in which case its response is
+// returned instead
+func (p *Provider) coalescedDo(metric v1alpha1.Metric, request *http.Request, body []byte) (*webResponse, error) {
+	key, err := requestKey(metric, request, body)
+	if err != nil {
+		return nil, err
+	}
+	response, err, _ := inflight.Do(key, func() (any, error) {
+		return p.do(metric, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.(*webResponse), nil
+}
+
+// requestKey returns a digest of the request signature, so the credentials it includes are not kept in memory as is
+func requestKey(metric v1alpha1.Metric, request *http.Request, body []byte) (string, error) {
+	web := metric.Provider.Web
+	signature, err := json.Marshal(requestSignature{
+		Method:                     request.Method,
+		URL:                        request.URL.String(),
+		Header:                     request.Header,
+		Body:                       body,
+		Authentication:             authentications(web),
+		Insecure:                   web.Insecure,
+		InsecureHosts:              web.InsecureHosts,
+		TLSConfig:                  web.TLSConfig,
+		ProxyUrl:                   web.ProxyUrl,
+		ForceHttp1:                 web.ForceHttp1,
+		DisableKeepAlives:          web.DisableKeepAlives,
+		DNSCacheTTLSeconds:         web.DNSCacheTTLSeconds,
+		DialTimeoutSeconds:         web.DialTimeoutSeconds,
+		TLSHandshakeTimeoutSeconds: web.TLSHandshakeTimeoutSeconds,
+		TimeoutSeconds:             web.TimeoutSeconds,
+		MaxRedirects:               web.MaxRedirects,
+		Location:                   web.Location != nil,
+	})
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(signature)
+	return hex.EncodeToString(digest[:]), nil
+}
@@ -0,0 +1,168 @@
+package webmetric
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunCoalescesConcurrentRequests(t *testing.T) {
+	const concurrentRuns = 10
+
+	tests := []struct {
+		coalesce      bool
+		expectedCalls int32
+	}{
+		{coalesce: true, expectedCalls: 1},
+		{coalesce: false, expectedCalls: concurrentRuns},
+	}
+
+	for _, test := range tests {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			calls.Add(1)
+			<-release
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, `{"ok": true}`)
+		}))
+
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:      server.URL,
+					Coalesce: test.coalesce,
+				},
+			},
+		}
+
+		var wg sync.WaitGroup
+		measurements := make([]v1alpha1.Measurement, concurrentRuns)
+		for i := 0; i < concurrentRuns; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				jsonparser, err := NewWebMetricJsonParser(metric)
+				assert.NoError(t, err)
+				client, err := NewWebMetricHttpClient(metric)
+				assert.NoError(t, err)
+				provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+				measurements[i] = provider.Run(newAnalysisRun(), metric)
+			}(i)
+		}
+		// let all the requests get in flight before responding
+		time.Sleep(200 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		server.Close()
+
+		assert.Equal(t, test.expectedCalls, calls.Load(), "coalesce: %v", test.coalesce)
+		for _, measurement := range measurements {
+			assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase)
+			assert.Equal(t, `{"ok":true}`, measurement.Value)
+		}
+	}
+}
+
+func TestRunDoesNotCoalesceRequestsOverOtherTransports(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+		<-release
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+
+	newMetric := func(tlsConfig *v1alpha1.WebMetricTLSConfig) v1alpha1.Metric {
+		return v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:       server.URL,
+					Coalesce:  true,
+					TLSConfig: tlsConfig,
+				},
+			},
+		}
+	}
+	run := func(metric v1alpha1.Metric, measurement *v1alpha1.Measurement, wg *sync.WaitGroup) {
+		defer wg.Done()
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+		*measurement = provider.Run(newAnalysisRun(), metric)
+	}
+
+	var wg sync.WaitGroup
+	var pinned, unpinned v1alpha1.Measurement
+	wg.Add(2)
+	go run(newMetric(&v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{hex.EncodeToString(fingerprint[:])}}), &pinned, &wg)
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+	// the request without the pins is sent while the pinned one is in flight, and may not share its response
+	go run(newMetric(nil), &unpinned, &wg)
+	time.Sleep(200 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, pinned.Phase, pinned.Message)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, unpinned.Phase)
+	assert.Contains(t, unpinned.Message, "certificate signed by unknown authority")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRequestKey(t *testing.T) {
+	newRequest := func(url, header string) *http.Request {
+		request, _ := http.NewRequest(http.MethodGet, url, nil)
+		request.Header.Set("key", header)
+		return request
+	}
+	metric := v1alpha1.Metric{Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{}}}
+	otherCredentials := v1alpha1.Metric{Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{
+		Authentication: v1alpha1.Authentication{OAuth2: v1alpha1.OAuth2Config{TokenURL: "http://token", ClientID: "other"}},
+	}}}
+
+	key := func(metric v1alpha1.Metric, request *http.Request, body string) string {
+		k, err := requestKey(metric, request, []byte(body))
+		assert.NoError(t, err)
+		return k
+	}
+	base := key(metric, newRequest("http://host/a", "1"), "")
+	assert.Equal(t, base, key(metric, newRequest("http://host/a", "1"), ""))
+	assert.NotEqual(t, base, key(metric, newRequest("http://host/b", "1"), ""))
+	assert.NotEqual(t, base, key(metric, newRequest("http://host/a", "2"), ""))
+	assert.NotEqual(t, base, key(metric, newRequest("http://host/a", "1"), "body"))
+	assert.NotEqual(t, base, key(otherCredentials, newRequest("http://host/a", "1"), ""))
+
+	maxRedirects := int64(0)
+	for _, web := range []v1alpha1.WebMetric{
+		{TLSConfig: &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{"ab"}}},
+		{TLSConfig: &v1alpha1.WebMetricTLSConfig{SPKISHA256: []string{"ab"}}},
+		{TLSConfig: &v1alpha1.WebMetricTLSConfig{ServerName: "other"}},
+		{TLSConfig: &v1alpha1.WebMetricTLSConfig{CipherSuites: []string{"TLS_AES_128_GCM_SHA256"}}},
+		{ProxyUrl: "http://proxy:3128"},
+		{ForceHttp1: true},
+		{MaxRedirects: &maxRedirects},
+		{Location: &v1alpha1.WebMetricLocation{}},
+	} {
+		assert.NotEqual(t, base, key(v1alpha1.Metric{Provider: v1alpha1.MetricProvider{Web: &web}}, newRequest("http://host/a", "1"), ""))
+	}
+}
@@ -0,0 +1,77 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// coerce converts the value selected from the response to the type of the coercion, so the conditions behave the same
+// whether the endpoint returns e.g. "200" or 200
+func coerce(coercion v1alpha1.WebMetricCoercion, val any) (any, string, error) {
+	var coerced any
+	var err error
+	switch coercion {
+	case v1alpha1.WebMetricCoercionNumber:
+		coerced, err = coerceNumber(val)
+	case v1alpha1.WebMetricCoercionString:
+		coerced, err = coerceString(val)
+	case v1alpha1.WebMetricCoercionBool:
+		coerced, err = coerceBool(val)
+	default:
+		return nil, "", fmt.Errorf("invalid coerceTo %q: it must be number, string or bool", coercion)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("could not coerce %v to a %s: %v", val, coercion, err)
+	}
+	valBytes, err := json.Marshal(coerced)
+	return coerced, string(valBytes), err
+}
+
+func coerceNumber(val any) (float64, error) {
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unsupported type %T", val)
+}
+
+func coerceString(val any) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("unsupported type %T", val)
+}
+
+func coerceBool(val any) (bool, error) {
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	case float64:
+		return v != 0, nil
+	case int:
+		return v != 0, nil
+	}
+	return false, fmt.Errorf("unsupported type %T", val)
+}
@@ -0,0 +1,101 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunWithCoerceTo(t *testing.T) {
+	tests := []struct {
+		name             string
+		coerceTo         v1alpha1.WebMetricCoercion
+		successCondition string
+		response         string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedMessage  string
+	}{
+		{
+			name:             "string to number",
+			coerceTo:         v1alpha1.WebMetricCoercionNumber,
+			successCondition: "result == 200",
+			response:         `{"status": "200"}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "200",
+		},
+		{
+			name:             "number to string",
+			coerceTo:         v1alpha1.WebMetricCoercionString,
+			successCondition: `result == "200"`,
+			response:         `{"status": 200}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `"200"`,
+		},
+		{
+			name:             "string to bool",
+			coerceTo:         v1alpha1.WebMetricCoercionBool,
+			successCondition: "result == true",
+			response:         `{"status": "true"}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "true",
+		},
+		{
+			name:             "failed coercion",
+			coerceTo:         v1alpha1.WebMetricCoercionNumber,
+			successCondition: "result == 200",
+			response:         `{"status": "OK"}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMessage:  `could not coerce OK to a number: strconv.ParseFloat: parsing "OK": invalid syntax`,
+		},
+		{
+			name:             "maps are not coerced",
+			coerceTo:         v1alpha1.WebMetricCoercionString,
+			successCondition: `result == "200"`,
+			response:         `{"status": {"code": 200}}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMessage:  "could not coerce map[code:200] to a string: unsupported type map[string]interface {}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						JSONPath: "{$.status}",
+						CoerceTo: test.coerceTo,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			if test.expectedPhase == v1alpha1.AnalysisPhaseError {
+				assert.Equal(t, ErrorCauseParse, measurement.Metadata[ErrorCauseMetadataKey])
+			}
+		})
+	}
+}
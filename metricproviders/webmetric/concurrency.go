@@ -0,0 +1,101 @@
+package webmetric
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// hostSemaphore caps the requests in flight to a host. Its limit is the smallest maxConcurrency of the metrics
+// querying the host, so the most restrictive cap applies to the requests of all of them
+type hostSemaphore struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	// released is closed, and replaced, whenever a slot is released, to wake up the waiting requests
+	released chan struct{}
+}
+
+var (
+	hostSemaphoresMu sync.Mutex
+	// hostSemaphores cap the requests in flight to each host. They are shared by all providers, so the cap applies to
+	// the requests of all the analysis runs
+	hostSemaphores = map[string]*hostSemaphore{}
+)
+
+// hostSemaphoreOf returns the semaphore of the host, lowered to the concurrency cap of the metric. A cap is never
+// raised, so the requests of a metric with another cap never exceed the smallest cap of the host
+func hostSemaphoreOf(host string, maxConcurrency int) *hostSemaphore {
+	hostSemaphoresMu.Lock()
+	semaphore, ok := hostSemaphores[host]
+	if !ok {
+		semaphore = &hostSemaphore{limit: maxConcurrency, released: make(chan struct{})}
+		hostSemaphores[host] = semaphore
+	}
+	hostSemaphoresMu.Unlock()
+
+	semaphore.mu.Lock()
+	defer semaphore.mu.Unlock()
+	if maxConcurrency < semaphore.limit {
+		semaphore.limit = maxConcurrency
+	}
+	return semaphore
+}
+
+// acquire waits for a slot of the semaphore, until the context is done
+func (s *hostSemaphore) acquire(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if s.inFlight < s.limit {
+			s.inFlight++
+			s.mu.Unlock()
+			return nil
+		}
+		released := s.released
+		s.mu.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot of the semaphore
+func (s *hostSemaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// inUse returns the number of slots of the semaphore held by requests in flight
+func (s *hostSemaphore) inUse() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
+
+// acquireHostSlot waits for a slot of the concurrency cap of the host of the request, which is released by the
+// returned function. The returned request has a deadline of the client timeout, including the wait, so queued
+// requests do not exceed the timeout of the metric
+func (p *Provider) acquireHostSlot(metric v1alpha1.Metric, request *http.Request) (*http.Request, func(), error) {
+	maxConcurrency := metric.Provider.Web.MaxConcurrency
+	if maxConcurrency <= 0 {
+		return request, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(request.Context(), p.client.Timeout)
+	semaphore := hostSemaphoreOf(request.URL.Host, int(maxConcurrency))
+	if err := semaphore.acquire(ctx); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("max concurrency of %s: %v", request.URL.Host, err)
+	}
+	return request.WithContext(ctx), func() {
+		semaphore.release()
+		cancel()
+	}, nil
+}
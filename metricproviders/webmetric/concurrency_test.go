@@ -0,0 +1,150 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func newConcurrencyLimitedProvider(t *testing.T, url string, timeoutSeconds, maxConcurrency int64) (*Provider, v1alpha1.Metric) {
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            url,
+				TimeoutSeconds: timeoutSeconds,
+				MaxConcurrency: maxConcurrency,
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	return NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser), metric
+}
+
+func TestRunWithMaxConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	// the cap is shared by the metrics querying the same host, the excess requests waiting for a slot
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			provider, metric := newConcurrencyLimitedProvider(t, server.URL, 0, 2)
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(2), maxInFlight.Load())
+}
+
+func TestRunWithMaxConcurrencyOfMetricsWithDifferentCaps(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	// once a metric with a cap of 1 queried the host, the metrics with a cap of 2 on the same host share the smallest
+	// cap while their measurements interleave
+	provider, metric := newConcurrencyLimitedProvider(t, server.URL, 0, 1)
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(maxConcurrency int64) {
+			defer wg.Done()
+			provider, metric := newConcurrencyLimitedProvider(t, server.URL, 0, maxConcurrency)
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+		}(int64(i%2 + 1))
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), maxInFlight.Load())
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hostSemaphoreOf(serverURL.Host, 2).inUse())
+}
+
+func TestRunWithMaxConcurrencyExceedingTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-unblock
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	// the only slot is held by a request in flight until the queued request times out
+	done := make(chan v1alpha1.Measurement)
+	go func() {
+		provider, metric := newConcurrencyLimitedProvider(t, server.URL, 5, 1)
+		done <- provider.Run(newAnalysisRun(), metric)
+	}()
+	assert.Eventually(t, func() bool {
+		return hostSemaphoreOf(serverURL.Host, 1).inUse() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	provider, metric := newConcurrencyLimitedProvider(t, server.URL, 1, 1)
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, "max concurrency of "+serverURL.Host+": context deadline exceeded", measurement.Message)
+
+	close(unblock)
+	measurement = <-done
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+}
+
+func TestHostSemaphoreOf(t *testing.T) {
+	semaphore := hostSemaphoreOf("semaphore.test", 4)
+	assert.Equal(t, 4, semaphore.limit)
+
+	// the semaphore of the host is lowered to a smaller cap, but never raised
+	assert.Same(t, semaphore, hostSemaphoreOf("semaphore.test", 2))
+	assert.Equal(t, 2, semaphore.limit)
+	assert.Same(t, semaphore, hostSemaphoreOf("semaphore.test", 8))
+	assert.Equal(t, 2, semaphore.limit)
+	assert.NotSame(t, semaphore, hostSemaphoreOf("other.semaphore.test", 2))
+}
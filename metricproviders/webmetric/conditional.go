@@ -0,0 +1,117 @@
+package webmetric
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// maxValidatedResponses caps the number of responses kept for the conditional requests, the least recently used
+// response being evicted
+const maxValidatedResponses = 200
+
+// validatedResponse is the last response of an endpoint with an ETag or Last-Modified header
+type validatedResponse struct {
+	response *webResponse
+	// lastUsed orders the responses by use, for the eviction
+	lastUsed uint64
+}
+
+var (
+	validatedResponsesMu sync.Mutex
+	// validatedResponses are the last responses with an ETag or Last-Modified header, by validated response key. They
+	// are shared by all providers since a provider is created for every measurement
+	validatedResponses    = map[string]*validatedResponse{}
+	validatedResponseUses uint64
+)
+
+// validatedResponseKey identifies the responses of an endpoint of the metric of an analysis run. The URL template of
+// the metric is the one before the placeholders of the measurement are resolved, and the endpoint leaves the query of
+// the request out, so the key is the same for every measurement whatever its times, headers or idempotency key. The
+// key is a digest so the credentials of the URL are not kept in memory as is
+func validatedResponseKey(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) string {
+	return string(run.UID) + "/" + metric.Name + "/" + metric.Provider.Web.URL
+}
+
+// endpointKey returns the key of the validated response of the request to an endpoint of the metric
+func endpointKey(metricKey string, request *http.Request) string {
+	u := *request.URL
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	digest := sha256.Sum256([]byte(metricKey + "\n" + request.Method + " " + u.String()))
+	return hex.EncodeToString(digest[:])
+}
+
+// conditionalDo sends the request with the validators of the last response, and returns the last response if the
+// endpoint replies it was not modified
+func (p *Provider) conditionalDo(metric v1alpha1.Metric, request *http.Request, body []byte) (*webResponse, error) {
+	key := endpointKey(p.validatedResponseKey, request)
+
+	validatedResponsesMu.Lock()
+	var cached *webResponse
+	if validated, ok := validatedResponses[key]; ok {
+		validatedResponseUses++
+		validated.lastUsed = validatedResponseUses
+		cached = validated.response
+	}
+	validatedResponsesMu.Unlock()
+	if cached != nil {
+		if etag := cached.header.Get("ETag"); etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.header.Get("Last-Modified"); lastModified != "" {
+			request.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	response, err := p.send(metric, request, body)
+	if err != nil {
+		return nil, err
+	}
+	if response.statusCode == http.StatusNotModified {
+		if cached == nil {
+			return nil, errors.New("received 304 Not Modified response without a previous response to reuse")
+		}
+		reused := *cached
+		reused.duration = response.duration
+		return &reused, nil
+	}
+
+	validatedResponsesMu.Lock()
+	defer validatedResponsesMu.Unlock()
+	if response.header.Get("ETag") != "" || response.header.Get("Last-Modified") != "" {
+		if _, ok := validatedResponses[key]; !ok && len(validatedResponses) >= maxValidatedResponses {
+			evictValidatedResponse()
+		}
+		validatedResponseUses++
+		validatedResponses[key] = &validatedResponse{response: response, lastUsed: validatedResponseUses}
+	} else {
+		delete(validatedResponses, key)
+	}
+	return response, nil
+}
+
+// evictValidatedResponse evicts the least recently used response. The caller must hold validatedResponsesMu
+func evictValidatedResponse() {
+	var oldestKey string
+	var oldest uint64
+	for key, validated := range validatedResponses {
+		if oldestKey == "" || validated.lastUsed < oldest {
+			oldestKey, oldest = key, validated.lastUsed
+		}
+	}
+	delete(validatedResponses, oldestKey)
+}
+
+// withoutConditionalRequests returns a copy of the metric sending its requests without validators
+func withoutConditionalRequests(metric v1alpha1.Metric) v1alpha1.Metric {
+	web := *metric.Provider.Web
+	web.ConditionalRequests = false
+	metric.Provider.Web = &web
+	return metric
+}
@@ -0,0 +1,236 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	version := 1
+	lastModified := "Wed, 14 Oct 2026 10:00:00 GMT"
+	var ifNoneMatch, ifModifiedSince string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ifNoneMatch = req.Header.Get("If-None-Match")
+		ifModifiedSince = req.Header.Get("If-Modified-Since")
+		etag := fmt.Sprintf(`"v%d"`, version)
+		rw.Header().Set("ETag", etag)
+		rw.Header().Set("Last-Modified", lastModified)
+		if ifNoneMatch == etag {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"value": %d}`, version)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result > 0",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:                 server.URL,
+				JSONPath:            "{$.value}",
+				ConditionalRequests: true,
+			},
+		},
+	}
+	measure := func() v1alpha1.Measurement {
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+		return provider.Run(newAnalysisRun(), metric)
+	}
+
+	// the first request has no validators
+	measurement := measure()
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "1", measurement.Value)
+	assert.Empty(t, ifNoneMatch)
+	assert.Empty(t, ifModifiedSince)
+
+	// a 304 reuses the prior value
+	measurement = measure()
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "1", measurement.Value)
+	assert.Equal(t, `"v1"`, ifNoneMatch)
+	assert.Equal(t, lastModified, ifModifiedSince)
+
+	// a 200 updates the cache
+	version = 2
+	measurement = measure()
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "2", measurement.Value)
+	assert.Equal(t, `"v1"`, ifNoneMatch)
+
+	measurement = measure()
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "2", measurement.Value)
+	assert.Equal(t, `"v2"`, ifNoneMatch)
+}
+
+func TestConditionalRequestsNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name                string
+		conditionalRequests bool
+		expectedMessage     string
+	}{
+		{
+			name:                "without a previous response",
+			conditionalRequests: true,
+			expectedMessage:     "received 304 Not Modified response without a previous response to reuse",
+		},
+		{
+			name:            "without conditional requests",
+			expectedMessage: "received non 2xx response code: 304",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name: "foo",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:                 server.URL,
+						JSONPath:            "{$.value}",
+						ConditionalRequests: test.conditionalRequests,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
+
+func TestConditionalRequestsWithIdempotencyKey(t *testing.T) {
+	var ifNoneMatch, idempotencyKey string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ifNoneMatch = req.Header.Get("If-None-Match")
+		idempotencyKey = req.Header.Get("Idempotency-Key")
+		rw.Header().Set("ETag", `"v1"`)
+		if ifNoneMatch == `"v1"` {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"value": 1}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result > 0",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:                  server.URL + "/query?start=$(now unixms)",
+				JSONPath:             "{$.value}",
+				ConditionalRequests:  true,
+				IdempotencyKeyHeader: "Idempotency-Key",
+			},
+		},
+	}
+	measure := func(run *v1alpha1.AnalysisRun) v1alpha1.Measurement {
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+		return provider.Run(run, metric)
+	}
+	run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{UID: "run-1"}}
+
+	measurement := measure(run)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Empty(t, ifNoneMatch)
+	firstKey := idempotencyKey
+
+	// the validators are sent although the idempotency key and the start time of the request differ
+	measurement = measure(run)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "1", measurement.Value)
+	assert.Equal(t, `"v1"`, ifNoneMatch)
+	assert.NotEqual(t, firstKey, idempotencyKey)
+
+	// the responses of another run are not reused
+	measurement = measure(&v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{UID: "run-2"}})
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Empty(t, ifNoneMatch)
+}
+
+func TestValidatedResponsesEviction(t *testing.T) {
+	validatedResponses = map[string]*validatedResponse{}
+	t.Cleanup(func() {
+		validatedResponses = map[string]*validatedResponse{}
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("ETag", `"v1"`)
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"value": 1}`)
+	}))
+	defer server.Close()
+
+	measure := func(uid string) {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result > 0",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:                 server.URL,
+					JSONPath:            "{$.value}",
+					ConditionalRequests: true,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+		measurement := provider.Run(&v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)}}, metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+
+	for i := 0; i < maxValidatedResponses; i++ {
+		measure(fmt.Sprintf("run-%d", i))
+	}
+	// the first response is used again, so the second one is the least recently used
+	measure("run-0")
+	measure("run-new")
+
+	assert.Len(t, validatedResponses, maxValidatedResponses)
+	hasRun := func(uid string) bool {
+		metric := v1alpha1.Metric{Name: "foo", Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{URL: server.URL}}}
+		request := httptest.NewRequest(http.MethodGet, server.URL, nil)
+		_, ok := validatedResponses[endpointKey(validatedResponseKey(&v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)}}, metric), request)]
+		return ok
+	}
+	assert.True(t, hasRun("run-0"))
+	assert.False(t, hasRun("run-1"))
+	assert.True(t, hasRun("run-new"))
+}
@@ -0,0 +1,51 @@
+package webmetric
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type connectTimeoutTransportKey struct {
+	base                *http.Transport
+	dialTimeout         time.Duration
+	tlsHandshakeTimeout time.Duration
+}
+
+var (
+	connectTimeoutTransportsMu sync.Mutex
+	// connectTimeoutTransports are shared, like the other transports, so their idle connections are reused between
+	// measurements
+	connectTimeoutTransports = map[connectTimeoutTransportKey]*http.Transport{}
+)
+
+// connectTimeoutTransport returns a transport like base, with the timeouts to establish the connections. A timeout of
+// 0 keeps the one of base
+func connectTimeoutTransport(base *http.Transport, dialTimeout, tlsHandshakeTimeout time.Duration) *http.Transport {
+	key := connectTimeoutTransportKey{base: base, dialTimeout: dialTimeout, tlsHandshakeTimeout: tlsHandshakeTimeout}
+	connectTimeoutTransportsMu.Lock()
+	defer connectTimeoutTransportsMu.Unlock()
+	if t, ok := connectTimeoutTransports[key]; ok {
+		return t
+	}
+	t := base.Clone()
+	if dialTimeout > 0 {
+		dial := base.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		// the timeout wraps the dial function of base, such as the one resolving through the DNS cache
+		t.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+			defer cancel()
+			return dial(ctx, network, address)
+		}
+	}
+	if tlsHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = tlsHandshakeTimeout
+	}
+	connectTimeoutTransports[key] = t
+	return t
+}
@@ -0,0 +1,82 @@
+package webmetric
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestDialTimeout(t *testing.T) {
+	// the base transport connects slowly, like to a host which does not answer
+	base := transport.Clone()
+	base.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, context.DeadlineExceeded
+		}
+	}
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: connectTimeoutTransport(base, 50*time.Millisecond, 0),
+	}
+
+	start := time.Now()
+	_, err := client.Get("http://slow.example.com")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestTLSHandshakeTimeout(t *testing.T) {
+	// the listener accepts the connections but never answers the TLS handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:                        "https://" + listener.Addr().String(),
+				TimeoutSeconds:             10,
+				DialTimeoutSeconds:         1,
+				TLSHandshakeTimeoutSeconds: 1,
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, client.Transport.(*http.Transport).TLSHandshakeTimeout)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	start := time.Now()
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Contains(t, measurement.Message, "TLS handshake timeout")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestConnectTimeoutTransportIsShared(t *testing.T) {
+	assert.Same(t, connectTimeoutTransport(transport, time.Second, 0), connectTimeoutTransport(transport, time.Second, 0))
+	assert.NotSame(t, connectTimeoutTransport(transport, time.Second, 0), connectTimeoutTransport(transport, 2*time.Second, 0))
+}
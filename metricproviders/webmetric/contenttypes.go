@@ -0,0 +1,118 @@
+package webmetric
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// responseFormat is the format a response body is decoded from
+type responseFormat string
+
+const (
+	responseFormatJSON responseFormat = "JSON"
+	responseFormatXML  responseFormat = "XML"
+	responseFormatYAML responseFormat = "YAML"
+	responseFormatCSV  responseFormat = "CSV"
+)
+
+// mediaTypeFormat returns the format of a media type, including the structured syntax suffixes such as
+// application/problem+json
+func mediaTypeFormat(mediaType string) (responseFormat, bool) {
+	_, subtype, _ := strings.Cut(mediaType, "/")
+	if _, suffix, ok := strings.Cut(subtype, "+"); ok {
+		subtype = suffix
+	}
+	switch subtype {
+	case "json":
+		return responseFormatJSON, true
+	case "xml":
+		return responseFormatXML, true
+	case "yaml", "x-yaml":
+		return responseFormatYAML, true
+	case "csv":
+		return responseFormatCSV, true
+	}
+	return "", false
+}
+
+// negotiatedFormat returns the format of the expected content type matched by the Content-Type of the response
+func negotiatedFormat(expected []string, response *webResponse) (responseFormat, error) {
+	contentType := response.header.Get(ContentTypeKey)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if contentType != "" && err != nil {
+		return "", fmt.Errorf("invalid Content-Type of the response %q: %v", contentType, err)
+	}
+	var matched responseFormat
+	for _, e := range expected {
+		expectedType, _, err := mime.ParseMediaType(e)
+		if err != nil {
+			return "", fmt.Errorf("invalid expectedContentTypes entry %q: %v", e, err)
+		}
+		format, ok := mediaTypeFormat(expectedType)
+		if !ok {
+			return "", fmt.Errorf("expectedContentTypes entry %q is not a JSON, XML, YAML or CSV content type", e)
+		}
+		if matched == "" && expectedType == mediaType {
+			matched = format
+		}
+	}
+	if matched == "" {
+		if contentType == "" {
+			return "", fmt.Errorf("the response has no Content-Type, expected one of: %s", strings.Join(expected, ", "))
+		}
+		return "", fmt.Errorf("the response Content-Type %s is not one of the expectedContentTypes: %s", mediaType, strings.Join(expected, ", "))
+	}
+	return matched, nil
+}
+
+// decodeNegotiatedBody decodes the body of the response by the format of the expected content type it matches
+func decodeNegotiatedBody(expected []string, response *webResponse) (any, error) {
+	format, err := negotiatedFormat(expected, response)
+	if err != nil {
+		return nil, err
+	}
+	var data any
+	switch format {
+	case responseFormatJSON:
+		err = json.Unmarshal(response.body, &data)
+	case responseFormatXML:
+		data, err = decodeXML(response.body)
+	case responseFormatYAML:
+		err = yaml.Unmarshal(response.body, &data)
+	case responseFormatCSV:
+		data, err = decodeCSV(response.body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decode the %s response: %v", format, err)
+	}
+	return data, nil
+}
+
+// decodeCSV decodes a CSV document into a list of its rows, as objects keyed by the names of the header row. The
+// cells holding a JSON value, such as a number or a bool, are decoded so that they can be compared
+func decodeCSV(body []byte) ([]any, error) {
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("there is no header row")
+	}
+	header := records[0]
+	rows := make([]any, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, name := range header {
+			row[name] = textValue(record[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
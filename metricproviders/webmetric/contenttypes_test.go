@@ -0,0 +1,159 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestExpectedContentTypes(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		body            string
+		jsonPath        string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "json",
+			contentType:   "application/json; charset=utf-8",
+			body:          `{"data": {"successRate": 0.99}}`,
+			jsonPath:      "{$.data.successRate}",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:          "xml",
+			contentType:   "application/xml",
+			body:          `<data><successRate>0.99</successRate></data>`,
+			jsonPath:      "{$.data.successRate}",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:          "yaml",
+			contentType:   "application/yaml",
+			body:          "data:\n  successRate: 0.99\n",
+			jsonPath:      "{$.data.successRate}",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:          "csv",
+			contentType:   "text/csv",
+			body:          "service,successRate\nfrontend,0.99\nbackend,0.95\n",
+			jsonPath:      "{$[?(@.service == 'frontend')].successRate}",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:            "unexpected content type",
+			contentType:     "text/html",
+			body:            "<html></html>",
+			jsonPath:        "{$.data.successRate}",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "the response Content-Type text/html is not one of the expectedContentTypes: application/json, application/xml, application/yaml, text/csv",
+		},
+		{
+			name:            "body not matching its content type",
+			contentType:     "application/yaml",
+			body:            "data: [",
+			jsonPath:        "{$.data.successRate}",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "could not decode the YAML response: error converting YAML to JSON: yaml: line 1: did not find expected node content",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var accept string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				accept = req.Header.Get("Accept")
+				rw.Header().Set("Content-Type", test.contentType)
+				io.WriteString(rw, test.body)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result > 0.9",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:                  server.URL,
+						JSONPath:             test.jsonPath,
+						ExpectedContentTypes: []string{"application/json", "application/xml", "application/yaml", "text/csv"},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.Equal(t, "application/json, application/xml, application/yaml, text/csv", accept)
+		})
+	}
+}
+
+func TestNegotiatedFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		expected       []string
+		contentType    string
+		expectedFormat responseFormat
+		expectedError  string
+	}{
+		{
+			name:           "structured syntax suffix",
+			expected:       []string{"application/problem+json"},
+			contentType:    "application/problem+json",
+			expectedFormat: responseFormatJSON,
+		},
+		{
+			name:           "case insensitive",
+			expected:       []string{"text/CSV"},
+			contentType:    "Text/csv; header=present",
+			expectedFormat: responseFormatCSV,
+		},
+		{
+			name:          "unsupported expected type",
+			expected:      []string{"application/json", "text/html"},
+			contentType:   "application/json",
+			expectedError: `expectedContentTypes entry "text/html" is not a JSON, XML, YAML or CSV content type`,
+		},
+		{
+			name:          "missing content type",
+			expected:      []string{"application/json"},
+			expectedError: "the response has no Content-Type, expected one of: application/json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			if test.contentType != "" {
+				header.Set(ContentTypeKey, test.contentType)
+			}
+			format, err := negotiatedFormat(test.expected, &webResponse{header: header})
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedFormat, format)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package webmetric
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// decode applies the decoders in order to the string selected by the JSONStringPath. The json decoder, which parses
+// the result as a JSON document, is implied after the last decoder
+func decode(decoders []v1alpha1.WebMetricDecoder, encoded string) ([]byte, error) {
+	data := []byte(encoded)
+	for i, decoder := range decoders {
+		var err error
+		switch decoder {
+		case v1alpha1.WebMetricDecoderBase64:
+			data, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		case v1alpha1.WebMetricDecoderGzip:
+			data, err = gunzip(data)
+		case v1alpha1.WebMetricDecoderJSON:
+			if i != len(decoders)-1 {
+				return nil, fmt.Errorf("the %s decoder must be the last of the decoders", decoder)
+			}
+		default:
+			return nil, fmt.Errorf("unknown decoder %q: it must be base64, gzip or json", decoder)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s decoder of jsonStringPath failed: %v", decoder, err)
+		}
+	}
+	return data, nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
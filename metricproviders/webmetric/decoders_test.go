@@ -0,0 +1,105 @@
+package webmetric
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func gzipBase64(t *testing.T, document string) string {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(document))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDecoders(t *testing.T) {
+	tests := []struct {
+		name            string
+		blob            string
+		decoders        []v1alpha1.WebMetricDecoder
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "base64 and gzip",
+			blob:          gzipBase64(t, `{"errorRate": 0.01}`),
+			decoders:      []v1alpha1.WebMetricDecoder{"base64", "gzip"},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.01",
+		},
+		{
+			name:          "explicit json decoder",
+			blob:          gzipBase64(t, `{"errorRate": 0.2}`),
+			decoders:      []v1alpha1.WebMetricDecoder{"base64", "gzip", "json"},
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "0.2",
+		},
+		{
+			name:            "corrupt base64",
+			blob:            "not base64!",
+			decoders:        []v1alpha1.WebMetricDecoder{"base64", "gzip"},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "base64 decoder of jsonStringPath failed: illegal base64 data at input byte 3",
+		},
+		{
+			name:            "corrupt gzip",
+			blob:            base64.StdEncoding.EncodeToString([]byte(`{"errorRate": 0.01}`)),
+			decoders:        []v1alpha1.WebMetricDecoder{"base64", "gzip"},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "gzip decoder of jsonStringPath failed: gzip: invalid header",
+		},
+		{
+			name:            "json decoder before the end",
+			blob:            gzipBase64(t, `{"errorRate": 0.01}`),
+			decoders:        []v1alpha1.WebMetricDecoder{"json", "base64"},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "the json decoder must be the last of the decoders",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(rw).Encode(map[string]string{"metrics": test.blob})
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result < 0.05",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:            server.URL,
+						JSONStringPath: "{$.metrics}",
+						Decoders:       test.decoders,
+						JSONPath:       "{$.errorRate}",
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package webmetric
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/config"
+)
+
+// withDefaults returns a copy of the metric inheriting the web metric defaults of the controller configuration. The
+// headers of the metric take precedence over the default headers of the same name, and the default authentication is
+// only inherited by metrics without an authentication or an Authorization header
+func withDefaults(metric v1alpha1.Metric) v1alpha1.Metric {
+	rolloutsConfig, err := config.GetConfig()
+	if err != nil {
+		// the configuration is not initialized outside of the controller
+		return metric
+	}
+	return mergeDefaults(metric, rolloutsConfig.GetWebMetricDefaults())
+}
+
+func mergeDefaults(metric v1alpha1.Metric, defaults config.WebMetricDefaults) v1alpha1.Metric {
+	web := *metric.Provider.Web
+	set := map[string]bool{}
+	for _, header := range web.Headers {
+		set[http.CanonicalHeaderKey(header.Key)] = true
+	}
+
+	var headers []v1alpha1.WebMetricHeader
+	for _, header := range defaults.Headers {
+		if !set[http.CanonicalHeaderKey(header.Key)] {
+			headers = append(headers, header)
+		}
+	}
+	if len(headers) > 0 {
+		web.Headers = append(headers, web.Headers...)
+	}
+
+	if !hasAuthentication(&web) && !set["Authorization"] {
+		web.Authentication = defaults.Authentication
+	}
+	metric.Provider.Web = &web
+	return metric
+}
+
+func hasAuthentication(web *v1alpha1.WebMetric) bool {
+	return len(web.Authentications) > 0 || !reflect.DeepEqual(web.Authentication, v1alpha1.Authentication{})
+}
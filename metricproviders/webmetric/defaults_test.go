@@ -0,0 +1,156 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/config"
+	"github.com/argoproj/argo-rollouts/utils/defaults"
+)
+
+func TestMergeDefaults(t *testing.T) {
+	defaultAuth := v1alpha1.Authentication{Basic: &v1alpha1.BasicAuthConfig{Username: "default", Password: "secret"}}
+	metricAuth := v1alpha1.Authentication{APIKey: &v1alpha1.APIKeyConfig{Name: "X-API-Key", Key: "key"}}
+	defaultConfig := config.WebMetricDefaults{
+		Headers: []v1alpha1.WebMetricHeader{
+			{Key: "X-Org-Id", Value: "default-org"},
+			{Key: "X-Trace-Id", Value: "default-trace"},
+		},
+		Authentication: defaultAuth,
+	}
+
+	tests := []struct {
+		name                   string
+		web                    v1alpha1.WebMetric
+		expectedHeaders        []v1alpha1.WebMetricHeader
+		expectedAuthentication v1alpha1.Authentication
+	}{
+		{
+			name:                   "metric inherits the defaults",
+			web:                    v1alpha1.WebMetric{},
+			expectedHeaders:        defaultConfig.Headers,
+			expectedAuthentication: defaultAuth,
+		},
+		{
+			name: "metric headers override the default headers of the same name",
+			web: v1alpha1.WebMetric{
+				Headers: []v1alpha1.WebMetricHeader{{Key: "x-org-id", Value: "my-org"}, {Key: "X-Extra", Value: "extra"}},
+			},
+			expectedHeaders: []v1alpha1.WebMetricHeader{
+				{Key: "X-Trace-Id", Value: "default-trace"},
+				{Key: "x-org-id", Value: "my-org"},
+				{Key: "X-Extra", Value: "extra"},
+			},
+			expectedAuthentication: defaultAuth,
+		},
+		{
+			name:                   "metric authentication overrides the default authentication",
+			web:                    v1alpha1.WebMetric{Authentication: metricAuth},
+			expectedHeaders:        defaultConfig.Headers,
+			expectedAuthentication: metricAuth,
+		},
+		{
+			name:            "metric authentications override the default authentication",
+			web:             v1alpha1.WebMetric{Authentications: []v1alpha1.Authentication{metricAuth}},
+			expectedHeaders: defaultConfig.Headers,
+		},
+		{
+			name: "metric authorization header overrides the default authentication",
+			web: v1alpha1.WebMetric{
+				Headers: []v1alpha1.WebMetricHeader{{Key: "Authorization", Value: "Bearer token"}},
+			},
+			expectedHeaders: append(append([]v1alpha1.WebMetricHeader{}, defaultConfig.Headers...),
+				v1alpha1.WebMetricHeader{Key: "Authorization", Value: "Bearer token"}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			web := test.web
+			metric := v1alpha1.Metric{Provider: v1alpha1.MetricProvider{Web: &web}}
+
+			merged := mergeDefaults(metric, defaultConfig)
+			assert.Equal(t, test.expectedHeaders, merged.Provider.Web.Headers)
+			assert.Equal(t, test.expectedAuthentication, merged.Provider.Web.Authentication)
+			// the metric itself is not modified
+			assert.Equal(t, test.web, web)
+		})
+	}
+
+	metric := v1alpha1.Metric{Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{Headers: defaultConfig.Headers}}}
+	assert.Equal(t, metric, mergeDefaults(metric, config.WebMetricDefaults{}))
+}
+
+func TestWebMetricDefaultsFromConfig(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaults.DefaultRolloutsConfigMapName,
+			Namespace: defaults.Namespace(),
+		},
+		Data: map[string]string{
+			"webMetricDefaults": `
+headers:
+- key: X-Org-Id
+  value: default-org
+- key: X-Trace-Id
+  value: default-trace
+authentication:
+  basic:
+    username: default
+    password: secret
+`,
+		},
+	}
+	_, err := config.InitializeConfig(k8sfake.NewSimpleClientset(cm), defaults.DefaultRolloutsConfigMapName)
+	assert.NoError(t, err)
+	defer config.UnInitializeConfig()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		username, password, _ := req.BasicAuth()
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"org": %q, "trace": %q, "username": %q, "password": %q}`,
+			req.Header.Get("X-Org-Id"), req.Header.Get("X-Trace-Id"), username, password)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: `result.org == "my-org" && result.trace == "default-trace" && result.username == "default" && result.password == "secret"`,
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:     server.URL,
+				Headers: []v1alpha1.WebMetricHeader{{Key: "X-Org-Id", Value: "my-org"}},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+}
+
+func TestInvalidWebMetricDefaults(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaults.DefaultRolloutsConfigMapName,
+			Namespace: defaults.Namespace(),
+		},
+		Data: map[string]string{"webMetricDefaults": "headers: not-a-list"},
+	}
+	_, err := config.InitializeConfig(k8sfake.NewSimpleClientset(cm), defaults.DefaultRolloutsConfigMapName)
+	defer config.UnInitializeConfig()
+	assert.ErrorContains(t, err, "failed to unmarshal web metric defaults while initializing")
+}
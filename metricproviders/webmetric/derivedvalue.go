@@ -0,0 +1,64 @@
+package webmetric
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/antonmedv/expr"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// deriveValue evaluates the expression of the derived value with the numeric values of its paths in the data
+func deriveValue(derived *v1alpha1.WebMetricDerivedValue, data any) (any, string, error) {
+	if len(derived.Paths) == 0 {
+		return nil, "", fmt.Errorf("derivedValue requires at least one path")
+	}
+	names := make([]string, 0, len(derived.Paths))
+	for name := range derived.Paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]any, len(derived.Paths))
+	for _, name := range names {
+		parser := jsonpath.New(name)
+		if err := parser.Parse(derived.Paths[name]); err != nil {
+			return nil, "", fmt.Errorf("invalid derivedValue path %s: %v", name, err)
+		}
+		fullResults, err := parser.FindResults(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("Could not find derivedValue path %s in body: %s", name, err)
+		}
+		val, valString, err := getValue(fullResults)
+		if err != nil {
+			return nil, "", fmt.Errorf("derivedValue path %s: %v", name, err)
+		}
+		number, ok := toFloat(val)
+		if !ok {
+			return nil, "", fmt.Errorf("derivedValue path %s requires a numeric value, got: %s", name, valString)
+		}
+		values[name] = number
+	}
+
+	program, err := expr.Compile(derived.Expression, expr.Env(values))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid derivedValue expression: %v", err)
+	}
+	output, err := expr.Run(program, values)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to evaluate the derivedValue expression: %v", err)
+	}
+	result, ok := toFloat(output)
+	if !ok {
+		return nil, "", fmt.Errorf("derivedValue expression must produce a number, got %T", output)
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		// e.g. a ratio of a total of zero
+		return nil, "", fmt.Errorf("derivedValue expression %q produced %v, likely from a division by zero", derived.Expression, result)
+	}
+	return result, strconv.FormatFloat(result, 'f', -1, 64), nil
+}
@@ -0,0 +1,119 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestDerivedValue(t *testing.T) {
+	tests := []struct {
+		name             string
+		response         string
+		paths            map[string]string
+		expression       string
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedMessage  string
+	}{
+		{
+			name:             "ratio of two fields",
+			response:         `{"data": {"errors": 5, "total": 200}}`,
+			paths:            map[string]string{"errors": "{$.data.errors}", "total": "{$.data.total}"},
+			expression:       "errors / total",
+			successCondition: "result < 0.05",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "0.025",
+		},
+		{
+			name:             "expression of several fields",
+			response:         `{"errors": 5, "timeouts": 15, "total": 200}`,
+			paths:            map[string]string{"errors": "{$.errors}", "timeouts": "{$.timeouts}", "total": "{$.total}"},
+			expression:       "(errors + timeouts) / total * 100",
+			successCondition: "result < 5",
+			expectedPhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedValue:    "10",
+		},
+		{
+			name:            "division by zero",
+			response:        `{"errors": 0, "total": 0}`,
+			paths:           map[string]string{"errors": "{$.errors}", "total": "{$.total}"},
+			expression:      "errors / total",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: `derivedValue expression "errors / total" produced NaN, likely from a division by zero`,
+		},
+		{
+			name:            "division of a non zero value by zero",
+			response:        `{"errors": 3, "total": 0}`,
+			paths:           map[string]string{"errors": "{$.errors}", "total": "{$.total}"},
+			expression:      "errors / total",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: `derivedValue expression "errors / total" produced +Inf, likely from a division by zero`,
+		},
+		{
+			name:            "non numeric value",
+			response:        `{"errors": "many", "total": 10}`,
+			paths:           map[string]string{"errors": "{$.errors}", "total": "{$.total}"},
+			expression:      "errors / total",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: `derivedValue path errors requires a numeric value, got: "many"`,
+		},
+		{
+			name:            "unknown variable",
+			response:        `{"errors": 1, "total": 10}`,
+			paths:           map[string]string{"errors": "{$.errors}", "total": "{$.total}"},
+			expression:      "errors / requests",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "invalid derivedValue expression: unknown name requests (1:10)\n | errors / requests\n | .........^",
+		},
+		{
+			name:            "non numeric expression",
+			response:        `{"errors": 1, "total": 10}`,
+			paths:           map[string]string{"errors": "{$.errors}", "total": "{$.total}"},
+			expression:      "errors < total",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "derivedValue expression must produce a number, got bool",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL: server.URL,
+						DerivedValue: &v1alpha1.WebMetricDerivedValue{
+							Paths:      test.paths,
+							Expression: test.expression,
+						},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
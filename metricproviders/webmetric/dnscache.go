@@ -0,0 +1,109 @@
+package webmetric
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// dnsCache caches the addresses of the hosts resolved by the web metric clients. It is shared by all providers since
+// a provider is created for every measurement
+var dnsCache = newResolverCache(net.DefaultResolver.LookupHost, time.Now)
+
+type resolverCache struct {
+	mu      sync.Mutex
+	entries map[string]resolverCacheEntry
+	lookup  func(ctx context.Context, host string) ([]string, error)
+	now     func() time.Time
+}
+
+type resolverCacheEntry struct {
+	addrs      []string
+	resolvedAt time.Time
+}
+
+func newResolverCache(lookup func(ctx context.Context, host string) ([]string, error), now func() time.Time) *resolverCache {
+	return &resolverCache{
+		entries: map[string]resolverCacheEntry{},
+		lookup:  lookup,
+		now:     now,
+	}
+}
+
+// lookupHost returns the addresses of the host, resolving it again when its cached addresses are older than the ttl
+func (c *resolverCache) lookupHost(ctx context.Context, host string, ttl time.Duration) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && c.now().Sub(entry.resolvedAt) < ttl {
+		return entry.addrs, nil
+	}
+
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[host] = resolverCacheEntry{addrs: addrs, resolvedAt: c.now()}
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+// dialContext returns a dial function resolving hosts through the cache
+func (c *resolverCache) dialContext(dialer *net.Dialer, ttl time.Duration) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, address)
+		}
+		addrs, err := c.lookupHost(ctx, host, ttl)
+		if err != nil {
+			return nil, err
+		}
+		errs := make([]error, 0, len(addrs))
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+type dnsCachingTransportKey struct {
+	base *http.Transport
+	ttl  time.Duration
+}
+
+var (
+	dnsCachingTransportsMu sync.Mutex
+	// dnsCachingTransports are shared, like the transport of the clients without a DNS cache, so their idle
+	// connections are reused between measurements
+	dnsCachingTransports = map[dnsCachingTransportKey]*http.Transport{}
+)
+
+// dnsCachingTransport returns a transport like base, resolving hosts through the DNS cache with the ttl
+func dnsCachingTransport(base *http.Transport, ttl time.Duration) *http.Transport {
+	key := dnsCachingTransportKey{base: base, ttl: ttl}
+	dnsCachingTransportsMu.Lock()
+	defer dnsCachingTransportsMu.Unlock()
+	if t, ok := dnsCachingTransports[key]; ok {
+		return t
+	}
+	t := base.Clone()
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	t.DialContext = dnsCache.dialContext(dialer, ttl)
+	dnsCachingTransports[key] = t
+	return t
+}
@@ -0,0 +1,184 @@
+package webmetric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestResolverCache(t *testing.T) {
+	lookups := 0
+	now := time.Now()
+	cache := newResolverCache(func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1"}, nil
+	}, func() time.Time { return now })
+
+	addrs, err := cache.lookupHost(context.TODO(), "metrics.test", 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, addrs)
+	assert.Equal(t, 1, lookups)
+
+	// cached within the ttl
+	now = now.Add(29 * time.Second)
+	_, err = cache.lookupHost(context.TODO(), "metrics.test", 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lookups)
+
+	// a shorter ttl of another metric refreshes the cache sooner
+	_, err = cache.lookupHost(context.TODO(), "metrics.test", 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+
+	// refreshed after the ttl
+	now = now.Add(30 * time.Second)
+	_, err = cache.lookupHost(context.TODO(), "metrics.test", 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, lookups)
+
+	// hosts are cached separately
+	_, err = cache.lookupHost(context.TODO(), "other.test", 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, lookups)
+}
+
+func TestRunWithDNSCache(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	// every measurement dials a new connection
+	server.Config.SetKeepAlivesEnabled(false)
+	server.Start()
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	_, port, err := net.SplitHostPort(serverURL.Host)
+	assert.NoError(t, err)
+
+	lookups := 0
+	now := time.Now()
+	defaultDNSCache := dnsCache
+	dnsCache = newResolverCache(func(ctx context.Context, host string) ([]string, error) {
+		assert.Equal(t, "metrics.test", host)
+		lookups++
+		return []string{"127.0.0.1"}, nil
+	}, func() time.Time { return now })
+	dnsCachingTransports = map[dnsCachingTransportKey]*http.Transport{}
+	defer func() {
+		dnsCache = defaultDNSCache
+		dnsCachingTransports = map[dnsCachingTransportKey]*http.Transport{}
+	}()
+
+	run := func() v1alpha1.Measurement {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:                "http://metrics.test:" + port,
+					DNSCacheTTLSeconds: 60,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+		return provider.Run(newAnalysisRun(), metric)
+	}
+
+	for i := 0; i < 3; i++ {
+		measurement := run()
+		assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+	assert.Equal(t, 1, lookups)
+
+	now = now.Add(time.Minute)
+	measurement := run()
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, 2, lookups)
+}
+
+func TestDNSErrorClassification(t *testing.T) {
+	tests := []struct {
+		name            string
+		lookupErr       error
+		expectedMessage string
+	}{
+		{
+			name:            "non existent host",
+			lookupErr:       &net.DNSError{Err: "no such host", Name: "metrics.test", IsNotFound: true},
+			expectedMessage: "could not resolve host metrics.test: host not found (NXDOMAIN), check the host of the URL: Get \"http://metrics.test\": lookup metrics.test: no such host",
+		},
+		{
+			name:            "resolver timeout",
+			lookupErr:       &net.DNSError{Err: "i/o timeout", Name: "metrics.test", IsTimeout: true, IsTemporary: true},
+			expectedMessage: "could not resolve host metrics.test: DNS resolution timed out, the resolver may be unavailable: Get \"http://metrics.test\": lookup metrics.test: i/o timeout",
+		},
+		{
+			name:            "temporary resolver failure",
+			lookupErr:       &net.DNSError{Err: "server misbehaving", Name: "metrics.test", IsTemporary: true},
+			expectedMessage: "could not resolve host metrics.test: temporary DNS failure: Get \"http://metrics.test\": lookup metrics.test: server misbehaving",
+		},
+	}
+
+	defaultDNSCache := dnsCache
+	dnsCachingTransports = map[dnsCachingTransportKey]*http.Transport{}
+	defer func() {
+		dnsCache = defaultDNSCache
+		dnsCachingTransports = map[dnsCachingTransportKey]*http.Transport{}
+	}()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dnsCache = newResolverCache(func(ctx context.Context, host string) ([]string, error) {
+				return nil, test.lookupErr
+			}, time.Now)
+			dnsCachingTransports = map[dnsCachingTransportKey]*http.Transport{}
+
+			metric := v1alpha1.Metric{
+				Name: "foo",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:                "http://metrics.test",
+						DNSCacheTTLSeconds: 60,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
+
+func TestClassifyDNSErrorWraps(t *testing.T) {
+	lookupErr := &net.DNSError{Err: "no such host", Name: "metrics.test", IsNotFound: true}
+	err := classifyDNSError(fmt.Errorf("Get \"http://metrics.test\": %w", lookupErr))
+	var dnsErr *net.DNSError
+	assert.True(t, errors.As(err, &dnsErr))
+	assert.Equal(t, lookupErr, dnsErr)
+
+	otherErr := errors.New("connection refused")
+	assert.Equal(t, otherErr, classifyDNSError(otherErr))
+}
@@ -0,0 +1,26 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// classifyDNSError returns a distinct error for each kind of DNS resolution failure, so a host which does not exist
+// can be told from a resolver issue. The error wraps the original one, and other errors are returned as is
+func classifyDNSError(err error) error {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		return err
+	}
+	switch {
+	case dnsErr.IsNotFound:
+		return fmt.Errorf("could not resolve host %s: host not found (NXDOMAIN), check the host of the URL: %w", dnsErr.Name, err)
+	case dnsErr.IsTimeout:
+		return fmt.Errorf("could not resolve host %s: DNS resolution timed out, the resolver may be unavailable: %w", dnsErr.Name, err)
+	case dnsErr.IsTemporary:
+		return fmt.Errorf("could not resolve host %s: temporary DNS failure: %w", dnsErr.Name, err)
+	default:
+		return fmt.Errorf("could not resolve host %s: %w", dnsErr.Name, err)
+	}
+}
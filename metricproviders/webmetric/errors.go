@@ -0,0 +1,114 @@
+package webmetric
+
+import (
+	"errors"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	metricutil "github.com/argoproj/argo-rollouts/utils/metric"
+)
+
+const (
+	// ErrorCauseMetadataKey is the measurement metadata key holding the cause of an errored measurement
+	ErrorCauseMetadataKey = "errorCause"
+
+	ErrorCauseConnection = "connection"
+	ErrorCauseStatusCode = "statusCode"
+	ErrorCauseParse      = "parse"
+	ErrorCauseEvaluation = "evaluation"
+)
+
+var (
+	// ErrConnection matches the errors of requests which got no response
+	ErrConnection = errors.New("connection error")
+	// ErrStatusCode matches the errors of responses with an unexpected status code, or matching the retryCondition
+	ErrStatusCode = errors.New("unexpected status code")
+	// ErrParse matches the errors of responses which could not be decoded, or miss the value of the metric
+	ErrParse = errors.New("parse error")
+	// ErrEvaluation matches the errors of the success, failure and pending conditions
+	ErrEvaluation = errors.New("evaluation error")
+)
+
+func (e *connectionError) Is(target error) bool {
+	return target == ErrConnection
+}
+
+func (e *statusCodeError) Is(target error) bool {
+	return target == ErrStatusCode
+}
+
+// parseError is the error of a response which could not be decoded, or misses the value of the metric
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *parseError) Unwrap() error {
+	return e.err
+}
+
+func (e *parseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// evaluationError is the error of a condition of the metric
+type evaluationError struct {
+	err error
+}
+
+func (e *evaluationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *evaluationError) Unwrap() error {
+	return e.err
+}
+
+func (e *evaluationError) Is(target error) bool {
+	return target == ErrEvaluation
+}
+
+// asParseError classifies an error of the response parsing as a parse error, unless it comes from a condition
+func asParseError(err error) error {
+	if err == nil || errors.Is(err, ErrEvaluation) {
+		return err
+	}
+	return &parseError{err: err}
+}
+
+// asEvaluationError classifies an error of a condition as an evaluation error
+func asEvaluationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &evaluationError{err: err}
+}
+
+// ErrorCause returns the cause of an error of the Web metric as stored under ErrorCauseMetadataKey, or an empty
+// string when the cause is unknown
+func ErrorCause(err error) string {
+	switch {
+	case errors.Is(err, ErrEvaluation):
+		return ErrorCauseEvaluation
+	case errors.Is(err, ErrParse):
+		return ErrorCauseParse
+	case errors.Is(err, ErrStatusCode):
+		return ErrorCauseStatusCode
+	case errors.Is(err, ErrConnection):
+		return ErrorCauseConnection
+	}
+	return ""
+}
+
+// markMeasurementError marks the measurement as errored, recording the cause of the error in its metadata
+func markMeasurementError(measurement v1alpha1.Measurement, err error) v1alpha1.Measurement {
+	if cause := ErrorCause(err); cause != "" {
+		if measurement.Metadata == nil {
+			measurement.Metadata = map[string]string{}
+		}
+		measurement.Metadata[ErrorCauseMetadataKey] = cause
+	}
+	return metricutil.MarkMeasurementError(measurement, err)
+}
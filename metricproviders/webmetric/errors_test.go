@@ -0,0 +1,133 @@
+package webmetric
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestErrorCause(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/unavailable":
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		case "/string":
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, `{"payload": "{\"successRate\":"}`)
+		default:
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, `{"successRate": 0.99}`)
+		}
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name             string
+		web              v1alpha1.WebMetric
+		successCondition string
+		expectedCause    string
+	}{
+		{
+			name:             "connection",
+			web:              v1alpha1.WebMetric{URL: closed.URL, JSONPath: "{$.successRate}"},
+			successCondition: "result > 0.95",
+			expectedCause:    ErrorCauseConnection,
+		},
+		{
+			name:             "status code",
+			web:              v1alpha1.WebMetric{URL: server.URL + "/unavailable", JSONPath: "{$.successRate}"},
+			successCondition: "result > 0.95",
+			expectedCause:    ErrorCauseStatusCode,
+		},
+		{
+			name: "status code of all the fallback URLs",
+			web: v1alpha1.WebMetric{
+				URL:          server.URL + "/unavailable",
+				FallbackUrls: []string{closed.URL},
+				JSONPath:     "{$.successRate}",
+			},
+			successCondition: "result > 0.95",
+			expectedCause:    ErrorCauseStatusCode,
+		},
+		{
+			name: "status code of the baseline",
+			web: v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.successRate}",
+				Baseline: &v1alpha1.WebMetricBaseline{URL: server.URL + "/unavailable"},
+			},
+			successCondition: "result >= baseline",
+			expectedCause:    ErrorCauseStatusCode,
+		},
+		{
+			name:             "parse",
+			web:              v1alpha1.WebMetric{URL: server.URL + "/string", JSONStringPath: "{$.payload}", JSONPath: "{$.successRate}"},
+			successCondition: "result > 0.95",
+			expectedCause:    ErrorCauseParse,
+		},
+		{
+			name:             "missing value",
+			web:              v1alpha1.WebMetric{URL: server.URL, JSONPath: "{$.errorRate}"},
+			successCondition: "result > 0.95",
+			expectedCause:    ErrorCauseParse,
+		},
+		{
+			name:             "evaluation",
+			web:              v1alpha1.WebMetric{URL: server.URL, JSONPath: "{$.successRate}"},
+			successCondition: "result > unknown",
+			expectedCause:    ErrorCauseEvaluation,
+		},
+		{
+			name:             "invalid metric",
+			web:              v1alpha1.WebMetric{URL: server.URL, JSONPath: "{$.successRate}", GRPCWeb: true},
+			successCondition: "result > 0.95",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			web := test.web
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider:         v1alpha1.MetricProvider{Web: &web},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+			assert.Equal(t, test.expectedCause, measurement.Metadata[ErrorCauseMetadataKey], measurement.Message)
+		})
+	}
+}
+
+func TestErrorCauseOfWrappedErrors(t *testing.T) {
+	connErr := &connectionError{err: errors.New("connection refused")}
+	statusErr := &statusCodeError{statusCode: http.StatusBadGateway}
+
+	assert.ErrorIs(t, connErr, ErrConnection)
+	assert.ErrorIs(t, statusErr, ErrStatusCode)
+	assert.NotErrorIs(t, statusErr, ErrConnection)
+	assert.ErrorIs(t, asParseError(errors.New("unexpected end of JSON input")), ErrParse)
+	assert.ErrorIs(t, asParseError(asEvaluationError(errors.New("unknown name"))), ErrEvaluation)
+	assert.NotErrorIs(t, asParseError(asEvaluationError(errors.New("unknown name"))), ErrParse)
+
+	fallbackErr := &fallbackError{failures: []error{connErr}}
+	assert.ErrorIs(t, fallbackErr, ErrConnection)
+	assert.Equal(t, ErrorCauseConnection, ErrorCause(fallbackErr))
+	assert.Equal(t, "", ErrorCause(errors.New("invalid metric")))
+	assert.Equal(t, "", ErrorCause(nil))
+}
@@ -0,0 +1,44 @@
+package webmetric
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// maxExpectedBodyValueLength is the length of the body kept as the value of the measurements of ExpectedBody
+const maxExpectedBodyValueLength = 256
+
+// expectedBodyMeasurement sets the phase of the measurement by comparing the body of the response with the ExpectedBody
+func expectedBodyMeasurement(measurement v1alpha1.Measurement, expected *v1alpha1.WebMetricExpectedBody, response *webResponse) (v1alpha1.Measurement, error) {
+	body := string(bytes.TrimSpace(response.body))
+	var matches bool
+	switch {
+	case expected.Equals != "" && expected.Regex != "":
+		return measurement, errors.New("use either equals or regex; both cannot exist for expectedBody")
+	case expected.Regex != "":
+		regex, err := regexp.Compile(expected.Regex)
+		if err != nil {
+			return measurement, fmt.Errorf("invalid expectedBody regex: %v", err)
+		}
+		matches = regex.MatchString(body)
+	default:
+		matches = body == expected.Equals
+	}
+
+	value := body
+	if len(value) > maxExpectedBodyValueLength {
+		value = value[:maxExpectedBodyValueLength] + "..."
+	}
+	measurement.Value = value
+	if matches {
+		measurement.Phase = v1alpha1.AnalysisPhaseSuccessful
+	} else {
+		measurement.Phase = v1alpha1.AnalysisPhaseFailed
+		measurement.Message = "the response body does not match the expectedBody"
+	}
+	return measurement, nil
+}
@@ -0,0 +1,99 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestExpectedBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		expectedBody    v1alpha1.WebMetricExpectedBody
+		response        string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "exact match",
+			expectedBody:  v1alpha1.WebMetricExpectedBody{Equals: "OK"},
+			response:      "OK\n",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "OK",
+		},
+		{
+			name:            "exact mismatch",
+			expectedBody:    v1alpha1.WebMetricExpectedBody{Equals: "OK"},
+			response:        "OK, degraded",
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+			expectedValue:   "OK, degraded",
+			expectedMessage: "the response body does not match the expectedBody",
+		},
+		{
+			name:          "regex match",
+			expectedBody:  v1alpha1.WebMetricExpectedBody{Regex: "^(OK|healthy)$"},
+			response:      "healthy",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "healthy",
+		},
+		{
+			name:            "regex mismatch",
+			expectedBody:    v1alpha1.WebMetricExpectedBody{Regex: "^(OK|healthy)$"},
+			response:        "unhealthy",
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+			expectedValue:   "unhealthy",
+			expectedMessage: "the response body does not match the expectedBody",
+		},
+		{
+			name:            "invalid regex",
+			expectedBody:    v1alpha1.WebMetricExpectedBody{Regex: "(OK"},
+			response:        "OK",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "invalid expectedBody regex: error parsing regexp: missing closing ): `(OK`",
+		},
+		{
+			name:            "both equals and regex",
+			expectedBody:    v1alpha1.WebMetricExpectedBody{Equals: "OK", Regex: "OK"},
+			response:        "OK",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "use either equals or regex; both cannot exist for expectedBody",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "text/plain")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name: "foo",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:          server.URL,
+						ExpectedBody: &test.expectedBody,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
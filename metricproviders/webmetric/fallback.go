@@ -0,0 +1,93 @@
+package webmetric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// connectionError is the error of a request which got no response
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectionError) Unwrap() error {
+	return e.err
+}
+
+// statusCodeError is the error of a response with a non 2xx status code
+type statusCodeError struct {
+	statusCode int
+}
+
+func (e *statusCodeError) Error() string {
+	return fmt.Sprintf("received non 2xx response code: %v", e.statusCode)
+}
+
+// fallbackError is the error of a request which failed against the URL and all the FallbackUrls of the metric
+type fallbackError struct {
+	failures []error
+}
+
+func (e *fallbackError) Error() string {
+	messages := make([]string, len(e.failures))
+	for i, failure := range e.failures {
+		messages[i] = failure.Error()
+	}
+	return fmt.Sprintf("requests to all the URLs failed: %s", strings.Join(messages, "; "))
+}
+
+func (e *fallbackError) Unwrap() []error {
+	return e.failures
+}
+
+// fetchWithFallback fetches the URL of the metric, then its FallbackUrls in order while the requests fail with a
+// connection error, a 5xx response or a response matching the RetryCondition. The attempts share the timeout of the
+// client rather than each getting its own
+func (p *Provider) fetchWithFallback(metric v1alpha1.Metric, body []byte) (*webResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.client.Timeout)
+	defer cancel()
+
+	urls := append([]string{metric.Provider.Web.URL}, metric.Provider.Web.FallbackUrls...)
+	var failures []error
+	for i, url := range urls {
+		response, err := p.fetchContext(ctx, metric, url, body)
+		if err == nil {
+			return response, nil
+		}
+		if !shouldFallback(err) {
+			return nil, err
+		}
+		redactedURL := redactCredentialsString(url, metric.Provider.Web)
+		failures = append(failures, fmt.Errorf("%s: %w", redactedURL, err))
+		if i < len(urls)-1 {
+			p.logCtx.Warnf("Web metric request to %s failed, falling back to the next URL: %v", redactedURL, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, &fallbackError{failures: failures}
+}
+
+// shouldFallback returns whether the error of a request is worth retrying against another endpoint
+func shouldFallback(err error) bool {
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode >= http.StatusInternalServerError
+	}
+	var retryErr *retryConditionError
+	if errors.As(err, &retryErr) {
+		return true
+	}
+	var connErr *connectionError
+	return errors.As(err, &connErr)
+}
@@ -0,0 +1,180 @@
+package webmetric
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestFallbackURLs(t *testing.T) {
+	newServer := func(status int, requests *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			*requests++
+			if status != http.StatusOK {
+				rw.WriteHeader(status)
+				return
+			}
+			rw.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(rw, `{"server": %q}`, req.Host)
+		}))
+	}
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedServer.Close()
+
+	tests := []struct {
+		name                     string
+		primaryStatus            int
+		primaryClosed            bool
+		fallbackStatuses         []int
+		expectedPhase            v1alpha1.AnalysisPhase
+		expectedFallback         int
+		expectedFallbackRequests []int
+		expectedMessage          string
+	}{
+		{
+			name:                     "primary success does not fall back",
+			primaryStatus:            http.StatusOK,
+			fallbackStatuses:         []int{http.StatusOK},
+			expectedPhase:            v1alpha1.AnalysisPhaseSuccessful,
+			expectedFallback:         -1,
+			expectedFallbackRequests: []int{0},
+		},
+		{
+			name:                     "primary 5xx falls back",
+			primaryStatus:            http.StatusServiceUnavailable,
+			fallbackStatuses:         []int{http.StatusOK},
+			expectedPhase:            v1alpha1.AnalysisPhaseSuccessful,
+			expectedFallback:         0,
+			expectedFallbackRequests: []int{1},
+		},
+		{
+			name:                     "primary connection error falls back",
+			primaryClosed:            true,
+			fallbackStatuses:         []int{http.StatusOK},
+			expectedPhase:            v1alpha1.AnalysisPhaseSuccessful,
+			expectedFallback:         0,
+			expectedFallbackRequests: []int{1},
+		},
+		{
+			name:                     "fallbacks are tried in order",
+			primaryStatus:            http.StatusInternalServerError,
+			fallbackStatuses:         []int{http.StatusBadGateway, http.StatusOK, http.StatusOK},
+			expectedPhase:            v1alpha1.AnalysisPhaseSuccessful,
+			expectedFallback:         1,
+			expectedFallbackRequests: []int{1, 1, 0},
+		},
+		{
+			name:                     "client errors do not fall back",
+			primaryStatus:            http.StatusNotFound,
+			fallbackStatuses:         []int{http.StatusOK},
+			expectedPhase:            v1alpha1.AnalysisPhaseError,
+			expectedFallbackRequests: []int{0},
+			expectedMessage:          "received non 2xx response code: 404",
+		},
+		{
+			name:                     "all the URLs fail",
+			primaryStatus:            http.StatusInternalServerError,
+			fallbackStatuses:         []int{http.StatusServiceUnavailable},
+			expectedPhase:            v1alpha1.AnalysisPhaseError,
+			expectedFallbackRequests: []int{1},
+			expectedMessage:          "requests to all the URLs failed: ",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primaryURL := closedServer.URL
+			primaryRequests := 0
+			if !test.primaryClosed {
+				primary := newServer(test.primaryStatus, &primaryRequests)
+				defer primary.Close()
+				primaryURL = primary.URL
+			}
+			fallbackRequests := make([]int, len(test.fallbackStatuses))
+			var fallbackURLs []string
+			for i, status := range test.fallbackStatuses {
+				fallback := newServer(status, &fallbackRequests[i])
+				defer fallback.Close()
+				fallbackURLs = append(fallbackURLs, fallback.URL)
+			}
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:          primaryURL,
+						FallbackUrls: fallbackURLs,
+						JSONPath:     "{$.server}",
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.True(t, strings.HasPrefix(measurement.Message, test.expectedMessage), measurement.Message)
+			assert.Equal(t, test.expectedFallbackRequests, fallbackRequests)
+			if test.expectedPhase == v1alpha1.AnalysisPhaseSuccessful {
+				expectedURL := primaryURL
+				if test.expectedFallback >= 0 {
+					expectedURL = fallbackURLs[test.expectedFallback]
+				}
+				assert.Equal(t, fmt.Sprintf("%q", strings.TrimPrefix(expectedURL, "http://")), measurement.Value)
+			}
+		})
+	}
+}
+
+func TestFallbackURLsShareTimeout(t *testing.T) {
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(time.Second):
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	})
+	primary := httptest.NewServer(handler)
+	defer primary.Close()
+	fallbackRequests := 0
+	fallback := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fallbackRequests++
+		io.WriteString(rw, `{}`)
+	}))
+	defer fallback.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:          primary.URL,
+				FallbackUrls: []string{fallback.URL},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client := &http.Client{Timeout: 100 * time.Millisecond}
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	start := time.Now()
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Contains(t, measurement.Message, "requests to all the URLs failed")
+	assert.Equal(t, 0, fallbackRequests)
+	assert.Less(t, time.Since(start), time.Second)
+}
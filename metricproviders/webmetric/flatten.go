@@ -0,0 +1,39 @@
+package webmetric
+
+import "strconv"
+
+// flatten converts a parsed JSON document into a map of the dotted paths of its values to the values. Array
+// elements are keyed by their index, and empty objects and arrays are kept as values
+func flatten(data any) map[string]any {
+	flat := map[string]any{}
+	flattenInto(flat, "", data)
+	return flat
+}
+
+func flattenInto(flat map[string]any, prefix string, value any) {
+	key := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && prefix != "" {
+			flat[prefix] = v
+		}
+		for k, child := range v {
+			flattenInto(flat, key(k), child)
+		}
+	case []any:
+		if len(v) == 0 && prefix != "" {
+			flat[prefix] = v
+		}
+		for i, child := range v {
+			flattenInto(flat, key(strconv.Itoa(i)), child)
+		}
+	default:
+		flat[prefix] = v
+	}
+}
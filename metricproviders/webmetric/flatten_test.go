@@ -0,0 +1,80 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const nestedBody = `{
+	"data": {
+		"service": {"name": "checkout", "labels": {}},
+		"items": [{"errors": 1}, {"errors": 2, "tags": ["a", "b"]}],
+		"empty": []
+	},
+	"ok": true,
+	"missing": null
+}`
+
+func TestFlatten(t *testing.T) {
+	var data any
+	assert.NoError(t, json.Unmarshal([]byte(nestedBody), &data))
+
+	assert.Equal(t, map[string]any{
+		"data.service.name":   "checkout",
+		"data.service.labels": map[string]any{},
+		"data.items.0.errors": float64(1),
+		"data.items.1.errors": float64(2),
+		"data.items.1.tags.0": "a",
+		"data.items.1.tags.1": "b",
+		"data.empty":          []any{},
+		"ok":                  true,
+		"missing":             nil,
+	}, flatten(data))
+}
+
+func TestRunWithFlatten(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, nestedBody)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		condition     string
+		flatten       bool
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		{condition: `flat["data.items.1.errors"] == 2 && flat["data.service.name"] == "checkout"`, flatten: true, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{condition: `flat["data.items.0.errors"] > 1`, flatten: true, expectedPhase: v1alpha1.AnalysisPhaseFailed},
+		{condition: `flat["data.items.1.errors"] == 2`, flatten: false, expectedPhase: v1alpha1.AnalysisPhaseError},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: test.condition,
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:     server.URL,
+					Flatten: test.flatten,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+	}
+}
@@ -0,0 +1,62 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// followLink fetches the link selected by the FollowLink of the metric from the response with a GET request, with the
+// headers and authentication of the metric, and returns the response of the link. Relative links are resolved against
+// the URL of the metric, and the links to other hosts are rejected since they would receive the credentials
+func (p *Provider) followLink(metric v1alpha1.Metric, response *webResponse) (*webResponse, error) {
+	parser, err := newJSONParser(metric.Provider.Web, "followLink", metric.Provider.Web.FollowLink)
+	if err != nil {
+		return nil, fmt.Errorf("invalid followLink: %v", err)
+	}
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return nil, &parseError{err: fmt.Errorf("followLink response is not a JSON document: %v", err)}
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("Could not find followLink in body: %s", err)}
+	}
+	val, _, err := getValue(fullResults)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("followLink: %v", err)}
+	}
+	href, ok := val.(string)
+	if !ok || href == "" {
+		return nil, &parseError{err: fmt.Errorf("followLink must select a URL, got: %v", val)}
+	}
+
+	link, err := resolveLink(metric.Provider.Web.URL, href)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := p.fetch(withoutMeasurementRequest(metric), link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("followLink request failed: %w", err)
+	}
+	return linked, nil
+}
+
+// resolveLink resolves the link against the base URL, rejecting the links to another host
+func resolveLink(baseURL, href string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", &parseError{err: fmt.Errorf("invalid followLink URL %q: %v", href, err)}
+	}
+	link := base.ResolveReference(ref)
+	if link.Scheme != base.Scheme || link.Host != base.Host {
+		return "", &parseError{err: fmt.Errorf("followLink URL %q is not on the host of the url", href)}
+	}
+	return link.String(), nil
+}
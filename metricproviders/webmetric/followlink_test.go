@@ -0,0 +1,101 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestFollowLink(t *testing.T) {
+	var linkAuthorization string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/api/v1/relative":
+			io.WriteString(rw, `{"_links": {"metrics": {"href": "/api/v1/metrics/42"}}}`)
+		case "/api/v1/absolute":
+			io.WriteString(rw, `{"_links": {"metrics": {"href": "`+server.URL+`/api/v1/metrics/42"}}}`)
+		case "/api/v1/other-host":
+			io.WriteString(rw, `{"_links": {"metrics": {"href": "http://attacker.example.com/api/v1/metrics/42"}}}`)
+		case "/api/v1/no-link":
+			io.WriteString(rw, `{"_links": {}}`)
+		case "/api/v1/metrics/42":
+			linkAuthorization = req.Header.Get("Authorization")
+			io.WriteString(rw, `{"successRate": 0.99}`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		path            string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "relative link",
+			path:          "/api/v1/relative",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:          "absolute link",
+			path:          "/api/v1/absolute",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:            "missing link",
+			path:            "/api/v1/no-link",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "Could not find followLink in body: metrics is not found",
+		},
+		{
+			name:            "link to another host",
+			path:            "/api/v1/other-host",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: `followLink URL "http://attacker.example.com/api/v1/metrics/42" is not on the host of the url`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			linkAuthorization = ""
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result > 0.9",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:        server.URL + test.path,
+						Headers:    []v1alpha1.WebMetricHeader{{Key: "Authorization", Value: "Bearer token"}},
+						FollowLink: "{$._links.metrics.href}",
+						JSONPath:   "{$.successRate}",
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			if test.expectedPhase == v1alpha1.AnalysisPhaseSuccessful {
+				assert.Equal(t, "Bearer token", linkAuthorization)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// grafanaValue returns the latest value of the first series of a Grafana data source query response, i.e. of the
+// first number field of the first frame of the result. The result of the RefId is used, or else the first result by
+// refId
+func grafanaValue(grafana *v1alpha1.WebMetricGrafana, data any) (any, string, error) {
+	body, ok := data.(map[string]any)
+	if !ok {
+		return nil, "", errors.New("grafana response is not a JSON object")
+	}
+	results, ok := body["results"].(map[string]any)
+	if !ok || len(results) == 0 {
+		return nil, "", errors.New("grafana response has no results")
+	}
+	refID := grafana.RefId
+	if refID == "" {
+		refIDs := make([]string, 0, len(results))
+		for id := range results {
+			refIDs = append(refIDs, id)
+		}
+		sort.Strings(refIDs)
+		refID = refIDs[0]
+	}
+	result, ok := results[refID].(map[string]any)
+	if !ok {
+		return nil, "", fmt.Errorf("grafana response has no %s result", refID)
+	}
+	if message, ok := result["error"].(string); ok && message != "" {
+		return nil, "", fmt.Errorf("grafana %s result has an error: %s", refID, message)
+	}
+	frames, ok := result["frames"].([]any)
+	if !ok || len(frames) == 0 {
+		return nil, "", fmt.Errorf("grafana %s result has no frames", refID)
+	}
+	frame, _ := frames[0].(map[string]any)
+
+	timeField, valueField := -1, -1
+	schema, _ := frame["schema"].(map[string]any)
+	fields, _ := schema["fields"].([]any)
+	for i, f := range fields {
+		field, _ := f.(map[string]any)
+		switch field["type"] {
+		case "time":
+			if timeField < 0 {
+				timeField = i
+			}
+		case "number":
+			if valueField < 0 {
+				valueField = i
+			}
+		}
+	}
+	if valueField < 0 {
+		return nil, "", fmt.Errorf("grafana %s frame has no number field", refID)
+	}
+	frameData, _ := frame["data"].(map[string]any)
+	columns, _ := frameData["values"].([]any)
+	if valueField >= len(columns) {
+		return nil, "", fmt.Errorf("grafana %s frame has no values", refID)
+	}
+	values, _ := columns[valueField].([]any)
+	var times []any
+	if timeField >= 0 && timeField < len(columns) {
+		times, _ = columns[timeField].([]any)
+	}
+
+	// the latest value is the one with the latest time, or the last one without a time field. Null values are
+	// skipped, since Grafana fills the missing points of a series with them
+	latest := -1
+	var latestTime float64
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if times == nil {
+			latest = i
+			continue
+		}
+		if i >= len(times) {
+			break
+		}
+		t, ok := toFloat(times[i])
+		if ok && (latest < 0 || t >= latestTime) {
+			latest, latestTime = i, t
+		}
+	}
+	if latest < 0 {
+		return nil, "", fmt.Errorf("grafana %s frame has no values", refID)
+	}
+	val := values[latest]
+	valBytes, err := json.Marshal(val)
+	return val, string(valBytes), err
+}
@@ -0,0 +1,136 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// grafanaResponse is a response of the /api/ds/query endpoint of Grafana with two queries
+const grafanaResponse = `{
+  "results": {
+    "B": {
+      "status": 200,
+      "frames": [{
+        "schema": {"refId": "B", "fields": [
+          {"name": "Time", "type": "time", "typeInfo": {"frame": "time.Time"}},
+          {"name": "Value", "type": "number", "typeInfo": {"frame": "float64"}}
+        ]},
+        "data": {"values": [[1700000000000, 1700000060000], [250, 300]]}
+      }]
+    },
+    "A": {
+      "status": 200,
+      "frames": [{
+        "schema": {"refId": "A", "name": "error_rate", "fields": [
+          {"name": "Time", "type": "time", "typeInfo": {"frame": "time.Time"}},
+          {"name": "Value", "type": "number", "labels": {"service": "checkout"}, "typeInfo": {"frame": "float64", "nullable": true}}
+        ]},
+        "data": {"values": [[1700000120000, 1700000000000, 1700000060000, 1700000180000], [0.02, 0.01, 0.03, null]]}
+      }, {
+        "schema": {"refId": "A", "name": "error_rate", "fields": [
+          {"name": "Time", "type": "time"},
+          {"name": "Value", "type": "number", "labels": {"service": "cart"}}
+        ]},
+        "data": {"values": [[1700000000000], [0.5]]}
+      }]
+    },
+    "C": {
+      "status": 400,
+      "error": "bad query",
+      "frames": []
+    }
+  }
+}`
+
+func TestRunWithGrafana(t *testing.T) {
+	tests := []struct {
+		name            string
+		grafana         *v1alpha1.WebMetricGrafana
+		response        string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "latest value of the first series",
+			grafana:       &v1alpha1.WebMetricGrafana{},
+			response:      grafanaResponse,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.02",
+		},
+		{
+			name:          "refId",
+			grafana:       &v1alpha1.WebMetricGrafana{RefId: "B"},
+			response:      grafanaResponse,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "300",
+		},
+		{
+			name:            "result with an error",
+			grafana:         &v1alpha1.WebMetricGrafana{RefId: "C"},
+			response:        grafanaResponse,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "grafana C result has an error: bad query",
+		},
+		{
+			name:            "unknown refId",
+			grafana:         &v1alpha1.WebMetricGrafana{RefId: "D"},
+			response:        grafanaResponse,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "grafana response has no D result",
+		},
+		{
+			name:            "no values",
+			grafana:         &v1alpha1.WebMetricGrafana{},
+			response:        `{"results": {"A": {"frames": [{"schema": {"fields": [{"name": "Time", "type": "time"}, {"name": "Value", "type": "number"}]}, "data": {"values": [[], []]}}]}}}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "grafana A frame has no values",
+		},
+		{
+			name:            "no results",
+			grafana:         &v1alpha1.WebMetricGrafana{},
+			response:        `{"message": "Unauthorized"}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "grafana response has no results",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result < 0.05",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						Method:  v1alpha1.WebMetricMethodPost,
+						URL:     server.URL + "/api/ds/query",
+						Grafana: test.grafana,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package webmetric
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const (
+	// GRPCWebContentTypeValue is the content type of gRPC-Web requests
+	GRPCWebContentTypeValue = "application/grpc-web+proto"
+
+	grpcStatusKey  = "Grpc-Status"
+	grpcMessageKey = "Grpc-Message"
+	// grpcWebTrailerFlag flags the frame holding the trailers in a gRPC-Web body
+	grpcWebTrailerFlag  = 0x80
+	grpcWebFrameHdrSize = 5
+)
+
+// grpcWebFrame frames the body of the request as a single gRPC-Web message
+func grpcWebFrame(body []byte) []byte {
+	frame := make([]byte, grpcWebFrameHdrSize, grpcWebFrameHdrSize+len(body))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(body)))
+	return append(frame, body...)
+}
+
+// grpcWebMeasurement sets the value and phase of the measurement from the grpc-status of the response
+func grpcWebMeasurement(measurement v1alpha1.Measurement, response *webResponse) (v1alpha1.Measurement, error) {
+	status, message, err := grpcWebStatus(response)
+	if err != nil {
+		return measurement, err
+	}
+	measurement.Value = status
+	if status == "0" {
+		measurement.Phase = v1alpha1.AnalysisPhaseSuccessful
+	} else {
+		measurement.Phase = v1alpha1.AnalysisPhaseFailed
+		measurement.Message = fmt.Sprintf("grpc-status %s: %s", status, message)
+	}
+	return measurement, nil
+}
+
+// grpcWebStatus returns the grpc-status and grpc-message of the response. They are in the headers of trailers-only
+// responses, in the HTTP trailers over HTTP/2, and in the trailer frame of the body otherwise
+func grpcWebStatus(response *webResponse) (string, string, error) {
+	for _, header := range []http.Header{response.header, response.trailer} {
+		if status := header.Get(grpcStatusKey); status != "" {
+			return status, decodeGRPCMessage(header.Get(grpcMessageKey)), nil
+		}
+	}
+	trailer, err := grpcWebBodyTrailer(response.body)
+	if err != nil {
+		return "", "", err
+	}
+	status := trailer.Get(grpcStatusKey)
+	if status == "" {
+		return "", "", errors.New("gRPC-Web response has no grpc-status")
+	}
+	return status, decodeGRPCMessage(trailer.Get(grpcMessageKey)), nil
+}
+
+// grpcWebBodyTrailer returns the trailers of the trailer frame of a gRPC-Web body
+func grpcWebBodyTrailer(body []byte) (http.Header, error) {
+	trailer := http.Header{}
+	for len(body) > 0 {
+		if len(body) < grpcWebFrameHdrSize {
+			return nil, errors.New("truncated gRPC-Web frame header")
+		}
+		flag := body[0]
+		length := binary.BigEndian.Uint32(body[1:grpcWebFrameHdrSize])
+		body = body[grpcWebFrameHdrSize:]
+		if uint64(len(body)) < uint64(length) {
+			return nil, errors.New("truncated gRPC-Web frame")
+		}
+		payload := body[:length]
+		body = body[length:]
+		if flag&grpcWebTrailerFlag == 0 {
+			continue
+		}
+		for _, line := range strings.Split(string(payload), "\r\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if ok {
+				trailer.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+			}
+		}
+	}
+	return trailer, nil
+}
+
+// decodeGRPCMessage decodes the percent encoded grpc-message
+func decodeGRPCMessage(message string) string {
+	if decoded, err := url.PathUnescape(message); err == nil {
+		return decoded
+	}
+	return message
+}
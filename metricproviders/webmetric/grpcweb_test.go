@@ -0,0 +1,146 @@
+package webmetric
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func grpcWebTrailerFrame(trailer string) []byte {
+	frame := make([]byte, grpcWebFrameHdrSize, grpcWebFrameHdrSize+len(trailer))
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(trailer)))
+	return append(frame, trailer...)
+}
+
+func TestGRPCWeb(t *testing.T) {
+	tests := []struct {
+		name            string
+		handler         func(rw http.ResponseWriter)
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name: "ok status in the body trailer frame",
+			handler: func(rw http.ResponseWriter) {
+				rw.Write(grpcWebFrame([]byte{0x08, 0x01}))
+				rw.Write(grpcWebTrailerFrame("grpc-status: 0\r\ngrpc-message: \r\n"))
+			},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0",
+		},
+		{
+			name: "error status in the body trailer frame",
+			handler: func(rw http.ResponseWriter) {
+				rw.Write(grpcWebTrailerFrame("grpc-status: 14\r\ngrpc-message: upstream%20unavailable\r\n"))
+			},
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+			expectedValue:   "14",
+			expectedMessage: "grpc-status 14: upstream unavailable",
+		},
+		{
+			name: "error status of a trailers-only response",
+			handler: func(rw http.ResponseWriter) {
+				rw.Header().Set("Grpc-Status", "5")
+				rw.Header().Set("Grpc-Message", "not found")
+			},
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+			expectedValue:   "5",
+			expectedMessage: "grpc-status 5: not found",
+		},
+		{
+			name: "ok status in the HTTP trailers",
+			handler: func(rw http.ResponseWriter) {
+				rw.Header().Set("Trailer", "Grpc-Status")
+				rw.Write(grpcWebFrame(nil))
+				rw.Header().Set("Grpc-Status", "0")
+			},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0",
+		},
+		{
+			name: "missing status",
+			handler: func(rw http.ResponseWriter) {
+				rw.Write(grpcWebFrame(nil))
+			},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "gRPC-Web response has no grpc-status",
+		},
+		{
+			name: "truncated frame",
+			handler: func(rw http.ResponseWriter) {
+				rw.Write(grpcWebFrame([]byte("message"))[:8])
+			},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "truncated gRPC-Web frame",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, GRPCWebContentTypeValue, req.Header.Get(ContentTypeKey))
+				body, _ := io.ReadAll(req.Body)
+				assert.Equal(t, grpcWebFrame([]byte("request")), body)
+				rw.Header().Set(ContentTypeKey, GRPCWebContentTypeValue)
+				test.handler(rw)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name: "foo",
+				// the conditions are not evaluated
+				SuccessCondition: "false",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:     server.URL,
+						Method:  v1alpha1.WebMetricMethodPost,
+						Body:    "request",
+						GRPCWeb: true,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.NotNil(t, measurement.FinishedAt)
+		})
+	}
+}
+
+func TestGRPCWebRequiresPost(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:     "http://localhost",
+				GRPCWeb: true,
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, "GRPCWeb can only be used with the POST WebMetric Method type", measurement.Message)
+}
@@ -0,0 +1,37 @@
+package webmetric
+
+import (
+	"net/http"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// setHeaders adds the headers of the metric to a request. With the add HeaderMode, the headers with the same key are
+// all sent, otherwise the last one overrides the others
+func setHeaders(header http.Header, web *v1alpha1.WebMetric) {
+	for _, h := range web.Headers {
+		if web.HeaderMode == v1alpha1.WebMetricHeaderModeAdd {
+			header.Add(h.Key, h.Value)
+		} else {
+			header.Set(h.Key, h.Value)
+		}
+	}
+}
+
+// duplicateHeaders returns the keys of the headers of the metric which are overridden by another header with the
+// same key, which is likely a mistake with the set HeaderMode
+func duplicateHeaders(web *v1alpha1.WebMetric) []string {
+	if web.HeaderMode == v1alpha1.WebMetricHeaderModeAdd {
+		return nil
+	}
+	var duplicates []string
+	seen := map[string]int{}
+	for _, h := range web.Headers {
+		key := http.CanonicalHeaderKey(h.Key)
+		seen[key]++
+		if seen[key] == 2 {
+			duplicates = append(duplicates, key)
+		}
+	}
+	return duplicates
+}
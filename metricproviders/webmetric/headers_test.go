@@ -0,0 +1,101 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestHeaderMode(t *testing.T) {
+	headers := []v1alpha1.WebMetricHeader{
+		{Key: "Cookie", Value: "session=abc"},
+		{Key: "X-Tag", Value: "canary"},
+		{Key: "cookie", Value: "region=eu"},
+		{Key: "X-Tag", Value: "checkout"},
+	}
+	tests := []struct {
+		name            string
+		headerMode      v1alpha1.WebMetricHeaderMode
+		expectedCookies []string
+		expectedTags    []string
+	}{
+		{
+			name:            "set by default",
+			expectedCookies: []string{"region=eu"},
+			expectedTags:    []string{"checkout"},
+		},
+		{
+			name:            "set",
+			headerMode:      v1alpha1.WebMetricHeaderModeSet,
+			expectedCookies: []string{"region=eu"},
+			expectedTags:    []string{"checkout"},
+		},
+		{
+			name:            "add",
+			headerMode:      v1alpha1.WebMetricHeaderModeAdd,
+			expectedCookies: []string{"session=abc", "region=eu"},
+			expectedTags:    []string{"canary", "checkout"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cookies, tags []string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				cookies = req.Header.Values("Cookie")
+				tags = req.Header.Values("X-Tag")
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, `{"ok": true}`)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:        server.URL,
+						JSONPath:   "{$.ok}",
+						Headers:    headers,
+						HeaderMode: test.headerMode,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedCookies, cookies)
+			assert.Equal(t, test.expectedTags, tags)
+		})
+	}
+}
+
+func TestDuplicateHeaders(t *testing.T) {
+	web := &v1alpha1.WebMetric{
+		Headers: []v1alpha1.WebMetricHeader{
+			{Key: "x-tag", Value: "canary"},
+			{Key: "Authorization", Value: "Bearer token"},
+			{Key: "X-Tag", Value: "checkout"},
+			{Key: "X-TAG", Value: "cart"},
+		},
+	}
+	assert.Equal(t, []string{"X-Tag"}, duplicateHeaders(web))
+
+	web.HeaderMode = v1alpha1.WebMetricHeaderModeAdd
+	assert.Empty(t, duplicateHeaders(web))
+
+	web.HeaderMode = ""
+	web.Headers = web.Headers[:2]
+	assert.Empty(t, duplicateHeaders(web))
+}
@@ -0,0 +1,33 @@
+package webmetric
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+)
+
+var (
+	http1TransportsMu sync.Mutex
+	// http1Transports are shared so the transports wrapping them, such as the DNS caching transports, are too
+	http1Transports = map[*http.Transport]*http.Transport{}
+)
+
+// http1Transport returns a transport like base, using HTTP/1.1 even with the servers supporting HTTP/2
+func http1Transport(base *http.Transport) *http.Transport {
+	http1TransportsMu.Lock()
+	defer http1TransportsMu.Unlock()
+	if t, ok := http1Transports[base]; ok {
+		return t
+	}
+	t := base.Clone()
+	t.ForceAttemptHTTP2 = false
+	// a non nil map disables the HTTP/2 upgrade of TLS connections
+	t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	// the config of a transport which was already used may offer h2 in the ALPN negotiation
+	t.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	http1Transports[base] = t
+	return t
+}
@@ -0,0 +1,55 @@
+package webmetric
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestForceHTTP1(t *testing.T) {
+	var proto string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proto = req.Proto
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+
+	for _, forceHTTP1 := range []bool{false, true} {
+		t.Run(map[bool]string{false: "HTTP/2", true: "HTTP/1.1"}[forceHTTP1], func(t *testing.T) {
+			proto = ""
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:        server.URL,
+						JSONPath:   "{$.ok}",
+						ForceHttp1: forceHTTP1,
+						TLSConfig:  &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{hex.EncodeToString(fingerprint[:])}},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+			assert.Equal(t, map[bool]string{false: "HTTP/2.0", true: "HTTP/1.1"}[forceHTTP1], proto)
+		})
+	}
+}
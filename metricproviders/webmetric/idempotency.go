@@ -0,0 +1,40 @@
+package webmetric
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// withIdempotencyKey returns a copy of the metric sending a new key in its IdempotencyKeyHeader. The copy is used for
+// all the attempts of the measurement request, so they share the key while other measurements get their own
+func withIdempotencyKey(metric v1alpha1.Metric) v1alpha1.Metric {
+	web := *metric.Provider.Web
+	web.Headers = append(append([]v1alpha1.WebMetricHeader{}, web.Headers...), v1alpha1.WebMetricHeader{
+		Key:   web.IdempotencyKeyHeader,
+		Value: uuid.NewString(),
+	})
+	metric.Provider.Web = &web
+	return metric
+}
+
+// correlationID returns the ID shared by the requests of all the measurements of the run, its UID
+func correlationID(run *v1alpha1.AnalysisRun) string {
+	if run.UID != "" {
+		return string(run.UID)
+	}
+	// the runs which are not stored yet have no UID
+	return run.Namespace + "/" + run.Name
+}
+
+// withCorrelationID returns a copy of the metric sending the correlation ID of the run in its CorrelationIdHeader, so
+// the logs of the backend can be tied to the run
+func withCorrelationID(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) v1alpha1.Metric {
+	web := *metric.Provider.Web
+	web.Headers = append(append([]v1alpha1.WebMetricHeader{}, web.Headers...), v1alpha1.WebMetricHeader{
+		Key:   web.CorrelationIdHeader,
+		Value: correlationID(run),
+	})
+	metric.Provider.Web = &web
+	return metric
+}
@@ -0,0 +1,135 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	var primaryKeys, fallbackKeys []string
+	primary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		primaryKeys = append(primaryKeys, req.Header.Get("Idempotency-Key"))
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fallbackKeys = append(fallbackKeys, req.Header.Get("Idempotency-Key"))
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer fallback.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:                  primary.URL,
+				FallbackUrls:         []string{fallback.URL},
+				Method:               v1alpha1.WebMetricMethodPost,
+				Body:                 `{"count": 1}`,
+				IdempotencyKeyHeader: "Idempotency-Key",
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	for i := 0; i < 2; i++ {
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+
+	if assert.Len(t, primaryKeys, 2) && assert.Len(t, fallbackKeys, 2) {
+		// the attempts of a measurement share its key
+		assert.Equal(t, primaryKeys[0], fallbackKeys[0])
+		assert.Equal(t, primaryKeys[1], fallbackKeys[1])
+		// separate measurements have their own key
+		assert.NotEqual(t, primaryKeys[0], primaryKeys[1])
+		_, err := uuid.Parse(primaryKeys[0])
+		assert.NoError(t, err)
+	}
+	// the metric itself is not modified
+	assert.Empty(t, metric.Provider.Web.Headers)
+}
+
+func TestNoIdempotencyKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Empty(t, req.Header.Get("Idempotency-Key"))
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider:         v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{URL: server.URL}},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+}
+
+func TestCorrelationID(t *testing.T) {
+	var ids, thresholdIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/threshold" {
+			thresholdIDs = append(thresholdIDs, req.Header.Get("X-Correlation-ID"))
+			io.WriteString(rw, `1`)
+			return
+		}
+		ids = append(ids, req.Header.Get("X-Correlation-ID"))
+		io.WriteString(rw, `{"errors": 0}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.errors < threshold",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:                 server.URL,
+				ThresholdUrl:        server.URL + "/threshold",
+				CorrelationIdHeader: "X-Correlation-ID",
+			},
+		},
+	}
+	run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns", UID: "7f1c4c1e-2f4b-4d0e-9a55-c1b7e0d6a3f2"}}
+	otherRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "other-run", Namespace: "ns", UID: "0b8e7c1a-96d4-4f0f-8f43-2d8a1c9e5b70"}}
+	for _, r := range []*v1alpha1.AnalysisRun{run, run, run, otherRun} {
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(r, metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+
+	// all the requests of the measurements of a run share its id
+	id := string(run.UID)
+	assert.Equal(t, []string{id, id, id, string(otherRun.UID)}, ids)
+	assert.Equal(t, ids, thresholdIDs)
+	// the metric itself is not modified
+	assert.Empty(t, metric.Provider.Web.Headers)
+}
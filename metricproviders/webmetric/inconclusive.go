@@ -0,0 +1,95 @@
+package webmetric
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// InconclusiveRetriesMetadataKey is the measurement metadata key of the number of times an Inconclusive measurement
+// was taken again by the RetryOnInconclusive
+const InconclusiveRetriesMetadataKey = "inconclusiveRetries"
+
+// retryOnInconclusiveDelay returns the delay of the RetryOnInconclusive of the metric. The delays of all the retries
+// must add up to less than the timeout of the metric, so the retries hold the analysis worker for a bounded time
+func retryOnInconclusiveDelay(metric v1alpha1.Metric) (time.Duration, error) {
+	retry := metric.Provider.Web.RetryOnInconclusive
+	if retry.Delay == "" {
+		return 0, nil
+	}
+	delay, err := retry.Delay.Duration()
+	if err != nil {
+		return 0, fmt.Errorf("invalid retryOnInconclusive delay: %v", err)
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("retryOnInconclusive delay must not be negative, got: %s", retry.Delay)
+	}
+	timeout := requestTimeout(withDefaults(metric))
+	if total := delay * time.Duration(retry.Count); total >= timeout {
+		return 0, fmt.Errorf("retryOnInconclusive delays of %s must add up to less than the timeoutSeconds of %s", total, timeout)
+	}
+	return delay, nil
+}
+
+// retryOnInconclusive takes an Inconclusive measurement again, after the delay, until it settles or the retries are
+// exhausted. The retries end within the timeout of the metric since the first measurement: the requests of a retry
+// time out at the deadline, and no retry is started past it. The last measurement is returned, started at the first
+// one
+func (p *Provider) retryOnInconclusive(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	retry := metric.Provider.Web.RetryOnInconclusive
+	if measurement.Phase != v1alpha1.AnalysisPhaseInconclusive {
+		return measurement
+	}
+	delay, err := retryOnInconclusiveDelay(metric)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+
+	startedAt := measurement.StartedAt
+	deadline := time.Now().Add(p.client.Timeout)
+	if startedAt != nil {
+		deadline = startedAt.Add(p.client.Timeout)
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	retries := int64(0)
+	for ; retries < retry.Count && measurement.Phase == v1alpha1.AnalysisPhaseInconclusive; retries++ {
+		p.logCtx.Infof("Web metric measurement is Inconclusive, retrying in %s (%d/%d)", delay, retries+1, retry.Count)
+		if !waitRetry(ctx, delay) {
+			p.logCtx.Infof("Web metric measurement is Inconclusive after the timeout of %s, not retrying", p.client.Timeout)
+			break
+		}
+		measurement = p.runRetryMeasurement(run, metric, deadline)
+	}
+	measurement.StartedAt = startedAt
+	if measurement.Metadata == nil {
+		measurement.Metadata = map[string]string{}
+	}
+	measurement.Metadata[InconclusiveRetriesMetadataKey] = strconv.FormatInt(retries, 10)
+	return measurement
+}
+
+// runRetryMeasurement takes a measurement with the requests timing out at the deadline of the retries
+func (p *Provider) runRetryMeasurement(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, deadline time.Time) v1alpha1.Measurement {
+	defaultClient := p.client
+	defer func() { p.client = defaultClient }()
+	client := *p.client
+	client.Timeout = time.Until(deadline)
+	p.client = &client
+	return p.runMeasurement(run, metric)
+}
+
+// waitRetry waits for the delay, and returns false without waiting it out when the context is done first
+func waitRetry(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return ctx.Err() == nil
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,156 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRetryOnInconclusive(t *testing.T) {
+	tests := []struct {
+		name             string
+		pendingResponses int32
+		count            int64
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedRequests int32
+		expectedRetries  string
+	}{
+		{
+			name:             "conclusive at once",
+			pendingResponses: 0,
+			count:            3,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedRequests: 1,
+		},
+		{
+			name:             "settles after retries",
+			pendingResponses: 2,
+			count:            3,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedRequests: 3,
+			expectedRetries:  "2",
+		},
+		{
+			name:             "retries exhausted",
+			pendingResponses: 5,
+			count:            3,
+			expectedPhase:    v1alpha1.AnalysisPhaseInconclusive,
+			expectedRequests: 4,
+			expectedRetries:  "3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				if requests.Add(1) <= test.pendingResponses {
+					fmt.Fprint(rw, `{"state": "pending"}`)
+					return
+				}
+				fmt.Fprint(rw, `{"state": "done", "value": 1}`)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == 1",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:              server.URL,
+						PendingCondition: `result.state == "pending"`,
+						JSONPath:         "{$.value}",
+						RetryOnInconclusive: &v1alpha1.WebMetricRetryOnInconclusive{
+							Count: test.count,
+							Delay: "10ms",
+						},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedRequests, requests.Load())
+			assert.Equal(t, test.expectedRetries, measurement.Metadata[InconclusiveRetriesMetadataKey])
+			assert.True(t, measurement.StartedAt.Before(measurement.FinishedAt))
+		})
+	}
+}
+
+func TestRetryOnInconclusiveInvalidDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"state": "pending"}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:                 server.URL,
+				PendingCondition:    `result.state == "pending"`,
+				RetryOnInconclusive: &v1alpha1.WebMetricRetryOnInconclusive{Count: 1, Delay: "soon"},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, `invalid retryOnInconclusive delay: time: invalid duration "soon"`, measurement.Message)
+}
+
+func TestRetryOnInconclusiveWithinTimeout(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		time.Sleep(400 * time.Millisecond)
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"state": "pending"}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:                 server.URL,
+				TimeoutSeconds:      1,
+				PendingCondition:    `result.state == "pending"`,
+				RetryOnInconclusive: &v1alpha1.WebMetricRetryOnInconclusive{Count: 5, Delay: "100ms"},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	// the retries stop once the timeout of the metric has elapsed, before they are exhausted
+	start := time.Now()
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseInconclusive, measurement.Phase, measurement.Message)
+	assert.Less(t, requests.Load(), int32(6))
+	assert.NotEqual(t, "5", measurement.Metadata[InconclusiveRetriesMetadataKey])
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
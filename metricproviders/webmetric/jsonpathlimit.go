@@ -0,0 +1,29 @@
+package webmetric
+
+// exceedsJSONNodes returns whether the data has more than max nodes, counting every object, array and value. The
+// count stops at the first node over max, so it is bounded even over a huge response
+func exceedsJSONNodes(data any, max int64) bool {
+	remaining := max
+	var walk func(node any) bool
+	walk = func(node any) bool {
+		if remaining--; remaining < 0 {
+			return true
+		}
+		switch node := node.(type) {
+		case map[string]any:
+			for _, child := range node {
+				if walk(child) {
+					return true
+				}
+			}
+		case []any:
+			for _, child := range node {
+				if walk(child) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return walk(data)
+}
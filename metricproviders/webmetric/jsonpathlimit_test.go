@@ -0,0 +1,97 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestJSONPathMaxNodes(t *testing.T) {
+	// a large response, for a recursive descent with a filter to walk through every node of
+	items := make([]map[string]any, 100000)
+	for i := range items {
+		items[i] = map[string]any{"name": "item", "labels": map[string]any{"tier": "web"}, "value": i}
+	}
+	body, err := json.Marshal(map[string]any{"items": items})
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write(body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		jsonPath        string
+		maxNodes        int64
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMessage string
+	}{
+		{
+			name:          "response within the limit",
+			jsonPath:      "{$.items[0].value}",
+			maxNodes:      1000000,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:            "response beyond the limit",
+			jsonPath:        `{$..[?(@.tier == "db")].value}`,
+			maxNodes:        1000,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "the response has more than the jsonPathMaxNodes of 1000 nodes to evaluate the JSONPath over",
+		},
+		{
+			name:          "light path without a limit",
+			jsonPath:      "{$.items[0].value}",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == 0",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:              server.URL,
+						JSONPath:         test.jsonPath,
+						JSONPathMaxNodes: test.maxNodes,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Contains(t, measurement.Message, test.expectedMessage)
+		})
+	}
+}
+
+func TestExceedsJSONNodes(t *testing.T) {
+	var data any
+	assert.NoError(t, json.Unmarshal([]byte(`{"a": [1, 2, {"b": null}], "c": "d"}`), &data))
+	// the object, the array, its 3 items, the null of b and the string of c
+	assert.False(t, exceedsJSONNodes(data, 7))
+	assert.True(t, exceedsJSONNodes(data, 6))
+	assert.False(t, exceedsJSONNodes(1, 1))
+	assert.True(t, exceedsJSONNodes(1, 0))
+
+	// the count stops at the limit, whatever the size of the data
+	items := make([]any, 10000000)
+	start := time.Now()
+	assert.True(t, exceedsJSONNodes(items, 1000))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
@@ -0,0 +1,42 @@
+package webmetric
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resolveJSONPointer returns the value the RFC 6901 JSON Pointer refers to in a parsed JSON document
+func resolveJSONPointer(pointer string, data any) (any, error) {
+	if pointer == "" {
+		return data, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with /", pointer)
+	}
+
+	value := data
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]any:
+			child, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("JSON pointer %q: key %q not found", pointer, token)
+			}
+			value = child
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+				return nil, fmt.Errorf("JSON pointer %q: invalid array index %q", pointer, token)
+			}
+			if index >= len(v) {
+				return nil, fmt.Errorf("JSON pointer %q: array index %d out of range", pointer, index)
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("JSON pointer %q: cannot resolve %q in a %T", pointer, token, value)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,99 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestResolveJSONPointer(t *testing.T) {
+	var data any
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"data": [{"value": 42}, {"value": 7, "tags": ["a"]}],
+		"a/b": {"m~n": true},
+		"": "empty key"
+	}`), &data))
+
+	tests := []struct {
+		pointer              string
+		expectedValue        any
+		expectedErrorMessage string
+	}{
+		{pointer: "/data/0/value", expectedValue: float64(42)},
+		{pointer: "/data/1/tags/0", expectedValue: "a"},
+		{pointer: "/data/1", expectedValue: map[string]any{"value": float64(7), "tags": []any{"a"}}},
+		{pointer: "/a~1b/m~0n", expectedValue: true},
+		{pointer: "/", expectedValue: "empty key"},
+		{pointer: "data/0", expectedErrorMessage: `invalid JSON pointer "data/0": must be empty or start with /`},
+		{pointer: "/missing", expectedErrorMessage: `JSON pointer "/missing": key "missing" not found`},
+		{pointer: "/data/2", expectedErrorMessage: `JSON pointer "/data/2": array index 2 out of range`},
+		{pointer: "/data/-", expectedErrorMessage: `JSON pointer "/data/-": invalid array index "-"`},
+		{pointer: "/data/01", expectedErrorMessage: `JSON pointer "/data/01": invalid array index "01"`},
+		{pointer: "/data/0/value/x", expectedErrorMessage: `JSON pointer "/data/0/value/x": cannot resolve "x" in a float64`},
+	}
+
+	for _, test := range tests {
+		value, err := resolveJSONPointer(test.pointer, data)
+		if test.expectedErrorMessage != "" {
+			assert.EqualError(t, err, test.expectedErrorMessage)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedValue, value, test.pointer)
+	}
+}
+
+func TestRunWithJSONPointer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"data": [{"value": 42}, {"value": 7}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		pointer       string
+		expectedPhase v1alpha1.AnalysisPhase
+		expectedValue string
+	}{
+		{pointer: "/data/0/value", expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedValue: "42"},
+		{pointer: "/data/0/value", expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedValue: "42"},
+		{pointer: "/data/1/value", expectedPhase: v1alpha1.AnalysisPhaseFailed, expectedValue: "7"},
+		{pointer: "/data/2/value", expectedPhase: v1alpha1.AnalysisPhaseError},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result == 42",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:         server.URL,
+					JSONPointer: test.pointer,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+		assert.Equal(t, test.expectedValue, measurement.Value)
+	}
+
+	// JSONPath and JSONPointer are mutually exclusive
+	_, err := NewWebMetricJsonParser(v1alpha1.Metric{Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{
+		JSONPath:    "{$.data}",
+		JSONPointer: "/data",
+	}}})
+	assert.EqualError(t, err, "use either JSONPath or JSONPointer; both cannot exists for WebMetric")
+}
@@ -0,0 +1,25 @@
+package webmetric
+
+import (
+	"net/http"
+	"sync"
+)
+
+var (
+	noKeepAliveTransportsMu sync.Mutex
+	// noKeepAliveTransports are shared so the transports wrapping them, such as the DNS caching transports, are too
+	noKeepAliveTransports = map[*http.Transport]*http.Transport{}
+)
+
+// noKeepAliveTransport returns a transport like base, opening a new connection for every request
+func noKeepAliveTransport(base *http.Transport) *http.Transport {
+	noKeepAliveTransportsMu.Lock()
+	defer noKeepAliveTransportsMu.Unlock()
+	if t, ok := noKeepAliveTransports[base]; ok {
+		return t
+	}
+	t := base.Clone()
+	t.DisableKeepAlives = true
+	noKeepAliveTransports[base] = t
+	return t
+}
@@ -0,0 +1,52 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestDisableKeepAlives(t *testing.T) {
+	for _, disableKeepAlives := range []bool{false, true} {
+		t.Run(map[bool]string{false: "keep-alives", true: "no keep-alives"}[disableKeepAlives], func(t *testing.T) {
+			var closed []bool
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				closed = append(closed, req.Close)
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, `{"ok": true}`)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:               server.URL,
+						JSONPath:          "{$.ok}",
+						DisableKeepAlives: disableKeepAlives,
+					},
+				},
+			}
+			for i := 0; i < 2; i++ {
+				jsonparser, err := NewWebMetricJsonParser(metric)
+				assert.NoError(t, err)
+				client, err := NewWebMetricHttpClient(metric)
+				assert.NoError(t, err)
+				assert.Equal(t, disableKeepAlives, client.Transport.(*http.Transport).DisableKeepAlives)
+				provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+				measurement := provider.Run(newAnalysisRun(), metric)
+				assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+			}
+			// the requests ask the server to close the connection
+			assert.Equal(t, []bool{disableKeepAlives, disableKeepAlives}, closed)
+		})
+	}
+}
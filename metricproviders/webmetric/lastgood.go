@@ -0,0 +1,32 @@
+package webmetric
+
+import (
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+)
+
+// useLastGoodValue turns a measurement errored by a connection error into an Inconclusive measurement with the value
+// of the last Successful measurement of the metric in the run. The other measurements are returned as is
+func useLastGoodValue(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	if measurement.Phase != v1alpha1.AnalysisPhaseError || measurement.Metadata[ErrorCauseMetadataKey] != ErrorCauseConnection {
+		return measurement
+	}
+	measurements := analysisutil.ArrayMeasurement(run, metric.Name)
+	for i := len(measurements) - 1; i >= 0; i-- {
+		lastGood := measurements[i]
+		if lastGood.Phase != v1alpha1.AnalysisPhaseSuccessful {
+			continue
+		}
+		measurement.Phase = v1alpha1.AnalysisPhaseInconclusive
+		measurement.Value = lastGood.Value
+		measurement.Message = fmt.Sprintf("reused the value of the last successful measurement: %s", measurement.Message)
+		if raw, ok := lastGood.Metadata[RawValueMetadataKey]; ok {
+			// the previous value of the next measurement is the raw value, as of the last successful one
+			measurement.Metadata[RawValueMetadataKey] = raw
+		}
+		return measurement
+	}
+	return measurement
+}
@@ -0,0 +1,98 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestUseLastGoodOnError(t *testing.T) {
+	// each response of the server is the next one of the responses: a value, a dropped connection or a 500
+	var responses []string
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch response := responses[requests.Add(1)-1]; response {
+		case "drop":
+			conn, _, _ := rw.(http.Hijacker).Hijack()
+			conn.Close()
+		case "500":
+			rw.WriteHeader(http.StatusInternalServerError)
+		default:
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, response)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		responses      []string
+		expectedPhases []v1alpha1.AnalysisPhase
+		expectedValues []string
+	}{
+		{
+			name:           "connection error after a successful measurement",
+			responses:      []string{`{"value": 1}`, "drop", `{"value": 2}`},
+			expectedPhases: []v1alpha1.AnalysisPhase{v1alpha1.AnalysisPhaseSuccessful, v1alpha1.AnalysisPhaseInconclusive, v1alpha1.AnalysisPhaseSuccessful},
+			expectedValues: []string{"1", "1", "2"},
+		},
+		{
+			name:           "connection error without a successful measurement",
+			responses:      []string{"drop"},
+			expectedPhases: []v1alpha1.AnalysisPhase{v1alpha1.AnalysisPhaseError},
+			expectedValues: []string{""},
+		},
+		{
+			name:           "error response after a successful measurement",
+			responses:      []string{`{"value": 1}`, "500"},
+			expectedPhases: []v1alpha1.AnalysisPhase{v1alpha1.AnalysisPhaseSuccessful, v1alpha1.AnalysisPhaseError},
+			expectedValues: []string{"1", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			responses = test.responses
+			requests.Store(0)
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result > 0",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:                server.URL,
+						JSONPath:           "{$.value}",
+						UseLastGoodOnError: true,
+						// a dropped kept-alive connection would be retried by the transport with the next response
+						DisableKeepAlives: true,
+					},
+				},
+			}
+			run := newAnalysisRun()
+			run.Status.MetricResults = []v1alpha1.MetricResult{{Name: "foo"}}
+
+			for i, expectedPhase := range test.expectedPhases {
+				jsonparser, err := NewWebMetricJsonParser(metric)
+				assert.NoError(t, err)
+				client, err := NewWebMetricHttpClient(metric)
+				assert.NoError(t, err)
+				provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+				measurement := provider.Run(run, metric)
+				assert.Equal(t, expectedPhase, measurement.Phase, measurement.Message)
+				assert.Equal(t, test.expectedValues[i], measurement.Value)
+				if expectedPhase == v1alpha1.AnalysisPhaseInconclusive {
+					assert.Contains(t, measurement.Message, "reused the value of the last successful measurement: ")
+					assert.Equal(t, ErrorCauseConnection, measurement.Metadata[ErrorCauseMetadataKey])
+				}
+				run.Status.MetricResults[0].Measurements = append(run.Status.MetricResults[0].Measurements, measurement)
+			}
+		})
+	}
+}
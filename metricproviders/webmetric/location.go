@@ -0,0 +1,41 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/evaluate"
+)
+
+// parseLocationResponse evaluates the value held by the Location header of the redirect response
+func (p *Provider) parseLocationResponse(metric v1alpha1.Metric, response *webResponse, previous any) (string, v1alpha1.AnalysisPhase, error) {
+	location := response.header.Get("Location")
+	if response.statusCode < 300 || response.statusCode >= 400 || location == "" {
+		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("expected a redirect with a Location header, received response code: %v", response.statusCode)
+	}
+
+	valString := location
+	if queryParam := metric.Provider.Web.Location.QueryParam; queryParam != "" {
+		locationURL, err := url.Parse(location)
+		if err != nil {
+			return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("invalid Location header: %v", err)
+		}
+		query := locationURL.Query()
+		if !query.Has(queryParam) {
+			return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("Location header has no %s query parameter", queryParam)
+		}
+		valString = query.Get(queryParam)
+	}
+	val := textValue(valString)
+
+	vars := map[string]any{
+		"statusCode":     response.statusCode,
+		"responseTimeMs": response.duration.Milliseconds(),
+		"responseBytes":  len(response.body),
+		"location":       location,
+		"previous":       previous,
+	}
+	status, err := evaluate.EvaluateResultWithVars(val, vars, metric, p.logCtx)
+	return valString, status, asEvaluationError(err)
+}
@@ -0,0 +1,107 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		location         string
+		queryParam       string
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedMessage  string
+	}{
+		{
+			name:             "value in a query parameter",
+			status:           http.StatusFound,
+			location:         "/results?id=42&successRate=0.98",
+			queryParam:       "successRate",
+			successCondition: "result > 0.95",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "0.98",
+		},
+		{
+			name:             "failing value in a query parameter",
+			status:           http.StatusSeeOther,
+			location:         "https://results.my-server.com/view?successRate=0.5",
+			queryParam:       "successRate",
+			successCondition: "result > 0.95",
+			expectedPhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedValue:    "0.5",
+		},
+		{
+			name:             "whole location",
+			status:           http.StatusFound,
+			location:         "/results/passed",
+			successCondition: `result == "/results/passed" && statusCode == 302`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "/results/passed",
+		},
+		{
+			name:            "missing query parameter",
+			status:          http.StatusFound,
+			location:        "/results?id=42",
+			queryParam:      "successRate",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "Location header has no successRate query parameter",
+		},
+		{
+			name:            "no redirect",
+			status:          http.StatusOK,
+			queryParam:      "successRate",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "expected a redirect with a Location header, received response code: 200",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			followed := false
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.URL.Path != "/" {
+					followed = true
+				}
+				if test.location != "" {
+					rw.Header().Set("Location", test.location)
+				}
+				rw.WriteHeader(test.status)
+				io.WriteString(rw, "")
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						Location: &v1alpha1.WebMetricLocation{QueryParam: test.queryParam},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.False(t, followed, "the redirect must not be followed")
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/evaluate"
+)
+
+// evaluateEach evaluates the conditions against each of the values, which the measurement passes when all or any of
+// them pass, per the match mode
+func evaluateEach(mode v1alpha1.WebMetricMatchMode, val any, vars map[string]any, metric v1alpha1.Metric, logCtx log.Entry) (v1alpha1.AnalysisPhase, error) {
+	values, ok := val.([]any)
+	if !ok {
+		return "", fmt.Errorf("matchMode %s requires several values to evaluate, got: %v", mode, val)
+	}
+	if len(values) == 0 {
+		return "", errors.New("result of web metric produced no value")
+	}
+	phases := map[v1alpha1.AnalysisPhase]bool{}
+	for _, value := range values {
+		phase, err := evaluate.EvaluateResultWithVars(value, vars, metric, logCtx)
+		if err != nil {
+			return "", fmt.Errorf("could not evaluate %v: %w", value, err)
+		}
+		phases[phase] = true
+	}
+	switch mode {
+	case v1alpha1.WebMetricMatchModeAll:
+		if phases[v1alpha1.AnalysisPhaseFailed] {
+			return v1alpha1.AnalysisPhaseFailed, nil
+		}
+		if phases[v1alpha1.AnalysisPhaseInconclusive] {
+			return v1alpha1.AnalysisPhaseInconclusive, nil
+		}
+		return v1alpha1.AnalysisPhaseSuccessful, nil
+	case v1alpha1.WebMetricMatchModeAny:
+		if phases[v1alpha1.AnalysisPhaseSuccessful] {
+			return v1alpha1.AnalysisPhaseSuccessful, nil
+		}
+		if phases[v1alpha1.AnalysisPhaseInconclusive] {
+			return v1alpha1.AnalysisPhaseInconclusive, nil
+		}
+		return v1alpha1.AnalysisPhaseFailed, nil
+	}
+	return "", fmt.Errorf("unknown matchMode %q: it must be all or any", mode)
+}
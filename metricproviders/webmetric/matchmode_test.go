@@ -0,0 +1,99 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestMatchMode(t *testing.T) {
+	tests := []struct {
+		name            string
+		matchMode       v1alpha1.WebMetricMatchMode
+		response        string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "all pass in all mode",
+			matchMode:     v1alpha1.WebMetricMatchModeAll,
+			response:      `{"services": [{"status": "OK"}, {"status": "OK"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: `["OK","OK"]`,
+		},
+		{
+			name:          "one fails in all mode",
+			matchMode:     v1alpha1.WebMetricMatchModeAll,
+			response:      `{"services": [{"status": "OK"}, {"status": "DEGRADED"}, {"status": "OK"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: `["OK","DEGRADED","OK"]`,
+		},
+		{
+			name:          "one passes in any mode",
+			matchMode:     v1alpha1.WebMetricMatchModeAny,
+			response:      `{"services": [{"status": "DOWN"}, {"status": "OK"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: `["DOWN","OK"]`,
+		},
+		{
+			name:          "none passes in any mode",
+			matchMode:     v1alpha1.WebMetricMatchModeAny,
+			response:      `{"services": [{"status": "DOWN"}, {"status": "DEGRADED"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: `["DOWN","DEGRADED"]`,
+		},
+		{
+			name:          "a single match",
+			matchMode:     v1alpha1.WebMetricMatchModeAll,
+			response:      `{"services": [{"status": "OK"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: `["OK"]`,
+		},
+		{
+			name:            "no match",
+			matchMode:       v1alpha1.WebMetricMatchModeAll,
+			response:        `{"services": []}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "result of web metric produced no value",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: `result == "OK"`,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:       server.URL,
+						JSONPath:  "{$.services[*].status}",
+						MatchMode: test.matchMode,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
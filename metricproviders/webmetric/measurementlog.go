@@ -0,0 +1,73 @@
+package webmetric
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// Fields of the measurement logs
+const (
+	MeasurementLogHostField       = "host"
+	MeasurementLogStatusCodeField = "statusCode"
+	MeasurementLogLatencyField    = "latencyMs"
+	MeasurementLogAttemptField    = "attempt"
+	MeasurementLogOutcomeField    = "outcome"
+)
+
+var measurementLogLevels = map[v1alpha1.WebMetricMeasurementLogLevel]log.Level{
+	v1alpha1.WebMetricMeasurementLogLevelDebug: log.DebugLevel,
+	v1alpha1.WebMetricMeasurementLogLevelInfo:  log.InfoLevel,
+	v1alpha1.WebMetricMeasurementLogLevelWarn:  log.WarnLevel,
+}
+
+// requestAttempts are the requests sent for a measurement, for its measurement log
+type requestAttempts struct {
+	count int
+	// host, statusCode and latency are those of the last request
+	host       string
+	statusCode int
+	latency    time.Duration
+}
+
+// record records a request sent for the measurement
+func (a *requestAttempts) record(request *http.Request, start time.Time, response *webResponse, err error) {
+	a.count++
+	a.host = request.URL.Host
+	a.latency = time.Since(start)
+	a.statusCode = 0
+	if response != nil {
+		a.statusCode = response.statusCode
+	}
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		a.statusCode = statusErr.statusCode
+	}
+}
+
+// logMeasurement logs the measurement at the MeasurementLogLevel of the metric, if any
+func (p *Provider) logMeasurement(metric v1alpha1.Metric, measurement v1alpha1.Measurement) {
+	if metric.Provider.Web.MeasurementLogLevel == "" {
+		return
+	}
+	level, ok := measurementLogLevels[metric.Provider.Web.MeasurementLogLevel]
+	if !ok {
+		p.logCtx.Warnf("Invalid measurementLogLevel %q: it must be debug, info or warn", metric.Provider.Web.MeasurementLogLevel)
+		return
+	}
+	fields := log.Fields{
+		MeasurementLogHostField:       p.attempts.host,
+		MeasurementLogStatusCodeField: p.attempts.statusCode,
+		MeasurementLogLatencyField:    p.attempts.latency.Milliseconds(),
+		MeasurementLogAttemptField:    p.attempts.count,
+		MeasurementLogOutcomeField:    string(measurement.Phase),
+	}
+	if cause := measurement.Metadata[ErrorCauseMetadataKey]; cause != "" {
+		fields[ErrorCauseMetadataKey] = cause
+	}
+	p.logCtx.WithFields(fields).Logf(level, "Web metric measurement %s", measurement.Phase)
+}
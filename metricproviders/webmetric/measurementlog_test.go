@@ -0,0 +1,133 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestMeasurementLog(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"value": 0.99}`)
+	}))
+	defer fallback.Close()
+	fallbackURL, err := url.Parse(fallback.URL)
+	assert.NoError(t, err)
+	primaryURL, err := url.Parse(primary.URL)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		level          v1alpha1.WebMetricMeasurementLogLevel
+		fallbackURLs   []string
+		expectedLevel  log.Level
+		expectedFields log.Fields
+	}{
+		{
+			name:          "successful after a fallback",
+			level:         v1alpha1.WebMetricMeasurementLogLevelInfo,
+			fallbackURLs:  []string{fallback.URL},
+			expectedLevel: log.InfoLevel,
+			expectedFields: log.Fields{
+				"test":                        "test",
+				MeasurementLogHostField:       fallbackURL.Host,
+				MeasurementLogStatusCodeField: http.StatusOK,
+				MeasurementLogAttemptField:    2,
+				MeasurementLogOutcomeField:    "Successful",
+			},
+		},
+		{
+			name:          "error",
+			level:         v1alpha1.WebMetricMeasurementLogLevelWarn,
+			expectedLevel: log.WarnLevel,
+			expectedFields: log.Fields{
+				"test":                        "test",
+				MeasurementLogHostField:       primaryURL.Host,
+				MeasurementLogStatusCodeField: http.StatusServiceUnavailable,
+				MeasurementLogAttemptField:    1,
+				MeasurementLogOutcomeField:    "Error",
+				ErrorCauseMetadataKey:         ErrorCauseStatusCode,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger, hook := newTestLogger()
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result > 0.9",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:                 primary.URL,
+						FallbackUrls:        test.fallbackURLs,
+						JSONPath:            "{$.value}",
+						MeasurementLogLevel: test.level,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*logger.WithField("test", "test"), client, jsonparser)
+
+			provider.Run(newAnalysisRun(), metric)
+			entry := hook.LastEntry()
+			if !assert.NotNil(t, entry) {
+				return
+			}
+			assert.Equal(t, test.expectedLevel, entry.Level)
+			latency, ok := entry.Data[MeasurementLogLatencyField].(int64)
+			assert.True(t, ok)
+			assert.GreaterOrEqual(t, latency, int64(0))
+			delete(entry.Data, MeasurementLogLatencyField)
+			assert.Equal(t, test.expectedFields, entry.Data)
+		})
+	}
+}
+
+func TestMeasurementLogDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"value": 0.99}`)
+	}))
+	defer server.Close()
+
+	logger, hook := newTestLogger()
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result > 0.9",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{URL: server.URL, JSONPath: "{$.value}"},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*logger.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Empty(t, hook.AllEntries())
+}
+
+func newTestLogger() (*log.Logger, *test.Hook) {
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(log.DebugLevel)
+	return logger, hook
+}
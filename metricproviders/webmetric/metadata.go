@@ -0,0 +1,72 @@
+package webmetric
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasttemplate"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const (
+	labelsPlaceholderPrefix      = "analysisRun.labels."
+	annotationsPlaceholderPrefix = "analysisRun.annotations."
+)
+
+// resolveRunMetadata returns a copy of the metric with the $(analysisRun.labels.<key>) and
+// $(analysisRun.annotations.<key>) placeholders of its URLs substituted with the query escaped metadata of the run
+func resolveRunMetadata(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) (v1alpha1.Metric, error) {
+	return resolveURLs(metric, func(template string) (string, error) {
+		return resolveMetadataPlaceholders(template, run, url.QueryEscape)
+	})
+}
+
+// resolveBodyRunMetadata substitutes the metadata placeholders of the body with the metadata of the run, escaped for
+// JSON strings
+func resolveBodyRunMetadata(run *v1alpha1.AnalysisRun, body []byte) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	resolved, err := resolveMetadataPlaceholders(string(body), run, func(value string) string {
+		quoted := strconv.Quote(value)
+		return quoted[1 : len(quoted)-1]
+	})
+	return []byte(resolved), err
+}
+
+// resolveMetadataPlaceholders substitutes the metadata placeholders of the template with the escaped labels and
+// annotations of the run. Other placeholders, such as the pagination cursor, are kept as is
+func resolveMetadataPlaceholders(template string, run *v1alpha1.AnalysisRun, escape func(string) string) (string, error) {
+	if !strings.Contains(template, placeholderOpenBracket+"analysisRun.") {
+		return template, nil
+	}
+	t, err := fasttemplate.NewTemplate(template, placeholderOpenBracket, placeholderCloseBracket)
+	if err != nil {
+		return "", err
+	}
+	var unresolvedErr error
+	resolved := t.ExecuteFuncString(func(w io.Writer, tag string) (int, error) {
+		name := strings.TrimSpace(tag)
+		var values map[string]string
+		var key string
+		switch {
+		case strings.HasPrefix(name, labelsPlaceholderPrefix):
+			values, key = run.Labels, strings.TrimPrefix(name, labelsPlaceholderPrefix)
+		case strings.HasPrefix(name, annotationsPlaceholderPrefix):
+			values, key = run.Annotations, strings.TrimPrefix(name, annotationsPlaceholderPrefix)
+		default:
+			return w.Write([]byte(placeholderOpenBracket + tag + placeholderCloseBracket))
+		}
+		value, ok := values[key]
+		if !ok {
+			unresolvedErr = fmt.Errorf("failed to resolve %s%s%s: the AnalysisRun has no such label or annotation", placeholderOpenBracket, tag, placeholderCloseBracket)
+			return 0, nil
+		}
+		return w.Write([]byte(escape(value)))
+	})
+	return resolved, unresolvedErr
+}
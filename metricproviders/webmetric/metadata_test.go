@@ -0,0 +1,111 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunMetadataPlaceholders(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		jsonBody        string
+		expectedQuery   string
+		expectedBody    string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMessage string
+	}{
+		{
+			name:          "label in the URL",
+			path:          "/?team=$(analysisRun.labels.team)&app=$( analysisRun.labels.app.kubernetes.io/name )",
+			expectedQuery: "team=payments&app=checkout",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "annotation in the URL is query escaped",
+			path:          "/?query=$(analysisRun.annotations.query)",
+			expectedQuery: "query=errors+%26+timeouts",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "label and annotation in the body",
+			path:          "/",
+			jsonBody:      `{"team": "$(analysisRun.labels.team)", "query": "$(analysisRun.annotations.description)", "other": "$(other)"}`,
+			expectedBody:  `{"team": "payments", "query": "the \"checkout\" service", "other": "$(other)"}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:            "missing label",
+			path:            "/?team=$(analysisRun.labels.owner)",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "failed to resolve $(analysisRun.labels.owner): the AnalysisRun has no such label or annotation",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var query, body string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				query = req.URL.RawQuery
+				bodyBytes, _ := io.ReadAll(req.Body)
+				body = string(bodyBytes)
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, `{"ok": true}`)
+			}))
+			defer server.Close()
+
+			web := &v1alpha1.WebMetric{
+				URL:      server.URL + test.path,
+				JSONPath: "{$.ok}",
+			}
+			if test.jsonBody != "" {
+				web.Method = v1alpha1.WebMetricMethodPost
+				web.JSONBody = json.RawMessage(test.jsonBody)
+			}
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider:         v1alpha1.MetricProvider{Web: web},
+			}
+			run := &v1alpha1.AnalysisRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "run",
+					Namespace: "ns",
+					Labels: map[string]string{
+						"team":                   "payments",
+						"app.kubernetes.io/name": "checkout",
+					},
+					Annotations: map[string]string{
+						"query":       "errors & timeouts",
+						"description": `the "checkout" service`,
+					},
+				},
+			}
+
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(run, metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			if test.expectedPhase == v1alpha1.AnalysisPhaseSuccessful {
+				assert.Equal(t, test.expectedQuery, query)
+				assert.Equal(t, test.expectedBody, body)
+			}
+			// the metric of the analysis is not modified
+			assert.Equal(t, server.URL+test.path, metric.Provider.Web.URL)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package webmetric
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// requestMethod returns the method of the web metric, GET by default. The method can be templated from the analysis
+// args, so it is only validated once they are resolved
+func requestMethod(web *v1alpha1.WebMetric) (v1alpha1.WebMetricMethod, error) {
+	if web.Method == "" {
+		return v1alpha1.WebMetricMethodGet, nil
+	}
+	method := v1alpha1.WebMetricMethod(strings.ToUpper(strings.TrimSpace(string(web.Method))))
+	switch method {
+	case v1alpha1.WebMetricMethodGet, v1alpha1.WebMetricMethodPost, v1alpha1.WebMetricMethodPut:
+		return method, nil
+	}
+	return "", fmt.Errorf("invalid WebMetric method %q: it must be GET, POST or PUT", web.Method)
+}
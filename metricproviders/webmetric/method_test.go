@@ -0,0 +1,84 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+)
+
+func TestTemplatedMethod(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMethod  string
+		expectedMessage string
+	}{
+		{
+			name:           "get",
+			method:         "GET",
+			expectedPhase:  v1alpha1.AnalysisPhaseSuccessful,
+			expectedMethod: http.MethodGet,
+		},
+		{
+			name:           "lower case post",
+			method:         "post",
+			expectedPhase:  v1alpha1.AnalysisPhaseSuccessful,
+			expectedMethod: http.MethodPost,
+		},
+		{
+			name:            "invalid method",
+			method:          "DELETE",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: `invalid WebMetric method "DELETE": it must be GET, POST or PUT`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var method string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				method = req.Method
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, `{"ok": true}`)
+			}))
+			defer server.Close()
+
+			template := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						Method:   "{{ args.method }}",
+						URL:      server.URL,
+						JSONPath: "{$.ok}",
+					},
+				},
+			}
+			// the args are resolved by the controller when the analysis run is created
+			metric, err := analysisutil.ResolveMetricArgs(template, []v1alpha1.Argument{
+				{Name: "method", Value: pointer.StringPtr(test.method)},
+			})
+			assert.NoError(t, err)
+
+			jsonparser, err := NewWebMetricJsonParser(*metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(*metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), *metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.Equal(t, test.expectedMethod, method)
+		})
+	}
+}
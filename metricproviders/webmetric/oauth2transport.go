@@ -0,0 +1,50 @@
+package webmetric
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// requestTokenTransport authenticates the requests with an OAuth2 token like oauth2.Transport, but fetches the token
+// with the context of the request which needs it. The token request then shares the deadline and the cancellation of
+// the measurement, instead of taking a timeout of its own once the measurement is over. The token is reused until it
+// expires
+type requestTokenTransport struct {
+	base  http.RoundTripper
+	fetch func(ctx context.Context) (*oauth2.Token, error)
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func (t *requestTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokenFor(req.Context())
+	if err != nil {
+		// a RoundTripper must close the body of the request, even on errors
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	authorized := req.Clone(req.Context())
+	token.SetAuthHeader(authorized)
+	return t.base.RoundTrip(authorized)
+}
+
+// tokenFor returns the current token, or fetches a new one with the context when it is missing or expired
+func (t *requestTokenTransport) tokenFor(ctx context.Context) (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token.Valid() {
+		return t.token, nil
+	}
+	token, err := t.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.token = token
+	return token, nil
+}
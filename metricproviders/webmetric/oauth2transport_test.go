@@ -0,0 +1,122 @@
+package webmetric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// slowTokenServer is a token endpoint which does not answer until the token request is cancelled or the server closed
+func slowTokenServer() (*httptest.Server, func()) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-release:
+		}
+	}))
+	return server, func() {
+		close(release)
+		server.Close()
+	}
+}
+
+func oauth2Metric(url, tokenURL string, timeoutSeconds int64) v1alpha1.Metric {
+	return v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result == 1",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            url,
+				JSONPath:       "{$.value}",
+				TimeoutSeconds: timeoutSeconds,
+				Authentication: v1alpha1.Authentication{
+					OAuth2: v1alpha1.OAuth2Config{
+						TokenURL:     tokenURL,
+						ClientID:     "myClientID",
+						ClientSecret: "mySecret",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestOAuth2TokenRequestDeadline(t *testing.T) {
+	tokenServer, closeTokenServer := slowTokenServer()
+	defer closeTokenServer()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Error("the metric must not be queried without a token")
+	}))
+	defer server.Close()
+
+	t.Run("deadline of the request", func(t *testing.T) {
+		// the timeout of the client, which the token request used to get on its own, is far beyond the deadline
+		client, err := NewWebMetricHttpClient(oauth2Metric(server.URL, tokenServer.URL, 60))
+		assert.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+
+		start := time.Now()
+		_, err = client.Do(request)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("timeout of the measurement", func(t *testing.T) {
+		metric := oauth2Metric(server.URL, tokenServer.URL, 1)
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		start := time.Now()
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+		assert.Contains(t, measurement.Message, "context deadline exceeded")
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestOAuth2TokenReused(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		tokenRequests.Add(1)
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"token_type": "Bearer", "expires_in": 3599, "access_token": "my-token"}`)
+	}))
+	defer tokenServer.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer my-token", req.Header.Get("Authorization"))
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"value": 1}`)
+	}))
+	defer server.Close()
+
+	client, err := NewWebMetricHttpClient(oauth2Metric(server.URL, tokenServer.URL, 10))
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		response, err := client.Do(request)
+		assert.NoError(t, err)
+		response.Body.Close()
+		// the request of the caller is left as is
+		assert.Empty(t, request.Header.Get("Authorization"))
+	}
+	assert.Equal(t, int32(1), tokenRequests.Load())
+}
@@ -0,0 +1,159 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const (
+	defaultMaxPages = 10
+	// cursorPlaceholder is substituted with the cursor of the page in the request body
+	cursorPlaceholder = "pagination.cursor"
+)
+
+// fetchPages fetches all the pages of a paginated response. The returned response holds the items of all the pages
+// in a JSON array, along with the status and headers of the last page
+func (p *Provider) fetchPages(metric v1alpha1.Metric, body []byte) (*webResponse, error) {
+	pagination := metric.Provider.Web.Pagination
+	if pagination.CursorParam == "" && body == nil {
+		return nil, errors.New("pagination without a cursorParam requires a Body or JSONBody with a $(pagination.cursor) placeholder")
+	}
+	cursorParser, err := newPaginationJSONPath("cursor", pagination.CursorPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagination cursorPath: %v", err)
+	}
+	itemsPath := pagination.ItemsPath
+	if itemsPath == "" {
+		itemsPath = "{$}"
+	}
+	itemsParser, err := newPaginationJSONPath("items", itemsPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagination itemsPath: %v", err)
+	}
+	maxPages := pagination.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	items := []any{}
+	var last *webResponse
+	var duration time.Duration
+	var totalBytes int64
+	cursor := ""
+	for page := int64(1); ; page++ {
+		if page > maxPages {
+			return nil, fmt.Errorf("pagination exceeded the maximum of %d pages", maxPages)
+		}
+		pageURL, pageBody, err := pageRequest(metric.Provider.Web, body, cursor, page == 1)
+		if err != nil {
+			return nil, err
+		}
+		pageMetric := metric
+		if page > 1 {
+			// the pages share the endpoint of the first one, so only the first page is requested conditionally
+			pageMetric = withoutConditionalRequests(metric)
+		}
+		last, err = p.fetch(pageMetric, pageURL, pageBody)
+		if err != nil {
+			return nil, err
+		}
+		duration += last.duration
+		totalBytes += int64(len(last.body))
+		if pagination.MaxTotalBytes > 0 && totalBytes > pagination.MaxTotalBytes {
+			return nil, fmt.Errorf("pagination exceeded the maximum of %d bytes read across the pages at page %d", pagination.MaxTotalBytes, page)
+		}
+
+		var data any
+		if err := json.Unmarshal(last.body, &data); err != nil {
+			return nil, fmt.Errorf("page %d is not a JSON document: %v", page, err)
+		}
+		results, err := itemsParser.FindResults(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not find pagination itemsPath in page %d: %v", page, err)
+		}
+		for _, r := range results {
+			for _, item := range r {
+				items = append(items, item.Interface())
+			}
+		}
+		cursor, err = nextCursor(cursorParser, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not find pagination cursorPath in page %d: %v", page, err)
+		}
+		if cursor == "" {
+			break
+		}
+	}
+
+	bodyBytes, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return &webResponse{
+		statusCode: last.statusCode,
+		header:     last.header,
+		body:       bodyBytes,
+		duration:   duration,
+	}, nil
+}
+
+func newPaginationJSONPath(name, path string) (*jsonpath.JSONPath, error) {
+	parser := jsonpath.New(name)
+	// a page without a cursor or items is not an error
+	parser.AllowMissingKeys(true)
+	return parser, parser.Parse(path)
+}
+
+// pageRequest returns the URL and body of the request for the page with the given cursor
+func pageRequest(web *v1alpha1.WebMetric, body []byte, cursor string, first bool) (string, []byte, error) {
+	if web.Pagination.CursorParam == "" {
+		pageBody, err := resolvePlaceholders(string(body), map[string]string{cursorPlaceholder: cursor})
+		if err != nil {
+			return "", nil, err
+		}
+		return web.URL, []byte(pageBody), nil
+	}
+	if first {
+		return web.URL, body, nil
+	}
+	pageURL, err := url.Parse(web.URL)
+	if err != nil {
+		return "", nil, err
+	}
+	query := pageURL.Query()
+	query.Set(web.Pagination.CursorParam, cursor)
+	pageURL.RawQuery = query.Encode()
+	return pageURL.String(), body, nil
+}
+
+// nextCursor returns the cursor of the next page, or an empty string if there is none
+func nextCursor(cursorParser *jsonpath.JSONPath, data any) (string, error) {
+	results, err := cursorParser.FindResults(data)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range results {
+		for _, v := range r {
+			switch cursor := v.Interface().(type) {
+			case nil:
+				return "", nil
+			case string:
+				return cursor, nil
+			case float64:
+				return strconv.FormatFloat(cursor, 'f', -1, 64), nil
+			default:
+				cursorBytes, err := json.Marshal(cursor)
+				return string(cursorBytes), err
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,204 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// pages are served for the cursors "", "page2" and "page3"
+var pages = map[string]string{
+	"":      `{"items": [{"errors": 1}, {"errors": 2}], "next": "page2"}`,
+	"page2": `{"items": [{"errors": 3}], "next": "page3"}`,
+	"page3": `{"items": [{"errors": 4}], "next": null}`,
+}
+
+func runPaginatedMetric(t *testing.T, metric v1alpha1.Metric) v1alpha1.Measurement {
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+	return provider.Run(newAnalysisRun(), metric)
+}
+
+func TestPaginationWithQueryCursor(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "bar", req.URL.Query().Get("foo"))
+		cursor := req.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, pages[cursor])
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		// the JSONPath of the metric applies to the items of all the pages
+		SuccessCondition: "result == 1",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL + "?foo=bar",
+				JSONPath: "{$[*].errors}",
+				Pagination: &v1alpha1.WebMetricPagination{
+					CursorPath:  "{$.next}",
+					CursorParam: "cursor",
+					ItemsPath:   "{$.items[*]}",
+				},
+			},
+		},
+	}
+	measurement := runPaginatedMetric(t, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, []string{"", "page2", "page3"}, cursors)
+
+	metric.Provider.Web.JSONPath = ""
+	metric.SuccessCondition = "len(result) == 4 && result[3].errors == 4"
+	cursors = nil
+	measurement = runPaginatedMetric(t, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, `[{"errors":1},{"errors":2},{"errors":3},{"errors":4}]`, measurement.Value)
+}
+
+func TestPaginationWithBodyCursor(t *testing.T) {
+	twoPages := map[string]string{
+		"":    `{"data": {"values": [1, 2]}, "nextCursor": "abc"}`,
+		"abc": `{"data": {"values": [3]}}`,
+	}
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, ContentTypeJsonValue, req.Header.Get(ContentTypeKey))
+		var body struct {
+			Query  string `json:"query"`
+			Cursor string `json:"cursor"`
+		}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, "errors", body.Query)
+		cursors = append(cursors, body.Cursor)
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, twoPages[body.Cursor])
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "len(result) == 3 && result[2] == 3",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				Method:   v1alpha1.WebMetricMethodPost,
+				URL:      server.URL,
+				JSONBody: json.RawMessage(`{"query": "errors", "cursor": "$(pagination.cursor)"}`),
+				Pagination: &v1alpha1.WebMetricPagination{
+					CursorPath: "{$.nextCursor}",
+					ItemsPath:  "{$.data.values[*]}",
+				},
+			},
+		},
+	}
+
+	measurement := runPaginatedMetric(t, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "[1,2,3]", measurement.Value)
+	assert.Equal(t, []string{"", "abc"}, cursors)
+}
+
+func TestPaginationErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Header().Set("Content-Type", "application/json")
+		if req.URL.Query().Get("text") != "" {
+			io.WriteString(rw, "not json")
+			return
+		}
+		// always returns a next page
+		fmt.Fprintf(rw, `{"items": [%d], "next": %d}`, requests, requests+1)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name                 string
+		web                  v1alpha1.WebMetric
+		expectedRequests     int
+		expectedErrorMessage string
+	}{
+		{
+			name: "page cap",
+			web: v1alpha1.WebMetric{
+				URL:        server.URL,
+				Pagination: &v1alpha1.WebMetricPagination{CursorPath: "{$.next}", CursorParam: "page", MaxPages: 2},
+			},
+			expectedRequests:     2,
+			expectedErrorMessage: "pagination exceeded the maximum of 2 pages",
+		},
+		{
+			name: "default page cap",
+			web: v1alpha1.WebMetric{
+				URL:        server.URL,
+				Pagination: &v1alpha1.WebMetricPagination{CursorPath: "{$.next}", CursorParam: "page"},
+			},
+			expectedRequests:     10,
+			expectedErrorMessage: "pagination exceeded the maximum of 10 pages",
+		},
+		{
+			name: "cumulative byte cap",
+			web: v1alpha1.WebMetric{
+				URL:        server.URL,
+				Pagination: &v1alpha1.WebMetricPagination{CursorPath: "{$.next}", CursorParam: "page", MaxPages: 100, MaxTotalBytes: 50},
+			},
+			// the pages of 23 bytes add up to 69 bytes with the third one
+			expectedRequests:     3,
+			expectedErrorMessage: "pagination exceeded the maximum of 50 bytes read across the pages at page 3",
+		},
+		{
+			name: "non JSON page",
+			web: v1alpha1.WebMetric{
+				URL:        server.URL + "?text=true",
+				Pagination: &v1alpha1.WebMetricPagination{CursorPath: "{$.next}", CursorParam: "page"},
+			},
+			expectedRequests:     1,
+			expectedErrorMessage: "page 1 is not a JSON document",
+		},
+		{
+			name: "body cursor without body",
+			web: v1alpha1.WebMetric{
+				URL:        server.URL,
+				Pagination: &v1alpha1.WebMetricPagination{CursorPath: "{$.next}"},
+			},
+			expectedErrorMessage: "pagination without a cursorParam requires a Body or JSONBody",
+		},
+		{
+			name: "invalid cursor path",
+			web: v1alpha1.WebMetric{
+				URL:        server.URL,
+				Pagination: &v1alpha1.WebMetricPagination{CursorPath: "{$.next", CursorParam: "page"},
+			},
+			expectedErrorMessage: "invalid pagination cursorPath",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = 0
+			web := test.web
+			metric := v1alpha1.Metric{
+				Name:     "foo",
+				Provider: v1alpha1.MetricProvider{Web: &web},
+			}
+			measurement := runPaginatedMetric(t, metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+			assert.Contains(t, measurement.Message, test.expectedErrorMessage)
+			assert.Equal(t, test.expectedRequests, requests)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package webmetric
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasttemplate"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// Placeholders substituted by the provider use the $(name) syntax since {{ }} templates in a metric are resolved
+// against the analysis arguments before the provider runs
+const (
+	placeholderOpenBracket  = "$("
+	placeholderCloseBracket = ")"
+)
+
+// resolvePlaceholders substitutes the $(name) placeholders of the template. Values are escaped so they can be
+// embedded in JSON strings
+func resolvePlaceholders(template string, values map[string]string) (string, error) {
+	t, err := fasttemplate.NewTemplate(template, placeholderOpenBracket, placeholderCloseBracket)
+	if err != nil {
+		return "", err
+	}
+	var unresolvedErr error
+	resolved := t.ExecuteFuncString(func(w io.Writer, tag string) (int, error) {
+		value, ok := values[strings.TrimSpace(tag)]
+		if !ok {
+			unresolvedErr = fmt.Errorf("failed to resolve %s%s%s", placeholderOpenBracket, tag, placeholderCloseBracket)
+			return 0, nil
+		}
+		quoted := strconv.Quote(value)
+		return w.Write([]byte(quoted[1 : len(quoted)-1]))
+	})
+	return resolved, unresolvedErr
+}
+
+// resolveURLs returns a copy of the metric with the placeholders of its URLs substituted by resolve
+func resolveURLs(metric v1alpha1.Metric, resolve func(string) (string, error)) (v1alpha1.Metric, error) {
+	web := *metric.Provider.Web
+	var err error
+	if web.URL, err = resolve(web.URL); err != nil {
+		return metric, err
+	}
+	if web.Preflight, err = resolve(web.Preflight); err != nil {
+		return metric, err
+	}
+	if web.ThresholdUrl, err = resolve(web.ThresholdUrl); err != nil {
+		return metric, err
+	}
+	if len(web.FallbackUrls) > 0 {
+		fallbackURLs := make([]string, len(web.FallbackUrls))
+		for i, fallbackURL := range web.FallbackUrls {
+			if fallbackURLs[i], err = resolve(fallbackURL); err != nil {
+				return metric, err
+			}
+		}
+		web.FallbackUrls = fallbackURLs
+	}
+	if web.Baseline != nil {
+		baseline := *web.Baseline
+		if baseline.URL, err = resolve(baseline.URL); err != nil {
+			return metric, err
+		}
+		web.Baseline = &baseline
+	}
+	if web.PreRequest != nil {
+		preRequest := *web.PreRequest
+		if preRequest.URL, err = resolve(preRequest.URL); err != nil {
+			return metric, err
+		}
+		web.PreRequest = &preRequest
+	}
+	metric.Provider.Web = &web
+	return metric, nil
+}
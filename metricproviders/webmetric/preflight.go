@@ -0,0 +1,51 @@
+package webmetric
+
+import (
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	timeutil "github.com/argoproj/argo-rollouts/utils/time"
+)
+
+// FailedStageMetadataKey is the measurement metadata key of the stage of a metric with a Preflight check which did not
+// succeed: FailedStagePreflight or FailedStageMeasurement
+const FailedStageMetadataKey = "failedStage"
+
+const (
+	FailedStagePreflight   = "preflight"
+	FailedStageMeasurement = "measurement"
+)
+
+// markPreflightFailed marks the measurement with the PreflightFailurePhase of the metric, Inconclusive by default
+func markPreflightFailed(web *v1alpha1.WebMetric, measurement v1alpha1.Measurement, err error) v1alpha1.Measurement {
+	if measurement.Metadata == nil {
+		measurement.Metadata = map[string]string{}
+	}
+	measurement.Metadata[FailedStageMetadataKey] = FailedStagePreflight
+	phase := web.PreflightFailurePhase
+	switch phase {
+	case "":
+		phase = v1alpha1.AnalysisPhaseInconclusive
+	case v1alpha1.AnalysisPhaseInconclusive, v1alpha1.AnalysisPhaseFailed, v1alpha1.AnalysisPhaseError:
+	default:
+		return markMeasurementError(measurement, fmt.Errorf("invalid preflightFailurePhase %s: it must be Inconclusive, Failed or Error", phase))
+	}
+	measurement.Phase = phase
+	measurement.Message = fmt.Sprintf("preflight check failed: %v", err)
+	finishedTime := timeutil.MetaNow()
+	measurement.FinishedAt = &finishedTime
+	return measurement
+}
+
+// withFailedStage records that a measurement which did not succeed past its Preflight check failed at the measurement
+// stage
+func withFailedStage(measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	if measurement.Phase == v1alpha1.AnalysisPhaseSuccessful || measurement.Metadata[FailedStageMetadataKey] != "" {
+		return measurement
+	}
+	if measurement.Metadata == nil {
+		measurement.Metadata = map[string]string{}
+	}
+	measurement.Metadata[FailedStageMetadataKey] = FailedStageMeasurement
+	return measurement
+}
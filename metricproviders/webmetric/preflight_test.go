@@ -0,0 +1,123 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestPreflightStages(t *testing.T) {
+	tests := []struct {
+		name            string
+		healthzStatus   int
+		metricsStatus   int
+		metricsBody     string
+		failurePhase    v1alpha1.AnalysisPhase
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedStage   string
+		expectedQueried bool
+		expectedMessage string
+	}{
+		{
+			name:            "both stages succeed",
+			healthzStatus:   http.StatusOK,
+			metricsStatus:   http.StatusOK,
+			metricsBody:     `{"successRate": 0.99}`,
+			failurePhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedPhase:   v1alpha1.AnalysisPhaseSuccessful,
+			expectedQueried: true,
+		},
+		{
+			name:            "liveness fails",
+			healthzStatus:   http.StatusServiceUnavailable,
+			failurePhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+			expectedStage:   FailedStagePreflight,
+			expectedMessage: "preflight check failed: received non 2xx response code: 503",
+		},
+		{
+			name:            "liveness fails with the default phase",
+			healthzStatus:   http.StatusServiceUnavailable,
+			expectedPhase:   v1alpha1.AnalysisPhaseInconclusive,
+			expectedStage:   FailedStagePreflight,
+			expectedMessage: "preflight check failed: received non 2xx response code: 503",
+		},
+		{
+			name:            "metric request fails",
+			healthzStatus:   http.StatusOK,
+			metricsStatus:   http.StatusInternalServerError,
+			failurePhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedStage:   FailedStageMeasurement,
+			expectedQueried: true,
+			expectedMessage: "received non 2xx response code: 500",
+		},
+		{
+			name:            "metric value fails",
+			healthzStatus:   http.StatusOK,
+			metricsStatus:   http.StatusOK,
+			metricsBody:     `{"successRate": 0.5}`,
+			failurePhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedPhase:   v1alpha1.AnalysisPhaseFailed,
+			expectedStage:   FailedStageMeasurement,
+			expectedQueried: true,
+		},
+		{
+			name:            "invalid failure phase",
+			healthzStatus:   http.StatusServiceUnavailable,
+			failurePhase:    v1alpha1.AnalysisPhaseRunning,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedStage:   FailedStagePreflight,
+			expectedMessage: "invalid preflightFailurePhase Running: it must be Inconclusive, Failed or Error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			queried := false
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case "/healthz":
+					rw.WriteHeader(test.healthzStatus)
+				case "/metrics":
+					queried = true
+					rw.Header().Set("Content-Type", "application/json")
+					rw.WriteHeader(test.metricsStatus)
+					io.WriteString(rw, test.metricsBody)
+				}
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result > 0.95",
+				FailureCondition: "result <= 0.95",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						Preflight:             server.URL + "/healthz",
+						PreflightFailurePhase: test.failurePhase,
+						URL:                   server.URL + "/metrics",
+						JSONPath:              "{$.successRate}",
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedStage, measurement.Metadata[FailedStageMetadataKey])
+			assert.Equal(t, test.expectedQueried, queried)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// withPreRequest sends the PreRequest of the metric, and returns a copy of the metric sending the value selected from
+// its response in the header of the PreRequest, along with the cookies set by the response when they are forwarded
+func (p *Provider) withPreRequest(metric v1alpha1.Metric) (v1alpha1.Metric, error) {
+	preRequest := metric.Provider.Web.PreRequest
+	if preRequest.Header == "" {
+		return metric, errors.New("preRequest requires a header")
+	}
+	sources := 0
+	for _, source := range []string{preRequest.FromHeader, preRequest.FromCookie, preRequest.FromJsonPath} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return metric, errors.New("preRequest requires exactly one of fromHeader, fromCookie and fromJsonPath")
+	}
+
+	response, err := p.fetch(withoutMeasurementRequest(metric), preRequest.URL, nil)
+	if err != nil {
+		return metric, fmt.Errorf("preRequest failed: %w", err)
+	}
+	cookies := (&http.Response{Header: response.header}).Cookies()
+	value, err := preRequestValue(metric.Provider.Web, response, cookies)
+	if err != nil {
+		return metric, err
+	}
+
+	web := *metric.Provider.Web
+	headers := append([]v1alpha1.WebMetricHeader{}, web.Headers...)
+	headers = append(headers, v1alpha1.WebMetricHeader{Key: preRequest.Header, Value: value})
+	if preRequest.ForwardCookies && len(cookies) > 0 {
+		headers = withCookies(headers, cookies)
+	}
+	web.Headers = headers
+	metric.Provider.Web = &web
+	return metric, nil
+}
+
+// preRequestValue returns the value of the PreRequest response, from its header, cookie or JSON body
+func preRequestValue(web *v1alpha1.WebMetric, response *webResponse, cookies []*http.Cookie) (string, error) {
+	preRequest := web.PreRequest
+	switch {
+	case preRequest.FromHeader != "":
+		value := response.header.Get(preRequest.FromHeader)
+		if value == "" {
+			return "", &parseError{err: fmt.Errorf("preRequest response has no %s header", preRequest.FromHeader)}
+		}
+		return value, nil
+	case preRequest.FromCookie != "":
+		for _, cookie := range cookies {
+			if cookie.Name == preRequest.FromCookie {
+				return cookie.Value, nil
+			}
+		}
+		return "", &parseError{err: fmt.Errorf("preRequest response has no %s cookie", preRequest.FromCookie)}
+	}
+
+	parser, err := newJSONParser(web, "preRequest", preRequest.FromJsonPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid preRequest fromJsonPath: %v", err)
+	}
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return "", &parseError{err: fmt.Errorf("preRequest response is not a JSON document: %v", err)}
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return "", &parseError{err: fmt.Errorf("Could not find preRequest fromJsonPath in body: %s", err)}
+	}
+	val, valString, err := getValue(fullResults)
+	if err != nil {
+		return "", &parseError{err: fmt.Errorf("preRequest: %v", err)}
+	}
+	if s, ok := val.(string); ok {
+		return s, nil
+	}
+	return valString, nil
+}
+
+// withCookies adds the cookies to the Cookie header of the headers, keeping the cookies already set
+func withCookies(headers []v1alpha1.WebMetricHeader, cookies []*http.Cookie) []v1alpha1.WebMetricHeader {
+	pairs := make([]string, len(cookies))
+	for i, cookie := range cookies {
+		pairs[i] = (&http.Cookie{Name: cookie.Name, Value: cookie.Value}).String()
+	}
+	for i, header := range headers {
+		if strings.EqualFold(header.Key, "Cookie") {
+			headers[i].Value = strings.Join(append([]string{header.Value}, pairs...), "; ")
+			return headers
+		}
+	}
+	return append(headers, v1alpha1.WebMetricHeader{Key: "Cookie", Value: strings.Join(pairs, "; ")})
+}
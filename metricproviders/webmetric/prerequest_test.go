@@ -0,0 +1,130 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunWithPreRequest(t *testing.T) {
+	const csrfToken = "3f2a9c"
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/csrf":
+			assert.Equal(t, http.MethodGet, req.Method)
+			assert.Equal(t, "value", req.Header.Get("key"))
+			http.SetCookie(rw, &http.Cookie{Name: "csrftoken", Value: csrfToken, Path: "/", HttpOnly: true})
+			rw.Header().Set("X-CSRF-Token", csrfToken)
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, `{"csrf": {"token": "`+csrfToken+`"}}`)
+		case "/nocsrf":
+			rw.WriteHeader(http.StatusNoContent)
+		case "/query":
+			// double submit: the token of the header must match the one of the cookie
+			cookie, err := req.Cookie("csrftoken")
+			if req.Header.Get("X-CSRFToken") != csrfToken || (err == nil && cookie.Value != csrfToken) {
+				rw.WriteHeader(http.StatusForbidden)
+				return
+			}
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, `{"ok": true, "session": "`+req.Header.Get("Cookie")+`"}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		headers         []v1alpha1.WebMetricHeader
+		preRequest      v1alpha1.WebMetricPreRequest
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMessage string
+		expectedCookie  string
+	}{
+		{
+			name:           "token from a cookie, forwarded",
+			preRequest:     v1alpha1.WebMetricPreRequest{URL: server.URL + "/csrf", Header: "X-CSRFToken", FromCookie: "csrftoken", ForwardCookies: true},
+			expectedPhase:  v1alpha1.AnalysisPhaseSuccessful,
+			expectedCookie: "csrftoken=" + csrfToken,
+		},
+		{
+			name:           "forwarded cookies are added to the cookies of the metric",
+			headers:        []v1alpha1.WebMetricHeader{{Key: "cookie", Value: "session=1"}},
+			preRequest:     v1alpha1.WebMetricPreRequest{URL: server.URL + "/csrf", Header: "X-CSRFToken", FromCookie: "csrftoken", ForwardCookies: true},
+			expectedPhase:  v1alpha1.AnalysisPhaseSuccessful,
+			expectedCookie: "session=1; csrftoken=" + csrfToken,
+		},
+		{
+			name:          "token from a header",
+			preRequest:    v1alpha1.WebMetricPreRequest{URL: server.URL + "/csrf", Header: "X-CSRFToken", FromHeader: "X-CSRF-Token"},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "token from the body",
+			preRequest:    v1alpha1.WebMetricPreRequest{URL: server.URL + "/csrf", Header: "X-CSRFToken", FromJsonPath: "{$.csrf.token}"},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:            "missing cookie",
+			preRequest:      v1alpha1.WebMetricPreRequest{URL: server.URL + "/nocsrf", Header: "X-CSRFToken", FromCookie: "csrftoken"},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "preRequest response has no csrftoken cookie",
+		},
+		{
+			name:            "failed pre-request",
+			preRequest:      v1alpha1.WebMetricPreRequest{URL: server.URL + "/query", Header: "X-CSRFToken", FromCookie: "csrftoken"},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "preRequest failed: received non 2xx response code: 403",
+		},
+		{
+			name:            "several sources",
+			preRequest:      v1alpha1.WebMetricPreRequest{URL: server.URL + "/csrf", Header: "X-CSRFToken", FromCookie: "csrftoken", FromHeader: "X-CSRF-Token"},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "preRequest requires exactly one of fromHeader, fromCookie and fromJsonPath",
+		},
+		{
+			name:            "missing header",
+			preRequest:      v1alpha1.WebMetricPreRequest{URL: server.URL + "/csrf", FromCookie: "csrftoken"},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "preRequest requires a header",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			preRequest := test.preRequest
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result.ok",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						Method:     v1alpha1.WebMetricMethodPost,
+						URL:        server.URL + "/query",
+						Headers:    append([]v1alpha1.WebMetricHeader{{Key: "key", Value: "value"}}, test.headers...),
+						JSONBody:   []byte(`{"query": "up"}`),
+						PreRequest: &preRequest,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			if test.expectedMessage != "" {
+				assert.Equal(t, test.expectedMessage, measurement.Message)
+			}
+			if test.expectedPhase == v1alpha1.AnalysisPhaseSuccessful {
+				assert.Contains(t, measurement.Value, `"session":"`+test.expectedCookie+`"`)
+			}
+		})
+	}
+}
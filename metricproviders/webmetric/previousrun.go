@@ -0,0 +1,54 @@
+package webmetric
+
+import (
+	"errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	listers "github.com/argoproj/argo-rollouts/pkg/client/listers/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+)
+
+// SetAnalysisRunLister sets the lister the provider looks the previous AnalysisRuns up with, for the PreviousRunValue
+// of the metrics
+func (p *Provider) SetAnalysisRunLister(lister listers.AnalysisRunLister) {
+	p.analysisRuns = lister
+}
+
+// previousRunValue returns the final value of the metric in the latest completed AnalysisRun created before the run
+// by the same controller, such as its Rollout, or nil if there is none
+func (p *Provider) previousRunValue(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) (any, error) {
+	if p.analysisRuns == nil {
+		return nil, errors.New("previousRunValue requires the AnalysisRun lister of the provider")
+	}
+	controllerRef := metav1.GetControllerOf(run)
+	if controllerRef == nil {
+		return nil, nil
+	}
+	runs, err := p.analysisRuns.AnalysisRuns(run.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *v1alpha1.AnalysisRun
+	for _, candidate := range runs {
+		if candidate.UID == run.UID || !candidate.Status.Phase.Completed() || !candidate.CreationTimestamp.Before(&run.CreationTimestamp) {
+			continue
+		}
+		if ref := metav1.GetControllerOf(candidate); ref == nil || ref.UID != controllerRef.UID {
+			continue
+		}
+		if analysisutil.GetResult(candidate, metric.Name) == nil {
+			continue
+		}
+		if previous == nil || previous.CreationTimestamp.Before(&candidate.CreationTimestamp) {
+			previous = candidate
+		}
+	}
+	if previous == nil {
+		return nil, nil
+	}
+	return previousValue(previous, metric), nil
+}
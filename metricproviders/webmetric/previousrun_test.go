@@ -0,0 +1,123 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	listers "github.com/argoproj/argo-rollouts/pkg/client/listers/rollouts/v1alpha1"
+)
+
+func newRolloutAnalysisRun(name, rolloutUID string, created time.Time, phase v1alpha1.AnalysisPhase, value string) *v1alpha1.AnalysisRun {
+	isController := true
+	run := &v1alpha1.AnalysisRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns",
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences: []metav1.OwnerReference{{
+				Kind:       "Rollout",
+				Name:       "guestbook",
+				UID:        types.UID(rolloutUID),
+				Controller: &isController,
+			}},
+		},
+		Status: v1alpha1.AnalysisRunStatus{Phase: phase},
+	}
+	if value != "" {
+		run.Status.MetricResults = []v1alpha1.MetricResult{{
+			Name:         "foo",
+			Phase:        phase,
+			Measurements: []v1alpha1.Measurement{{Phase: phase, Value: value}},
+		}}
+	}
+	return run
+}
+
+func TestPreviousRunValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"successRate": 0.9}`)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	run := newRolloutAnalysisRun("current", "rollout", now, v1alpha1.AnalysisPhaseRunning, "")
+	tests := []struct {
+		name          string
+		runs          []*v1alpha1.AnalysisRun
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		{
+			name:          "without a previous run",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name: "degraded since the previous run",
+			runs: []*v1alpha1.AnalysisRun{
+				newRolloutAnalysisRun("older", "rollout", now.Add(-2*time.Hour), v1alpha1.AnalysisPhaseSuccessful, "0.9"),
+				newRolloutAnalysisRun("previous", "rollout", now.Add(-time.Hour), v1alpha1.AnalysisPhaseSuccessful, "0.99"),
+			},
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			name: "stable since the previous run",
+			runs: []*v1alpha1.AnalysisRun{
+				newRolloutAnalysisRun("older", "rollout", now.Add(-2*time.Hour), v1alpha1.AnalysisPhaseSuccessful, "0.99"),
+				newRolloutAnalysisRun("previous", "rollout", now.Add(-time.Hour), v1alpha1.AnalysisPhaseSuccessful, "0.9"),
+			},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name: "runs of other rollouts, running or without the metric are ignored",
+			runs: []*v1alpha1.AnalysisRun{
+				newRolloutAnalysisRun("other-rollout", "other", now.Add(-time.Hour), v1alpha1.AnalysisPhaseSuccessful, "0.99"),
+				newRolloutAnalysisRun("running", "rollout", now.Add(-time.Hour), v1alpha1.AnalysisPhaseRunning, "0.99"),
+				newRolloutAnalysisRun("other-metric", "rollout", now.Add(-time.Hour), v1alpha1.AnalysisPhaseSuccessful, ""),
+			},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			assert.NoError(t, indexer.Add(run))
+			for _, r := range test.runs {
+				assert.NoError(t, indexer.Add(r))
+			}
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "previousRun == nil || result >= previousRun * 0.95",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:              server.URL,
+						JSONPath:         "{$.successRate}",
+						PreviousRunValue: true,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+			provider.SetAnalysisRunLister(listers.NewAnalysisRunLister(indexer))
+
+			measurement := provider.Run(run, metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, "0.9", measurement.Value)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasttemplate"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// previousValuePlaceholder is substituted with the value of the previous measurement of the metric in the request body
+const previousValuePlaceholder = "previousMeasurement.value"
+
+// resolveBodyPreviousValue substitutes the $(previousMeasurement.value) placeholders of the body with the value of the
+// previous measurement of the metric, escaped for JSON strings, or with an empty string for the first measurement.
+// Other placeholders, such as the pagination cursor, are kept as is
+func resolveBodyPreviousValue(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, body []byte) ([]byte, error) {
+	if !strings.Contains(string(body), placeholderOpenBracket+previousValuePlaceholder) {
+		return body, nil
+	}
+	t, err := fasttemplate.NewTemplate(string(body), placeholderOpenBracket, placeholderCloseBracket)
+	if err != nil {
+		return nil, err
+	}
+	value, err := formatPreviousValue(previousValue(run, metric))
+	if err != nil {
+		return nil, err
+	}
+	quoted := strconv.Quote(value)
+	resolved := t.ExecuteFuncString(func(w io.Writer, tag string) (int, error) {
+		if strings.TrimSpace(tag) != previousValuePlaceholder {
+			return w.Write([]byte(placeholderOpenBracket + tag + placeholderCloseBracket))
+		}
+		return w.Write([]byte(quoted[1 : len(quoted)-1]))
+	})
+	return []byte(resolved), nil
+}
+
+// formatPreviousValue returns strings as is, and the other values as JSON
+func formatPreviousValue(previous any) (string, error) {
+	switch v := previous.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	valBytes, err := json.Marshal(previous)
+	return string(valBytes), err
+}
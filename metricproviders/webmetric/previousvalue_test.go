@@ -0,0 +1,64 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestBodyWithPreviousValue(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var body struct {
+			After string `json:"after"`
+		}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		received = append(received, body.After)
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"cursor": "c%d"}`, len(received))
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: `result != ""`,
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				Method:   v1alpha1.WebMetricMethodPost,
+				URL:      server.URL,
+				Body:     `{"after": "$(previousMeasurement.value)"}`,
+				JSONPath: "{$.cursor}",
+			},
+		},
+	}
+	run := newAnalysisRun()
+	run.Status.MetricResults = []v1alpha1.MetricResult{{Name: "foo"}}
+	for i := 0; i < 3; i++ {
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(run, metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+		run.Status.MetricResults[0].Measurements = append(run.Status.MetricResults[0].Measurements, measurement)
+	}
+	// the first measurement has no previous value
+	assert.Equal(t, []string{"", "c1", "c2"}, received)
+}
+
+func TestFormatPreviousValue(t *testing.T) {
+	for previous, expected := range map[any]string{nil: "", "cursor": "cursor", 0.5: "0.5", true: "true"} {
+		value, err := formatPreviousValue(previous)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, value)
+	}
+}
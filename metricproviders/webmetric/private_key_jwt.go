@@ -0,0 +1,133 @@
+package webmetric
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const (
+	// ClientAssertionType is the assertion type used by the private_key_jwt client authentication method
+	ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// clientAssertionLifetime is how long a signed client assertion remains valid
+	clientAssertionLifetime = 5 * time.Minute
+)
+
+// privateKeyJWTTokenSource fetches client credentials tokens, authenticating the token request with a freshly
+// signed client assertion every time a new token is needed
+type privateKeyJWTTokenSource struct {
+	ctx    context.Context
+	config clientcredentials.Config
+	method jwt.SigningMethod
+	key    any
+	keyID  string
+	claims map[string]string
+}
+
+func newPrivateKeyJWTTokenSource(ctx context.Context, oauth2Cfg v1alpha1.OAuth2Config) (*privateKeyJWTTokenSource, error) {
+	cfg := oauth2Cfg.PrivateKeyJwt
+	if cfg.PrivateKey == "" {
+		return nil, errors.New("missing private key in metric for OAuth2 private_key_jwt setup")
+	}
+	key, method, err := parseSigningKey([]byte(cfg.PrivateKey), cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &privateKeyJWTTokenSource{
+		ctx: ctx,
+		config: clientcredentials.Config{
+			ClientID:  oauth2Cfg.ClientID,
+			TokenURL:  oauth2Cfg.TokenURL,
+			Scopes:    oauth2Scopes(&oauth2Cfg),
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		method: method,
+		key:    key,
+		keyID:  cfg.KeyId,
+		claims: cfg.Claims,
+	}, nil
+}
+
+// parseSigningKey parses a PEM encoded RSA or ECDSA private key and resolves the signing method to use with it
+func parseSigningKey(pemKey []byte, algorithm string) (any, jwt.SigningMethod, error) {
+	var key any
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey); err == nil {
+		key = rsaKey
+		if algorithm == "" {
+			algorithm = jwt.SigningMethodRS256.Alg()
+		}
+	} else if ecKey, err := jwt.ParseECPrivateKeyFromPEM(pemKey); err == nil {
+		key = ecKey
+		if algorithm == "" {
+			algorithm = jwt.SigningMethodES256.Alg()
+		}
+	} else {
+		return nil, nil, errors.New("private key for OAuth2 private_key_jwt must be a PEM encoded RSA or ECDSA key")
+	}
+
+	method := jwt.GetSigningMethod(algorithm)
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if _, ok := key.(*rsa.PrivateKey); ok {
+			return key, method, nil
+		}
+	case *jwt.SigningMethodECDSA:
+		if _, ok := key.(*ecdsa.PrivateKey); ok {
+			return key, method, nil
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported OAuth2 private_key_jwt algorithm: %s", algorithm)
+	}
+	return nil, nil, fmt.Errorf("OAuth2 private_key_jwt algorithm %s does not match the private key type", algorithm)
+}
+
+// assertion builds and signs the client assertion sent with the token request
+func (s *privateKeyJWTTokenSource) assertion() (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": s.config.ClientID,
+		"sub": s.config.ClientID,
+		"aud": s.config.TokenURL,
+		"jti": uuid.New().String(),
+		"iat": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+	}
+	for k, v := range s.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(s.method, claims)
+	if s.keyID != "" {
+		token.Header["kid"] = s.keyID
+	}
+	return token.SignedString(s.key)
+}
+
+// Token implements oauth2.TokenSource
+func (s *privateKeyJWTTokenSource) Token() (*oauth2.Token, error) {
+	return s.tokenWithContext(s.ctx)
+}
+
+// tokenWithContext fetches a token with the context of the token request
+func (s *privateKeyJWTTokenSource) tokenWithContext(ctx context.Context) (*oauth2.Token, error) {
+	assertion, err := s.assertion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign OAuth2 client assertion: %v", err)
+	}
+	cfg := s.config
+	cfg.EndpointParams = url.Values{
+		"client_assertion_type": {ClientAssertionType},
+		"client_assertion":      {assertion},
+	}
+	return cfg.Token(ctx)
+}
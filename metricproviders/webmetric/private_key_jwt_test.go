@@ -0,0 +1,155 @@
+package webmetric
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func rsaPrivateKeyPEM(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, string(keyPEM)
+}
+
+func TestPrivateKeyJWT(t *testing.T) {
+	key, keyPEM := rsaPrivateKeyPEM(t)
+
+	var tokenURL string
+	var assertionClaims jwt.MapClaims
+	var assertionHeader map[string]any
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.NoError(t, req.ParseForm())
+		assert.Equal(t, "client_credentials", req.PostForm.Get("grant_type"))
+		assert.Equal(t, "myClientID", req.PostForm.Get("client_id"))
+		assert.Empty(t, req.PostForm.Get("client_secret"))
+		assert.Equal(t, ClientAssertionType, req.PostForm.Get("client_assertion_type"))
+
+		token, err := jwt.Parse(req.PostForm.Get("client_assertion"), func(token *jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		})
+		if !assert.NoError(t, err) {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assertionClaims = token.Claims.(jwt.MapClaims)
+		assertionHeader = token.Header
+		mockOAuthOKResponse(rw, req, AccessToken)
+	}))
+	defer tokenServer.Close()
+	tokenURL = tokenServer.URL + "/token"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+AccessToken {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL: server.URL,
+				Authentication: v1alpha1.Authentication{
+					OAuth2: v1alpha1.OAuth2Config{
+						TokenURL: tokenURL,
+						ClientID: "myClientID",
+						Scopes:   []string{"myScope"},
+						PrivateKeyJwt: &v1alpha1.PrivateKeyJWTConfig{
+							PrivateKey: keyPEM,
+							KeyId:      "my-key",
+							Claims:     map[string]string{"tenant": "my-tenant"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+
+	assert.Equal(t, "RS256", assertionHeader["alg"])
+	assert.Equal(t, "my-key", assertionHeader["kid"])
+	assert.Equal(t, "myClientID", assertionClaims["iss"])
+	assert.Equal(t, "myClientID", assertionClaims["sub"])
+	assert.Equal(t, tokenURL, assertionClaims["aud"])
+	assert.Equal(t, "my-tenant", assertionClaims["tenant"])
+	assert.NotEmpty(t, assertionClaims["jti"])
+	assert.NotEmpty(t, assertionClaims["exp"])
+}
+
+func TestPrivateKeyJWTInvalidConfig(t *testing.T) {
+	_, rsaPEM := rsaPrivateKeyPEM(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	ecBytes, err := x509.MarshalECPrivateKey(ecKey)
+	assert.NoError(t, err)
+	ecPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecBytes}))
+
+	newMetric := func(clientID string, cfg v1alpha1.PrivateKeyJWTConfig) v1alpha1.Metric {
+		return v1alpha1.Metric{
+			Name: "foo",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					Authentication: v1alpha1.Authentication{
+						OAuth2: v1alpha1.OAuth2Config{
+							TokenURL:      "http://tokenurl",
+							ClientID:      clientID,
+							PrivateKeyJwt: &cfg,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// ECDSA keys default to ES256
+	_, err = NewWebMetricHttpClient(newMetric("myClientID", v1alpha1.PrivateKeyJWTConfig{PrivateKey: ecPEM}))
+	assert.NoError(t, err)
+
+	// Missing client ID should fail
+	_, err = NewWebMetricHttpClient(newMetric("", v1alpha1.PrivateKeyJWTConfig{PrivateKey: rsaPEM}))
+	assert.EqualError(t, err, "missing mandatory parameter in metric for OAuth2 setup")
+
+	// Missing private key should fail
+	_, err = NewWebMetricHttpClient(newMetric("myClientID", v1alpha1.PrivateKeyJWTConfig{}))
+	assert.EqualError(t, err, "missing private key in metric for OAuth2 private_key_jwt setup")
+
+	// Invalid private key should fail
+	_, err = NewWebMetricHttpClient(newMetric("myClientID", v1alpha1.PrivateKeyJWTConfig{PrivateKey: "not a key"}))
+	assert.EqualError(t, err, "private key for OAuth2 private_key_jwt must be a PEM encoded RSA or ECDSA key")
+
+	// Algorithm must match the key type
+	_, err = NewWebMetricHttpClient(newMetric("myClientID", v1alpha1.PrivateKeyJWTConfig{PrivateKey: ecPEM, Algorithm: "RS256"}))
+	assert.EqualError(t, err, "OAuth2 private_key_jwt algorithm RS256 does not match the private key type")
+
+	// Unknown algorithm should fail
+	_, err = NewWebMetricHttpClient(newMetric("myClientID", v1alpha1.PrivateKeyJWTConfig{PrivateKey: rsaPEM, Algorithm: "HS256"}))
+	assert.EqualError(t, err, "unsupported OAuth2 private_key_jwt algorithm: HS256")
+}
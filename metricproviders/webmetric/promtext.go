@@ -0,0 +1,121 @@
+package webmetric
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// PromTextAcceptValue requests the Prometheus text exposition format, unless the metric sets another Accept header
+const PromTextAcceptValue = "text/plain; version=0.0.4"
+
+// promTextSample is a sample of the Prometheus text exposition format
+type promTextSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// promTextValue returns the value of the single sample of the exposition body matching the metric name and labels
+func promTextValue(promText *v1alpha1.WebMetricPromText, body []byte) (float64, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("could not parse Prometheus text exposition: %v", err)
+	}
+
+	var matches []promTextSample
+	for _, sample := range promTextSamples(families, promText.Metric) {
+		if labelsMatch(sample.labels, promText.Labels) {
+			matches = append(matches, sample)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no sample of metric %s matches the labels %v", promText.Metric, promText.Labels)
+	case 1:
+		return matches[0].value, nil
+	default:
+		return 0, fmt.Errorf("%d samples of metric %s match the labels %v", len(matches), promText.Metric, promText.Labels)
+	}
+}
+
+// promTextSamples returns the samples of a metric. The _sum, _count and _bucket samples of summaries and histograms
+// are grouped under the family of their base name by the parser
+func promTextSamples(families map[string]*dto.MetricFamily, name string) []promTextSample {
+	var samples []promTextSample
+	if family, ok := families[name]; ok {
+		for _, m := range family.GetMetric() {
+			labels := metricLabels(m)
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				samples = append(samples, promTextSample{labels, m.GetCounter().GetValue()})
+			case dto.MetricType_GAUGE:
+				samples = append(samples, promTextSample{labels, m.GetGauge().GetValue()})
+			case dto.MetricType_UNTYPED:
+				samples = append(samples, promTextSample{labels, m.GetUntyped().GetValue()})
+			case dto.MetricType_SUMMARY:
+				for _, q := range m.GetSummary().GetQuantile() {
+					samples = append(samples, promTextSample{withLabel(labels, "quantile", q.GetQuantile()), q.GetValue()})
+				}
+			}
+		}
+	}
+
+	for _, suffix := range []string{"_sum", "_count", "_bucket"} {
+		family, ok := families[strings.TrimSuffix(name, suffix)]
+		if !strings.HasSuffix(name, suffix) || !ok {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := metricLabels(m)
+			switch {
+			case family.GetType() == dto.MetricType_SUMMARY && suffix == "_sum":
+				samples = append(samples, promTextSample{labels, m.GetSummary().GetSampleSum()})
+			case family.GetType() == dto.MetricType_SUMMARY && suffix == "_count":
+				samples = append(samples, promTextSample{labels, float64(m.GetSummary().GetSampleCount())})
+			case family.GetType() == dto.MetricType_HISTOGRAM && suffix == "_sum":
+				samples = append(samples, promTextSample{labels, m.GetHistogram().GetSampleSum()})
+			case family.GetType() == dto.MetricType_HISTOGRAM && suffix == "_count":
+				samples = append(samples, promTextSample{labels, float64(m.GetHistogram().GetSampleCount())})
+			case family.GetType() == dto.MetricType_HISTOGRAM && suffix == "_bucket":
+				for _, b := range m.GetHistogram().GetBucket() {
+					samples = append(samples, promTextSample{withLabel(labels, "le", b.GetUpperBound()), float64(b.GetCumulativeCount())})
+				}
+			}
+		}
+	}
+	return samples
+}
+
+func metricLabels(m *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, label := range m.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	return labels
+}
+
+func withLabel(labels map[string]string, name string, value float64) map[string]string {
+	copied := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		copied[k] = v
+	}
+	copied[name] = strconv.FormatFloat(value, 'g', -1, 64)
+	return copied
+}
+
+// labelsMatch returns whether the labels include all the expected labels
+func labelsMatch(labels, expected map[string]string) bool {
+	for name, value := range expected {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
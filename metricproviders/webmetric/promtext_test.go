@@ -0,0 +1,119 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const exposition = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+http_requests_total{method="post",code="500"} 3
+http_requests_total{method="get",code="200"} 2000
+# HELP queue_depth The depth of the queue.
+# TYPE queue_depth gauge
+queue_depth 12.5
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds{quantile="0.99"} 0.3
+rpc_duration_seconds_sum 17.5
+rpc_duration_seconds_count 250
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 80
+request_duration_seconds_bucket{le="0.5"} 95
+request_duration_seconds_bucket{le="+Inf"} 100
+request_duration_seconds_sum 21.2
+request_duration_seconds_count 100
+`
+
+func TestPromTextValue(t *testing.T) {
+	tests := []struct {
+		metric               string
+		labels               map[string]string
+		expectedValue        float64
+		expectedErrorMessage string
+	}{
+		{metric: "http_requests_total", labels: map[string]string{"method": "post", "code": "500"}, expectedValue: 3},
+		{metric: "queue_depth", expectedValue: 12.5},
+		{metric: "rpc_duration_seconds", labels: map[string]string{"quantile": "0.99"}, expectedValue: 0.3},
+		{metric: "rpc_duration_seconds_count", expectedValue: 250},
+		{metric: "request_duration_seconds_bucket", labels: map[string]string{"le": "0.5"}, expectedValue: 95},
+		{metric: "request_duration_seconds_sum", expectedValue: 21.2},
+		{
+			metric:               "http_requests_total",
+			labels:               map[string]string{"method": "put"},
+			expectedErrorMessage: "no sample of metric http_requests_total matches the labels map[method:put]",
+		},
+		{
+			metric:               "unknown_metric",
+			expectedErrorMessage: "no sample of metric unknown_metric matches the labels map[]",
+		},
+		{
+			metric:               "http_requests_total",
+			labels:               map[string]string{"code": "200"},
+			expectedErrorMessage: "2 samples of metric http_requests_total match the labels map[code:200]",
+		},
+	}
+
+	for _, test := range tests {
+		value, err := promTextValue(&v1alpha1.WebMetricPromText{Metric: test.metric, Labels: test.labels}, []byte(exposition))
+		if test.expectedErrorMessage != "" {
+			assert.EqualError(t, err, test.expectedErrorMessage)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedValue, value, test.metric)
+	}
+
+	_, err := promTextValue(&v1alpha1.WebMetricPromText{Metric: "queue_depth"}, []byte(`{"not": "exposition"}`))
+	assert.ErrorContains(t, err, "could not parse Prometheus text exposition")
+}
+
+func TestRunWithPromText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, PromTextAcceptValue, req.Header.Get("Accept"))
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		io.WriteString(rw, exposition)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		labels        map[string]string
+		expectedPhase v1alpha1.AnalysisPhase
+		expectedValue string
+	}{
+		{labels: map[string]string{"method": "post", "code": "500"}, expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedValue: "3"},
+		{labels: map[string]string{"method": "get", "code": "200"}, expectedPhase: v1alpha1.AnalysisPhaseFailed, expectedValue: "2000"},
+		{labels: map[string]string{"method": "get", "code": "500"}, expectedPhase: v1alpha1.AnalysisPhaseError},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result < 10",
+			FailureCondition: "result >= 10",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:      server.URL + "/metrics",
+					PromText: &v1alpha1.WebMetricPromText{Metric: "http_requests_total", Labels: test.labels},
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+		assert.Equal(t, test.expectedValue, measurement.Value)
+	}
+}
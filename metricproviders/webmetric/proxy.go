@@ -0,0 +1,64 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+type proxyTransportKey struct {
+	base     *http.Transport
+	proxyURL string
+}
+
+var (
+	proxyTransportsMu sync.Mutex
+	// proxyTransports are shared so the connections to the proxies are kept alive between measurements
+	proxyTransports = map[proxyTransportKey]*http.Transport{}
+)
+
+// parseProxyURL validates the proxyUrl of a web metric
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxyUrl: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxyUrl %q: it must be an http or https URL", redactURL(proxyURL))
+	}
+	return u, nil
+}
+
+// proxyTransport returns a transport like base, sending the requests through the proxy. The requests to HTTPS URLs are
+// tunneled with CONNECT, and the user and password of the proxy URL are sent in the Proxy-Authorization header
+func proxyTransport(base *http.Transport, proxyURL string) (*http.Transport, error) {
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	key := proxyTransportKey{base: base, proxyURL: proxyURL}
+
+	proxyTransportsMu.Lock()
+	defer proxyTransportsMu.Unlock()
+	if t, ok := proxyTransports[key]; ok {
+		return t, nil
+	}
+	t := base.Clone()
+	t.Proxy = http.ProxyURL(u)
+	proxyTransports[key] = t
+	return t, nil
+}
+
+// proxyPassword returns the password of the proxyURL, if any
+func proxyPassword(proxyURL string) string {
+	if proxyURL == "" {
+		return ""
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	password, _ := u.User.Password()
+	return password
+}
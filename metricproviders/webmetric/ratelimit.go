@@ -0,0 +1,59 @@
+package webmetric
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+var (
+	rateLimitersMu sync.Mutex
+	// rateLimiters limit the requests to each host. They are shared by all providers, so the limit applies to the
+	// requests of all the analysis runs
+	rateLimiters = map[string]*rate.Limiter{}
+)
+
+// hostRateLimiter returns the limiter of the host, lowered to the rate limit of the metric. The rate and burst are
+// never raised, so the most restrictive rate limit of the metrics querying the host applies to all of them
+func hostRateLimiter(host string, rateLimit *v1alpha1.WebMetricRateLimit) *rate.Limiter {
+	limit := rate.Limit(rateLimit.RequestsPerSecond)
+	burst := int(rateLimit.Burst)
+	if burst <= 0 {
+		burst = int(rateLimit.RequestsPerSecond)
+	}
+
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+	limiter, ok := rateLimiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(limit, burst)
+		rateLimiters[host] = limiter
+	}
+	if limit < limiter.Limit() {
+		limiter.SetLimit(limit)
+	}
+	if burst < limiter.Burst() {
+		limiter.SetBurst(burst)
+	}
+	return limiter
+}
+
+// waitRateLimit waits for the rate limit of the host of the request. The returned request has a deadline of the
+// client timeout, including the wait, so rate limited requests do not exceed the timeout of the metric
+func (p *Provider) waitRateLimit(metric v1alpha1.Metric, request *http.Request) (*http.Request, context.CancelFunc, error) {
+	rateLimit := metric.Provider.Web.RateLimit
+	if rateLimit == nil {
+		return request, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(request.Context(), p.client.Timeout)
+	if err := hostRateLimiter(request.URL.Host, rateLimit).Wait(ctx); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("rate limit of %s: %v", request.URL.Host, err)
+	}
+	return request.WithContext(ctx), cancel, nil
+}
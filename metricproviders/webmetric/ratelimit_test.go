@@ -0,0 +1,104 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func newRateLimitedProvider(t *testing.T, url string, timeoutSeconds int64, rateLimit *v1alpha1.WebMetricRateLimit) (*Provider, v1alpha1.Metric) {
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            url,
+				TimeoutSeconds: timeoutSeconds,
+				RateLimit:      rateLimit,
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	return NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser), metric
+}
+
+func newOKServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+}
+
+func TestRunWithRateLimit(t *testing.T) {
+	server := newOKServer()
+	defer server.Close()
+	otherServer := newOKServer()
+	defer otherServer.Close()
+
+	rateLimit := &v1alpha1.WebMetricRateLimit{RequestsPerSecond: 10, Burst: 1}
+	start := time.Now()
+	// the limiter is shared by the metrics querying the same host
+	for _, path := range []string{"/a", "/b", "/a"} {
+		provider, metric := newRateLimitedProvider(t, server.URL+path, 0, rateLimit)
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+	// the second and third requests wait for a token
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// other hosts are not limited by it
+	start = time.Now()
+	provider, metric := newRateLimitedProvider(t, otherServer.URL, 0, rateLimit)
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Less(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestRunWithRateLimitExceedingTimeout(t *testing.T) {
+	server := newOKServer()
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	rateLimit := &v1alpha1.WebMetricRateLimit{RequestsPerSecond: 1}
+	provider, metric := newRateLimitedProvider(t, server.URL, 1, rateLimit)
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+
+	// the next token is only available in 2 seconds, after the timeout of the metric
+	hostRateLimiter(serverURL.Host, rateLimit).Reserve()
+	start := time.Now()
+	measurement = provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Contains(t, measurement.Message, "rate limit of "+serverURL.Host)
+	// the request fails without waiting for the token
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestHostRateLimiter(t *testing.T) {
+	limiter := hostRateLimiter("limiter.test", &v1alpha1.WebMetricRateLimit{RequestsPerSecond: 5})
+	assert.Equal(t, 5, limiter.Burst())
+
+	// the limiter of the host is lowered to a more restrictive rate limit
+	updated := hostRateLimiter("limiter.test", &v1alpha1.WebMetricRateLimit{RequestsPerSecond: 2, Burst: 4})
+	assert.Same(t, limiter, updated)
+	assert.Equal(t, 4, updated.Burst())
+	assert.EqualValues(t, 2, updated.Limit())
+
+	// but never raised, whichever metric queries the host last
+	updated = hostRateLimiter("limiter.test", &v1alpha1.WebMetricRateLimit{RequestsPerSecond: 10, Burst: 3})
+	assert.Same(t, limiter, updated)
+	assert.Equal(t, 3, updated.Burst())
+	assert.EqualValues(t, 2, updated.Limit())
+}
@@ -0,0 +1,80 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// rateSample is the first sample of a rate of change
+type rateSample struct {
+	value float64
+	at    time.Time
+}
+
+// takeFirstSample fetches the first sample of the rate of change of the metric, and waits for the delay before the
+// measurement
+func (p *Provider) takeFirstSample(metric v1alpha1.Metric, body []byte) (*rateSample, error) {
+	delay, err := metric.Provider.Web.RateOfChange.Delay.Duration()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rateOfChange delay: %v", err)
+	}
+	if delay <= 0 {
+		return nil, fmt.Errorf("rateOfChange delay must be positive, got: %s", metric.Provider.Web.RateOfChange.Delay)
+	}
+	// the delay must leave time for the measurement request
+	if p.client.Timeout > 0 && delay >= p.client.Timeout {
+		return nil, fmt.Errorf("rateOfChange delay %s must be shorter than the timeout of %s", delay, p.client.Timeout)
+	}
+
+	response, err := p.fetchResponse(metric, body)
+	if err != nil {
+		return nil, fmt.Errorf("first sample request failed: %w", err)
+	}
+	at := time.Now()
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return nil, &parseError{err: fmt.Errorf("first sample is not a JSON document: %v", err)}
+	}
+	if metric.Provider.Web.JSONStringPath != "" {
+		if data, err = decodeJSONString(metric.Provider.Web, data); err != nil {
+			return nil, &parseError{err: err}
+		}
+	}
+	val, _, err := p.selectValue(metric.Provider.Web, data, response)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("first sample: %v", err)}
+	}
+	value, ok := toFloat(val)
+	if !ok {
+		return nil, &parseError{err: fmt.Errorf("rateOfChange requires a numeric value, got: %v", val)}
+	}
+
+	time.Sleep(delay)
+	return &rateSample{value: value, at: at}, nil
+}
+
+// addRateOfChange adds the delta and the rate of change per second from the first sample to the value to the vars
+func addRateOfChange(vars map[string]any, first *rateSample, val any, at time.Time) error {
+	value, ok := toFloat(val)
+	if !ok {
+		return fmt.Errorf("rateOfChange requires a numeric value, got: %v", val)
+	}
+	delta := value - first.value
+	vars["delta"] = delta
+	vars["rate"] = delta / at.Sub(first.at).Seconds()
+	return nil
+}
+
+func toFloat(val any) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
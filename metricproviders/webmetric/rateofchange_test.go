@@ -0,0 +1,141 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRateOfChange(t *testing.T) {
+	tests := []struct {
+		name             string
+		samples          []int
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+	}{
+		{
+			name:             "rate computation",
+			samples:          []int{100, 150},
+			successCondition: "delta == 50 && rate > 0 && rate <= 50 / 0.05",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "150",
+		},
+		{
+			name:             "zero delta",
+			samples:          []int{100, 100},
+			successCondition: "delta == 0 && rate == 0",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "100",
+		},
+		{
+			name:             "increase beyond the threshold",
+			samples:          []int{100, 5000},
+			successCondition: "delta <= 1000",
+			expectedPhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedValue:    "5000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requestTimes []time.Time
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				requestTimes = append(requestTimes, time.Now())
+				rw.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(rw, `{"requests": %d}`, test.samples[len(requestTimes)-1])
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:          server.URL,
+						JSONPath:     "{$.requests}",
+						RateOfChange: &v1alpha1.WebMetricRateOfChange{Delay: "50ms"},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			if assert.Len(t, requestTimes, 2) {
+				assert.GreaterOrEqual(t, requestTimes[1].Sub(requestTimes[0]), 50*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestRateOfChangeInvalid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"requests": 100, "status": "ok"}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		jsonPath        string
+		delay           v1alpha1.DurationString
+		expectedMessage string
+	}{
+		{
+			name:            "delay beyond the timeout",
+			jsonPath:        "{$.requests}",
+			delay:           "10s",
+			expectedMessage: "rateOfChange delay 10s must be shorter than the timeout of 10s",
+		},
+		{
+			name:            "invalid delay",
+			jsonPath:        "{$.requests}",
+			delay:           "soon",
+			expectedMessage: `invalid rateOfChange delay: time: invalid duration "soon"`,
+		},
+		{
+			name:            "non numeric value",
+			jsonPath:        "{$.status}",
+			delay:           "10ms",
+			expectedMessage: "rateOfChange requires a numeric value, got: ok",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:          server.URL,
+						JSONPath:     test.jsonPath,
+						RateOfChange: &v1alpha1.WebMetricRateOfChange{Delay: test.delay},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
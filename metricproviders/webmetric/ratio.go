@@ -0,0 +1,61 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// errZeroDenominator is the error of a ratio with a denominator of zero and the inconclusive ZeroDenominator, for
+// which the measurement is Inconclusive
+var errZeroDenominator = errors.New("the denominator of the ratio is zero")
+
+// ratioValue returns the ratio of the numerator and denominator of the ratio in the data
+func ratioValue(ratio *v1alpha1.WebMetricRatio, data any) (any, string, error) {
+	switch ratio.ZeroDenominator {
+	case "", v1alpha1.WebMetricZeroDenominatorInconclusive, v1alpha1.WebMetricZeroDenominatorZero:
+	default:
+		return nil, "", fmt.Errorf("unknown ratio zeroDenominator: %s", ratio.ZeroDenominator)
+	}
+	numerator, err := ratioOperand(data, "numeratorPath", ratio.NumeratorPath)
+	if err != nil {
+		return nil, "", err
+	}
+	denominator, err := ratioOperand(data, "denominatorPath", ratio.DenominatorPath)
+	if err != nil {
+		return nil, "", err
+	}
+	if denominator == 0 {
+		if ratio.ZeroDenominator == v1alpha1.WebMetricZeroDenominatorZero {
+			return float64(0), "0", nil
+		}
+		return nil, "", errZeroDenominator
+	}
+	result := numerator / denominator
+	return result, strconv.FormatFloat(result, 'f', -1, 64), nil
+}
+
+// ratioOperand returns the numeric value of the path of an operand of the ratio in the data
+func ratioOperand(data any, name, path string) (float64, error) {
+	parser := jsonpath.New(name)
+	if err := parser.Parse(path); err != nil {
+		return 0, fmt.Errorf("invalid ratio %s: %v", name, err)
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return 0, fmt.Errorf("Could not find ratio %s in body: %s", name, err)
+	}
+	val, valString, err := getValue(fullResults)
+	if err != nil {
+		return 0, fmt.Errorf("ratio %s: %v", name, err)
+	}
+	number, ok := toFloat(val)
+	if !ok {
+		return 0, fmt.Errorf("ratio %s requires a numeric value, got: %s", name, valString)
+	}
+	return number, nil
+}
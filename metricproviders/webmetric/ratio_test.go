@@ -0,0 +1,110 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		zeroDenominator v1alpha1.WebMetricZeroDenominator
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "ratio below the threshold",
+			body:          `{"data": {"errors": 3, "requests": 200}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.015",
+		},
+		{
+			name:          "ratio above the threshold",
+			body:          `{"data": {"errors": 30, "requests": 200}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "0.15",
+		},
+		{
+			name:          "zero denominator is inconclusive by default",
+			body:          `{"data": {"errors": 0, "requests": 0}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseInconclusive,
+		},
+		{
+			name:            "zero denominator is inconclusive",
+			body:            `{"data": {"errors": 0, "requests": 0}}`,
+			zeroDenominator: v1alpha1.WebMetricZeroDenominatorInconclusive,
+			expectedPhase:   v1alpha1.AnalysisPhaseInconclusive,
+		},
+		{
+			name:            "zero denominator is a ratio of zero",
+			body:            `{"data": {"errors": 0, "requests": 0}}`,
+			zeroDenominator: v1alpha1.WebMetricZeroDenominatorZero,
+			expectedPhase:   v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:   "0",
+		},
+		{
+			name:            "unknown zero denominator",
+			body:            `{"data": {"errors": 3, "requests": 200}}`,
+			zeroDenominator: "infinity",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "unknown ratio zeroDenominator: infinity",
+		},
+		{
+			name:            "non numeric denominator",
+			body:            `{"data": {"errors": 3, "requests": "many"}}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: `ratio denominatorPath requires a numeric value, got: "many"`,
+		},
+		{
+			name:            "missing numerator",
+			body:            `{"data": {"requests": 200}}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "Could not find ratio numeratorPath in body: errors is not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.body)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result < 0.05",
+				FailureCondition: "result >= 0.05",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL: server.URL,
+						Ratio: &v1alpha1.WebMetricRatio{
+							NumeratorPath:   "{$.data.errors}",
+							DenominatorPath: "{$.data.requests}",
+							ZeroDenominator: test.zeroDenominator,
+						},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
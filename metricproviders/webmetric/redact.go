@@ -0,0 +1,77 @@
+package webmetric
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+var (
+	// messageURL matches the URLs in an error message, such as the one of a failed request
+	messageURL = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+	// sensitiveName matches the names of the query parameters and headers whose values are credentials
+	sensitiveName = regexp.MustCompile(`(?i)(token|key|secret|password|passwd|signature|sig|auth|credential|cookie)`)
+)
+
+// redactMessage removes the credentials of the metric from the message of a measurement: the secrets of its
+// authentications and sensitive headers, the passwords of URLs and the values of their sensitive query parameters
+func redactMessage(message string, web *v1alpha1.WebMetric) string {
+	message = redactCredentialsString(message, web)
+	for _, secret := range secrets(web) {
+		message = strings.ReplaceAll(message, secret, redactedAPIKey)
+	}
+	return messageURL.ReplaceAllStringFunc(message, redactURL)
+}
+
+// secrets returns the credentials of the metric besides its API keys
+func secrets(web *v1alpha1.WebMetric) []string {
+	var secrets []string
+	for _, auth := range authentications(web) {
+		if auth.Basic != nil {
+			secrets = append(secrets, auth.Basic.Password)
+		}
+		secrets = append(secrets, auth.OAuth2.ClientSecret)
+	}
+	secrets = append(secrets, proxyPassword(web.ProxyUrl))
+	for _, header := range web.Headers {
+		if sensitiveName.MatchString(header.Key) {
+			secrets = append(secrets, header.Value)
+		}
+	}
+	nonEmpty := secrets[:0]
+	for _, secret := range secrets {
+		if secret != "" {
+			nonEmpty = append(nonEmpty, secret)
+		}
+	}
+	return nonEmpty
+}
+
+// redactURL removes the password and the values of the sensitive query parameters of the URL
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if u.RawQuery != "" {
+		// the query is rewritten in place, since encoding it again would reorder and escape it
+		params := strings.Split(u.RawQuery, "&")
+		for i, param := range params {
+			name, _, ok := strings.Cut(param, "=")
+			if ok && sensitiveName.MatchString(name) {
+				params[i] = name + "=" + redactedAPIKey
+			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+	if _, ok := u.User.Password(); ok {
+		// the password is replaced as is, since the Userinfo would escape it
+		user := u.User
+		u.User = nil
+		scheme, rest, _ := strings.Cut(u.String(), "://")
+		return scheme + "://" + user.Username() + ":" + redactedAPIKey + "@" + rest
+	}
+	return u.String()
+}
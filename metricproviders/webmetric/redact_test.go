@@ -0,0 +1,56 @@
+package webmetric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRedactedErrorMessage(t *testing.T) {
+	// the server is closed so the request fails with an error echoing its URL
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result == true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      "http://metrics:hunter2@" + host + "/api/v1/query?service=web&access_token=s3cr3t",
+				JSONPath: "{$.ok}",
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Contains(t, measurement.Message, "http://metrics:*****@"+host+"/api/v1/query?service=web&access_token=*****")
+	assert.NotContains(t, measurement.Message, "hunter2")
+	assert.NotContains(t, measurement.Message, "s3cr3t")
+}
+
+func TestRedactMessage(t *testing.T) {
+	web := &v1alpha1.WebMetric{
+		Headers: []v1alpha1.WebMetricHeader{
+			{Key: "Authorization", Value: "Bearer header-token"},
+			{Key: "X-Tenant", Value: "team-a"},
+		},
+		Authentication: v1alpha1.Authentication{
+			Basic: &v1alpha1.BasicAuthConfig{Username: "user", Password: "basic-password"},
+		},
+	}
+	message := redactMessage(`received "Bearer header-token" for team-a with basic-password from https://example.com/?sig=abc&page=2`, web)
+	assert.Equal(t, `received "*****" for team-a with ***** from https://example.com/?sig=*****&page=2`, message)
+}
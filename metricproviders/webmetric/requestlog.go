@@ -0,0 +1,167 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const (
+	// RequestLogPath is the path of the debug endpoint serving the request logs of the web metrics
+	RequestLogPath = "/debug/webmetric/requests"
+	// maxRequestLogs caps the number of metrics with a request log, the least recently used log being evicted
+	maxRequestLogs = 200
+	// maxRequestLogBodyBytes truncates the response bodies kept in the request logs
+	maxRequestLogBodyBytes = 4096
+)
+
+// RequestLogEntry is a request of a web metric and its response. The headers and body of the request are not kept
+// since they may hold credentials
+type RequestLogEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Body       string    `json:"body,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// requestLog is a ring buffer of the last requests of a metric
+type requestLog struct {
+	entries []RequestLogEntry
+	// next is the index of the entry overwritten by the next request once the buffer is full
+	next int
+	// lastUsed orders the logs by use, for the eviction
+	lastUsed uint64
+}
+
+var (
+	requestLogsLock sync.Mutex
+	requestLogs     = map[string]*requestLog{}
+	requestLogUses  uint64
+)
+
+// requestLogKey identifies the request log of the metric of an analysis run
+func requestLogKey(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) string {
+	return run.Namespace + "/" + run.Name + "/" + metric.Name
+}
+
+// requestLogFor returns the request log of the key with the given capacity, creating it if needed. The least recently
+// used log is evicted when there are too many of them
+func requestLogFor(key string, size int) *requestLog {
+	requestLogsLock.Lock()
+	defer requestLogsLock.Unlock()
+
+	requestLogUses++
+	l, ok := requestLogs[key]
+	if !ok {
+		if len(requestLogs) >= maxRequestLogs {
+			evictRequestLog()
+		}
+		l = &requestLog{}
+		requestLogs[key] = l
+	}
+	l.lastUsed = requestLogUses
+	if cap(l.entries) != size {
+		// the last entries are kept when the size of the log changes
+		entries := l.list()
+		if len(entries) > size {
+			entries = entries[len(entries)-size:]
+		}
+		l.entries = append(make([]RequestLogEntry, 0, size), entries...)
+		l.next = 0
+	}
+	return l
+}
+
+func evictRequestLog() {
+	var oldestKey string
+	var oldest uint64
+	for key, l := range requestLogs {
+		if oldestKey == "" || l.lastUsed < oldest {
+			oldestKey, oldest = key, l.lastUsed
+		}
+	}
+	delete(requestLogs, oldestKey)
+}
+
+// add adds the entry to the log, overwriting the oldest entry when the log is full
+func (l *requestLog) add(entry RequestLogEntry) {
+	requestLogsLock.Lock()
+	defer requestLogsLock.Unlock()
+
+	if len(entry.Body) > maxRequestLogBodyBytes {
+		entry.Body = entry.Body[:maxRequestLogBodyBytes]
+	}
+	if len(l.entries) < cap(l.entries) {
+		l.entries = append(l.entries, entry)
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+}
+
+// list returns the entries of the log, oldest first. The caller must hold requestLogsLock
+func (l *requestLog) list() []RequestLogEntry {
+	return append(append([]RequestLogEntry{}, l.entries[l.next:]...), l.entries[:l.next]...)
+}
+
+// logRequest adds the request and its response or error to the request log of the provider, if it has one. The
+// credentials of the metric are redacted from the URL and the error, like from the message of a measurement
+func (p *Provider) logRequest(metric v1alpha1.Metric, request *http.Request, start time.Time, response *webResponse, err error) {
+	if p.requestLog == nil {
+		return
+	}
+	entry := RequestLogEntry{
+		Time:       start,
+		Method:     request.Method,
+		URL:        redactMessage(request.URL.String(), metric.Provider.Web),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if response != nil {
+		entry.StatusCode = response.statusCode
+		entry.Body = string(response.body)
+	}
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		entry.StatusCode = statusErr.statusCode
+	}
+	if err != nil {
+		entry.Error = redactMessage(err.Error(), metric.Provider.Web)
+	}
+	p.requestLog.add(entry)
+}
+
+// RequestLogs returns the entries of the request logs by analysis run metric, oldest first
+func RequestLogs() map[string][]RequestLogEntry {
+	requestLogsLock.Lock()
+	defer requestLogsLock.Unlock()
+
+	logs := make(map[string][]RequestLogEntry, len(requestLogs))
+	for key, l := range requestLogs {
+		logs[key] = l.list()
+	}
+	return logs
+}
+
+// ServeRequestLogs serves the request logs as JSON, or only the log of the <namespace>/<analysisrun>/<metric> of the
+// metric query parameter
+func ServeRequestLogs(rw http.ResponseWriter, req *http.Request) {
+	logs := RequestLogs()
+	var payload any = logs
+	if key := req.URL.Query().Get("metric"); key != "" {
+		entries, ok := logs[key]
+		if !ok {
+			http.Error(rw, "no request log for metric "+key, http.StatusNotFound)
+			return
+		}
+		payload = entries
+	}
+	rw.Header().Set(ContentTypeKey, ContentTypeJsonValue)
+	json.NewEncoder(rw).Encode(payload)
+}
@@ -0,0 +1,190 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func resetRequestLogs(t *testing.T) {
+	requestLogs = map[string]*requestLog{}
+	t.Cleanup(func() {
+		requestLogs = map[string]*requestLog{}
+	})
+}
+
+func requestLogURLs(entries []RequestLogEntry) []string {
+	urls := []string{}
+	for _, entry := range entries {
+		urls = append(urls, entry.URL)
+	}
+	return urls
+}
+
+func TestRequestLogCapacity(t *testing.T) {
+	resetRequestLogs(t)
+
+	l := requestLogFor("ns/run/foo", 3)
+	for i := 1; i <= 2; i++ {
+		l.add(RequestLogEntry{URL: fmt.Sprintf("/%d", i)})
+	}
+	assert.Equal(t, []string{"/1", "/2"}, requestLogURLs(RequestLogs()["ns/run/foo"]))
+
+	// the oldest entries are evicted once the log is full
+	for i := 3; i <= 7; i++ {
+		l.add(RequestLogEntry{URL: fmt.Sprintf("/%d", i)})
+	}
+	assert.Equal(t, []string{"/5", "/6", "/7"}, requestLogURLs(RequestLogs()["ns/run/foo"]))
+
+	// the last entries are kept when the log shrinks or grows
+	l = requestLogFor("ns/run/foo", 2)
+	assert.Equal(t, []string{"/6", "/7"}, requestLogURLs(RequestLogs()["ns/run/foo"]))
+	l = requestLogFor("ns/run/foo", 4)
+	l.add(RequestLogEntry{URL: "/8"})
+	l.add(RequestLogEntry{URL: "/9"})
+	l.add(RequestLogEntry{URL: "/10"})
+	assert.Equal(t, []string{"/7", "/8", "/9", "/10"}, requestLogURLs(RequestLogs()["ns/run/foo"]))
+
+	// bodies are truncated
+	l.add(RequestLogEntry{URL: "/11", Body: strings.Repeat("a", maxRequestLogBodyBytes+1)})
+	entries := RequestLogs()["ns/run/foo"]
+	assert.Len(t, entries[len(entries)-1].Body, maxRequestLogBodyBytes)
+}
+
+func TestRequestLogEviction(t *testing.T) {
+	resetRequestLogs(t)
+
+	for i := 0; i < maxRequestLogs; i++ {
+		requestLogFor(fmt.Sprintf("ns/run/metric-%d", i), 1)
+	}
+	// the first log is used again, so the second one is the least recently used
+	requestLogFor("ns/run/metric-0", 1)
+	requestLogFor("ns/run/new", 1)
+
+	logs := RequestLogs()
+	assert.Len(t, logs, maxRequestLogs)
+	assert.Contains(t, logs, "ns/run/metric-0")
+	assert.NotContains(t, logs, "ns/run/metric-1")
+	assert.Contains(t, logs, "ns/run/new")
+}
+
+func TestRunWithRequestLog(t *testing.T) {
+	resetRequestLogs(t)
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if status != http.StatusOK {
+			rw.WriteHeader(status)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            server.URL + "/query",
+				RequestLogSize: 2,
+				Authentication: v1alpha1.Authentication{
+					APIKey: &v1alpha1.APIKeyConfig{Key: "secret", In: v1alpha1.APIKeyLocationQuery, Name: "api_key"},
+				},
+			},
+		},
+	}
+	run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+	newProvider := func() *Provider {
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		return NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+	}
+
+	for i := 0; i < 2; i++ {
+		newProvider().Run(run, metric)
+	}
+	status = http.StatusBadGateway
+	newProvider().Run(run, metric)
+
+	rw := httptest.NewRecorder()
+	ServeRequestLogs(rw, httptest.NewRequest(http.MethodGet, RequestLogPath+"?metric=ns/run/foo", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	var entries []RequestLogEntry
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &entries))
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, http.StatusOK, entries[0].StatusCode)
+		assert.Equal(t, `{"ok": true}`, entries[0].Body)
+		assert.Equal(t, http.StatusBadGateway, entries[1].StatusCode)
+		assert.Equal(t, "received non 2xx response code: 502", entries[1].Error)
+		assert.Equal(t, http.MethodGet, entries[1].Method)
+		assert.NotContains(t, entries[1].URL, "secret")
+	}
+
+	rw = httptest.NewRecorder()
+	ServeRequestLogs(rw, httptest.NewRequest(http.MethodGet, RequestLogPath+"?metric=ns/run/bar", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	// metrics without a RequestLogSize keep no log
+	metric.Name = "bar"
+	metric.Provider.Web.RequestLogSize = 0
+	newProvider().Run(run, metric)
+	assert.NotContains(t, RequestLogs(), "ns/run/bar")
+}
+
+func TestRunWithRequestLogRedaction(t *testing.T) {
+	resetRequestLogs(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	serverURL := strings.Replace(server.URL, "://", "://user:hunter2@", 1) + "/query?token=t0ps3cret&q=up"
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.ok",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            serverURL,
+				RequestLogSize: 2,
+			},
+		},
+	}
+	run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	provider.Run(run, metric)
+	// the URL of the request is part of the error once the server is gone
+	server.Close()
+	provider.Run(run, metric)
+
+	entries := RequestLogs()["ns/run/foo"]
+	if assert.Len(t, entries, 2) {
+		for _, entry := range entries {
+			assert.Contains(t, entry.URL, "user:"+redactedAPIKey+"@")
+			assert.Contains(t, entry.URL, "token="+redactedAPIKey+"&q=up")
+			assert.NotContains(t, entry.URL, "hunter2")
+			assert.NotContains(t, entry.URL, "t0ps3cret")
+		}
+		assert.Contains(t, entries[1].Error, "token="+redactedAPIKey)
+		assert.NotContains(t, entries[1].Error, "hunter2")
+		assert.NotContains(t, entries[1].Error, "t0ps3cret")
+	}
+}
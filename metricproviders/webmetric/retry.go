@@ -0,0 +1,41 @@
+package webmetric
+
+import (
+	"encoding/json"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/evaluate"
+)
+
+// retryConditionError is the error of a response matching the RetryCondition of the metric. Like a 5xx response, it
+// falls back to the next URL of the metric, if any, and errors the measurement so it is taken again
+type retryConditionError struct{}
+
+func (e *retryConditionError) Error() string {
+	return "received a response matching the retryCondition"
+}
+
+func (e *retryConditionError) Is(target error) bool {
+	return target == ErrStatusCode
+}
+
+// checkRetryCondition evaluates the RetryCondition of the metric against the whole body of a JSON response. The
+// responses which are not JSON never match it
+func checkRetryCondition(web *v1alpha1.WebMetric, response *webResponse) error {
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return nil
+	}
+	vars := map[string]any{
+		"statusCode": response.statusCode,
+		"body":       data,
+	}
+	retry, err := evaluate.EvalConditionWithVars(data, vars, web.RetryCondition)
+	if err != nil {
+		return asEvaluationError(err)
+	}
+	if retry {
+		return &retryConditionError{}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func newRetryConditionServer(transientResponses int) *httptest.Server {
+	requests := 0
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Header().Set("Content-Type", "application/json")
+		if requests <= transientResponses {
+			io.WriteString(rw, `{"code": "TRY_AGAIN"}`)
+			return
+		}
+		io.WriteString(rw, `{"code": "OK", "value": 0.99}`)
+	}))
+}
+
+func runRetryConditionMetric(t *testing.T, metric v1alpha1.Metric) v1alpha1.Measurement {
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+	return provider.Run(newAnalysisRun(), metric)
+}
+
+func TestRetryCondition(t *testing.T) {
+	server := newRetryConditionServer(2)
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result > 0.9",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            server.URL,
+				JSONPath:       "{$.value}",
+				RetryCondition: `result.code == "TRY_AGAIN"`,
+			},
+		},
+	}
+
+	// the transient responses error the measurements, which are taken again until the response succeeds
+	for i := 0; i < 2; i++ {
+		measurement := runRetryConditionMetric(t, metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+		assert.Equal(t, "received a response matching the retryCondition", measurement.Message)
+		assert.Equal(t, ErrorCauseStatusCode, measurement.Metadata[ErrorCauseMetadataKey])
+	}
+	measurement := runRetryConditionMetric(t, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "0.99", measurement.Value)
+}
+
+func TestRetryConditionFallsBack(t *testing.T) {
+	primary := newRetryConditionServer(1)
+	defer primary.Close()
+	fallback := newRetryConditionServer(0)
+	defer fallback.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result > 0.9",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            primary.URL,
+				FallbackUrls:   []string{fallback.URL},
+				JSONPath:       "{$.value}",
+				RetryCondition: `result.code == "TRY_AGAIN"`,
+			},
+		},
+	}
+	measurement := runRetryConditionMetric(t, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "0.99", measurement.Value)
+}
+
+func TestInvalidRetryCondition(t *testing.T) {
+	server := newRetryConditionServer(0)
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result > 0.9",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            server.URL,
+				JSONPath:       "{$.value}",
+				RetryCondition: `result.code ==`,
+			},
+		},
+	}
+	measurement := runRetryConditionMetric(t, metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, ErrorCauseEvaluation, measurement.Metadata[ErrorCauseMetadataKey])
+}
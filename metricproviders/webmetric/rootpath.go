@@ -0,0 +1,30 @@
+package webmetric
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// unwrapRoot returns the payload at the RootPath of the metric in the data, or the data itself when the metric has no
+// RootPath
+func unwrapRoot(web *v1alpha1.WebMetric, data any) (any, error) {
+	if web.RootPath == "" {
+		return data, nil
+	}
+	parser := jsonpath.New("root")
+	if err := parser.Parse(web.RootPath); err != nil {
+		return nil, fmt.Errorf("invalid rootPath: %v", err)
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("Could not find rootPath in body: %s", err)
+	}
+	root, _, err := getValue(fullResults)
+	if err != nil {
+		return nil, fmt.Errorf("rootPath produced no value")
+	}
+	return root, nil
+}
@@ -0,0 +1,103 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRootPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"data": {"successRate": 0.99, "errors": 1, "total": 100, "samples": 100, "region": "eu-west-1"}, "meta": {"version": 2}}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name             string
+		web              v1alpha1.WebMetric
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedMetadata map[string]string
+		expectedMessage  string
+	}{
+		{
+			name: "value and metadata paths are relative to the root",
+			web: v1alpha1.WebMetric{
+				RootPath:       "{$.data}",
+				JSONPath:       "{$.successRate}",
+				MetadataPaths:  map[string]string{"region": "{$.region}"},
+				MinSampleCount: &v1alpha1.WebMetricMinSampleCount{JSONPath: "{$.samples}", Count: 50},
+			},
+			// the body is still the whole response
+			successCondition: "result > 0.95 && body.meta.version == 2",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "0.99",
+			expectedMetadata: map[string]string{"region": `"eu-west-1"`},
+		},
+		{
+			name: "json pointer is relative to the root",
+			web: v1alpha1.WebMetric{
+				RootPath:    "{$.data}",
+				JSONPointer: "/region",
+			},
+			successCondition: `result == "eu-west-1"`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `"eu-west-1"`,
+		},
+		{
+			name: "derived value paths are relative to the root",
+			web: v1alpha1.WebMetric{
+				RootPath: "{$.data}",
+				DerivedValue: &v1alpha1.WebMetricDerivedValue{
+					Paths:      map[string]string{"errors": "{$.errors}", "total": "{$.total}"},
+					Expression: "errors / total",
+				},
+			},
+			successCondition: "result < 0.05",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "0.01",
+		},
+		{
+			name: "missing root",
+			web: v1alpha1.WebMetric{
+				RootPath: "{$.payload}",
+				JSONPath: "{$.successRate}",
+			},
+			successCondition: "result > 0.95",
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMessage:  "Could not find rootPath in body: payload is not found",
+			expectedMetadata: map[string]string{ErrorCauseMetadataKey: ErrorCauseParse},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			web := test.web
+			web.URL = server.URL
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider:         v1alpha1.MetricProvider{Web: &web},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.Equal(t, test.expectedMetadata, measurement.Metadata)
+		})
+	}
+}
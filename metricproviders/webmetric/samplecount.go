@@ -0,0 +1,86 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+)
+
+// SampleCountMetadataKey is the measurement metadata key of the sample count of a MinSampleCount with a window
+const SampleCountMetadataKey = "sampleCount"
+
+// hasMinSampleCount returns whether the sample count in the response, added to the sample count of the previous
+// measurements of the window, reaches the minimum
+func hasMinSampleCount(minSampleCount *v1alpha1.WebMetricMinSampleCount, data any, windowCount float64) (bool, error) {
+	count, err := sampleCount(minSampleCount, data)
+	if err != nil {
+		return false, err
+	}
+	return count+windowCount >= float64(minSampleCount.Count), nil
+}
+
+// sampleCount returns the sample count in the response
+func sampleCount(minSampleCount *v1alpha1.WebMetricMinSampleCount, data any) (float64, error) {
+	parser := jsonpath.New("sampleCount")
+	if err := parser.Parse(minSampleCount.JSONPath); err != nil {
+		return 0, fmt.Errorf("invalid minSampleCount jsonPath: %v", err)
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return 0, fmt.Errorf("Could not find minSampleCount jsonPath in body: %s", err)
+	}
+	val, _, err := getValue(fullResults)
+	if err != nil {
+		return 0, errors.New("minSampleCount jsonPath produced no value")
+	}
+	count, ok := val.(float64)
+	if !ok {
+		return 0, fmt.Errorf("sample count must be a number, got: %v", val)
+	}
+	return count, nil
+}
+
+// windowSampleCount returns the sum of the sample counts of the previous measurements of the window of the metric.
+// The measurements which errored have no sample count, and count for none
+func windowSampleCount(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) float64 {
+	minSampleCount := metric.Provider.Web.MinSampleCount
+	if minSampleCount == nil || minSampleCount.Window <= 1 {
+		return 0
+	}
+	measurements := analysisutil.ArrayMeasurement(run, metric.Name)
+	var total float64
+	for i := len(measurements) - 1; i >= 0 && i >= len(measurements)-int(minSampleCount.Window-1); i-- {
+		if count, err := strconv.ParseFloat(measurements[i].Metadata[SampleCountMetadataKey], 64); err == nil {
+			total += count
+		}
+	}
+	return total
+}
+
+// addSampleCount adds the sample count of the response to the metadata of the measurement, so that the next
+// measurements of the window can sum it
+func addSampleCount(metadata map[string]string, web *v1alpha1.WebMetric, response *webResponse) map[string]string {
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return metadata
+	}
+	data, err := unwrapRoot(web, data)
+	if err != nil {
+		return metadata
+	}
+	count, err := sampleCount(web.MinSampleCount, data)
+	if err != nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata[SampleCountMetadataKey] = strconv.FormatFloat(count, 'f', -1, 64)
+	return metadata
+}
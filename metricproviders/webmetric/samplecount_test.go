@@ -0,0 +1,139 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestMinSampleCount(t *testing.T) {
+	tests := []struct {
+		name            string
+		response        string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "below the threshold",
+			response:      `{"successRate": 0.5, "samples": 3}`,
+			expectedPhase: v1alpha1.AnalysisPhaseInconclusive,
+			expectedValue: "0.5",
+		},
+		{
+			name:          "at the threshold",
+			response:      `{"successRate": 0.5, "samples": 10}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "0.5",
+		},
+		{
+			name:          "above the threshold",
+			response:      `{"successRate": 0.99, "samples": 1200}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:            "missing sample count",
+			response:        `{"successRate": 0.99}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "Could not find minSampleCount jsonPath in body: samples is not found",
+		},
+		{
+			name:            "non numeric sample count",
+			response:        `{"successRate": 0.99, "samples": "many"}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "sample count must be a number, got: many",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result >= 0.95",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						JSONPath: "{$.successRate}",
+						MinSampleCount: &v1alpha1.WebMetricMinSampleCount{
+							JSONPath: "{$.samples}",
+							Count:    10,
+						},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
+
+func TestMinSampleCountWindow(t *testing.T) {
+	samples := []string{"4", "3", "5", "1"}
+	request := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"successRate": 0.99, "samples": `+samples[request]+`}`)
+		request++
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result >= 0.95",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.successRate}",
+				MinSampleCount: &v1alpha1.WebMetricMinSampleCount{
+					JSONPath: "{$.samples}",
+					Count:    10,
+					Window:   3,
+				},
+			},
+		},
+	}
+	run := newAnalysisRun()
+	run.Status.MetricResults = []v1alpha1.MetricResult{{Name: "foo"}}
+
+	// the sample counts add up across the window of the last 3 measurements: 4, 7, 12, then 9 once the first one
+	// leaves the window
+	expectedPhases := []v1alpha1.AnalysisPhase{
+		v1alpha1.AnalysisPhaseInconclusive,
+		v1alpha1.AnalysisPhaseInconclusive,
+		v1alpha1.AnalysisPhaseSuccessful,
+		v1alpha1.AnalysisPhaseInconclusive,
+	}
+	for i, expectedPhase := range expectedPhases {
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(run, metric)
+		assert.Equal(t, expectedPhase, measurement.Phase, "measurement %d: %s", i, measurement.Message)
+		assert.Equal(t, samples[i], measurement.Metadata[SampleCountMetadataKey])
+		run.Status.MetricResults[0].Measurements = append(run.Status.MetricResults[0].Measurements, measurement)
+	}
+}
@@ -0,0 +1,91 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// SetServiceLister sets the lister the provider reads the Services of the ServiceRef of the metrics with
+func (p *Provider) SetServiceLister(lister corelisters.ServiceLister) {
+	p.services = lister
+}
+
+// loadServiceRef resolves the in-cluster URL of the Service referenced by the ServiceRef of the metric, by default in
+// the namespace of the AnalysisRun. Like the BodyFrom, the Service is read from the informer cache when the
+// measurement is taken. It is a no-op for metrics without a ServiceRef
+func (p *Provider) loadServiceRef(namespace string, metric v1alpha1.Metric) error {
+	ref := metric.Provider.Web.ServiceRef
+	if ref == nil {
+		return nil
+	}
+	if ref.Name == "" {
+		return errors.New("ServiceRef of WebMetric requires a name")
+	}
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	scheme := ref.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	if scheme != "http" && scheme != "https" {
+		return fmt.Errorf("invalid ServiceRef scheme %q: it must be http or https", scheme)
+	}
+	if p.services == nil {
+		return errors.New("ServiceRef of WebMetric requires the Service lister of the provider")
+	}
+
+	service, err := p.services.Services(namespace).Get(ref.Name)
+	if err != nil {
+		return err
+	}
+	port, err := servicePort(ref, service.Spec.Ports)
+	if err != nil {
+		return err
+	}
+	p.serviceURL = fmt.Sprintf("%s://%s.%s.svc:%d", scheme, ref.Name, namespace, port)
+	return nil
+}
+
+// servicePort returns the number of the port of the Service referenced by the ServiceRef
+func servicePort(ref *v1alpha1.WebMetricServiceRef, ports []corev1.ServicePort) (int32, error) {
+	if ref.Port == nil {
+		if len(ports) != 1 {
+			return 0, fmt.Errorf("Service '%s' has %d ports: the ServiceRef of WebMetric requires a port", ref.Name, len(ports))
+		}
+		return ports[0].Port, nil
+	}
+	for _, port := range ports {
+		if ref.Port.Type == intstr.Int && port.Port == ref.Port.IntVal ||
+			ref.Port.Type == intstr.String && port.Name == ref.Port.StrVal {
+			return port.Port, nil
+		}
+	}
+	return 0, fmt.Errorf("Service '%s' has no port %s", ref.Name, ref.Port.String())
+}
+
+// withServiceURL returns a copy of the metric with its URL, a path, appended to the URL of the ServiceRef
+func (p *Provider) withServiceURL(metric v1alpha1.Metric) (v1alpha1.Metric, error) {
+	if p.serviceURL == "" {
+		return metric, errors.New("ServiceRef of WebMetric was not loaded")
+	}
+	if u, err := url.Parse(metric.Provider.Web.URL); err != nil || u.Scheme != "" || u.Host != "" {
+		return metric, fmt.Errorf("the url of a WebMetric with a ServiceRef must be a path, e.g. /api/v1/measurement")
+	}
+	web := *metric.Provider.Web
+	path := web.URL
+	if path != "" && !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "?") {
+		path = "/" + path
+	}
+	web.URL = p.serviceURL + path
+	metric.Provider.Web = &web
+	return metric, nil
+}
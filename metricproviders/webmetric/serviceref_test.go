@@ -0,0 +1,126 @@
+package webmetric
+
+import (
+	"net/http"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func newServiceLister(t *testing.T, services ...*corev1.Service) corelisters.ServiceLister {
+	informer := k8sinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0).Core().V1().Services()
+	for _, service := range services {
+		assert.NoError(t, informer.Informer().GetIndexer().Add(service))
+	}
+	return informer.Lister()
+}
+
+func TestLoadServiceRef(t *testing.T) {
+	services := newServiceLister(t,
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "monitoring"},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80},
+				{Name: "https", Port: 443},
+			}},
+		},
+	)
+	port := func(port intstr.IntOrString) *intstr.IntOrString { return &port }
+
+	tests := []struct {
+		name                 string
+		serviceRef           v1alpha1.WebMetricServiceRef
+		url                  string
+		expectedURL          string
+		expectedErrorMessage string
+	}{
+		{
+			name:        "only port of the service",
+			serviceRef:  v1alpha1.WebMetricServiceRef{Name: "metrics"},
+			url:         "/api/v1/measurement?service=canary",
+			expectedURL: "http://metrics.ns.svc:8080/api/v1/measurement?service=canary",
+		},
+		{
+			name:        "named port in another namespace",
+			serviceRef:  v1alpha1.WebMetricServiceRef{Name: "api", Namespace: "monitoring", Port: port(intstr.FromString("https")), Scheme: "https"},
+			url:         "health",
+			expectedURL: "https://api.monitoring.svc:443/health",
+		},
+		{
+			name:        "port number",
+			serviceRef:  v1alpha1.WebMetricServiceRef{Name: "api", Namespace: "monitoring", Port: port(intstr.FromInt(80))},
+			expectedURL: "http://api.monitoring.svc:80",
+		},
+		{
+			name:                 "missing service",
+			serviceRef:           v1alpha1.WebMetricServiceRef{Name: "other"},
+			expectedErrorMessage: `service "other" not found`,
+		},
+		{
+			name:                 "several ports without a port",
+			serviceRef:           v1alpha1.WebMetricServiceRef{Name: "api", Namespace: "monitoring"},
+			expectedErrorMessage: "Service 'api' has 2 ports: the ServiceRef of WebMetric requires a port",
+		},
+		{
+			name:                 "missing port",
+			serviceRef:           v1alpha1.WebMetricServiceRef{Name: "metrics", Port: port(intstr.FromString("grpc"))},
+			expectedErrorMessage: "Service 'metrics' has no port grpc",
+		},
+		{
+			name:                 "absolute url",
+			serviceRef:           v1alpha1.WebMetricServiceRef{Name: "metrics"},
+			url:                  "http://metrics.ns.svc:8080/api/v1/measurement",
+			expectedErrorMessage: "the url of a WebMetric with a ServiceRef must be a path, e.g. /api/v1/measurement",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serviceRef := test.serviceRef
+			metric := v1alpha1.Metric{
+				Name: "foo",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{URL: test.url, ServiceRef: &serviceRef},
+				},
+			}
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), http.DefaultClient, nil)
+			provider.SetServiceLister(services)
+			err := provider.loadServiceRef("ns", metric)
+			if err == nil {
+				metric, err = provider.withServiceURL(metric)
+			}
+			if test.expectedErrorMessage != "" {
+				assert.EqualError(t, err, test.expectedErrorMessage)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedURL, metric.Provider.Web.URL)
+		})
+	}
+}
+
+func TestServiceRefWithoutLister(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{URL: "/health", ServiceRef: &v1alpha1.WebMetricServiceRef{Name: "metrics"}},
+		},
+	}
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), http.DefaultClient, nil)
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, "ServiceRef of WebMetric requires the Service lister of the provider", measurement.Message)
+}
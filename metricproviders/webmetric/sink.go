@@ -0,0 +1,93 @@
+package webmetric
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// MeasurementSink receives the measurements of web metrics, for instance to mirror them to a data lake
+type MeasurementSink interface {
+	// Send sends the measurement of the metric. An error does not affect the measurement
+	Send(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) error
+}
+
+// SinkPayload is the serialized measurement sent by the HTTP measurement sink
+type SinkPayload struct {
+	Metric      string               `json:"metric"`
+	AnalysisRun string               `json:"analysisRun"`
+	Namespace   string               `json:"namespace"`
+	Measurement v1alpha1.Measurement `json:"measurement"`
+}
+
+// HTTPMeasurementSink posts the measurements as JSON documents to an HTTP endpoint
+type HTTPMeasurementSink struct {
+	client  *http.Client
+	url     string
+	headers []v1alpha1.WebMetricHeader
+}
+
+// NewHTTPMeasurementSink returns a sink posting the measurements to the endpoint with the timeout. It does not use the
+// client of the metric, so the credentials of the metric are not sent to the sink
+func NewHTTPMeasurementSink(config v1alpha1.WebMetricMeasurementSink, timeout time.Duration) *HTTPMeasurementSink {
+	return &HTTPMeasurementSink{
+		client:  &http.Client{Timeout: timeout, Transport: transport},
+		url:     config.URL,
+		headers: config.Headers,
+	}
+}
+
+func (s *HTTPMeasurementSink) Send(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) error {
+	body, err := json.Marshal(SinkPayload{
+		Metric:      metric.Name,
+		AnalysisRun: run.Name,
+		Namespace:   run.Namespace,
+		Measurement: measurement,
+	})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set(ContentTypeKey, ContentTypeJsonValue)
+	for _, header := range s.headers {
+		request.Header.Set(header.Key, header.Value)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("received non 2xx response code: %v", response.StatusCode)
+	}
+	return nil
+}
+
+// AddMeasurementSink adds a sink receiving every measurement of the provider
+func (p *Provider) AddMeasurementSink(sink MeasurementSink) {
+	p.sinks = append(p.sinks, sink)
+}
+
+// sendMeasurement sends the measurement to the sinks. Errors are only logged since the sinks must not affect the
+// measurement
+func (p *Provider) sendMeasurement(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) {
+	sinks := p.sinks
+	if config := metric.Provider.Web.MeasurementSink; config != nil {
+		sinks = append(sinks[:len(sinks):len(sinks)], NewHTTPMeasurementSink(*config, p.client.Timeout))
+	}
+	for _, sink := range sinks {
+		if err := sink.Send(run, metric, measurement); err != nil {
+			p.logCtx.Warnf("Failed to send the measurement to the sink: %v", err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+type fakeMeasurementSink struct {
+	measurements []v1alpha1.Measurement
+	err          error
+}
+
+func (s *fakeMeasurementSink) Send(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) error {
+	s.measurements = append(s.measurements, measurement)
+	return s.err
+}
+
+func TestHTTPMeasurementSink(t *testing.T) {
+	tests := []struct {
+		name              string
+		webServerResponse string
+		sinkStatus        int
+		expectedPhase     v1alpha1.AnalysisPhase
+	}{
+		{
+			name:              "successful measurement",
+			webServerResponse: `{"ok": true}`,
+			sinkStatus:        200,
+			expectedPhase:     v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:              "failed measurement",
+			webServerResponse: `{"ok": false}`,
+			sinkStatus:        200,
+			expectedPhase:     v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			name:              "sink failure does not affect the measurement",
+			webServerResponse: `{"ok": true}`,
+			sinkStatus:        500,
+			expectedPhase:     v1alpha1.AnalysisPhaseSuccessful,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var payload *SinkPayload
+			sink := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, ContentTypeJsonValue, req.Header.Get(ContentTypeKey))
+				assert.Equal(t, "value", req.Header.Get("key"))
+				assert.Empty(t, req.Header.Get("Authorization"))
+				bodyBytes, _ := io.ReadAll(req.Body)
+				payload = &SinkPayload{}
+				assert.NoError(t, json.Unmarshal(bodyBytes, payload))
+				rw.WriteHeader(test.sinkStatus)
+			}))
+			defer sink.Close()
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.webServerResponse)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				FailureCondition: "result == false",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						JSONPath: "{$.ok}",
+						Headers:  []v1alpha1.WebMetricHeader{{Key: "Authorization", Value: "Bearer secret"}},
+						MeasurementSink: &v1alpha1.WebMetricMeasurementSink{
+							URL:     sink.URL,
+							Headers: []v1alpha1.WebMetricHeader{{Key: "key", Value: "value"}},
+						},
+					},
+				},
+			}
+			run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(run, metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase)
+			if assert.NotNil(t, payload) {
+				assert.Equal(t, "foo", payload.Metric)
+				assert.Equal(t, "run", payload.AnalysisRun)
+				assert.Equal(t, "ns", payload.Namespace)
+				assert.Equal(t, measurement.Phase, payload.Measurement.Phase)
+				assert.Equal(t, measurement.Value, payload.Measurement.Value)
+				assert.Equal(t, measurement.StartedAt.Unix(), payload.Measurement.StartedAt.Unix())
+			}
+		})
+	}
+}
+
+func TestAddMeasurementSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{URL: server.URL},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+	failing := &fakeMeasurementSink{err: errors.New("sink unavailable")}
+	sink := &fakeMeasurementSink{}
+	provider.AddMeasurementSink(failing)
+	provider.AddMeasurementSink(sink)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.Equal(t, []v1alpha1.Measurement{measurement}, failing.measurements)
+	assert.Equal(t, []v1alpha1.Measurement{measurement}, sink.measurements)
+}
@@ -0,0 +1,40 @@
+package webmetric
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SSEAcceptValue is the Accept header of the requests of server-sent events metrics
+const SSEAcceptValue = "text/event-stream"
+
+// readFirstEvent returns the data of the first event of a server-sent events stream which has some. The stream is not
+// read further, since it may never end
+func readFirstEvent(reader io.Reader) ([]byte, error) {
+	lines := bufio.NewReader(reader)
+	var data []string
+	for {
+		line, err := lines.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, &connectionError{err: fmt.Errorf("no event received from the event stream: %v", err)}
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			// comments and the other fields than data are ignored
+			field, value, _ := strings.Cut(line, ":")
+			if field == "data" {
+				data = append(data, strings.TrimPrefix(value, " "))
+			}
+		}
+		// a blank line dispatches the event if it has data, like the end of the stream
+		if data != nil && (line == "" || err == io.EOF) {
+			return []byte(strings.Join(data, "\n")), nil
+		}
+		if err == io.EOF {
+			return nil, errors.New("the event stream ended without an event")
+		}
+	}
+}
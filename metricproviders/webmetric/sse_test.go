@@ -0,0 +1,77 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func newSSEServer(events string, done chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", req.Header.Get("Accept"))
+		io.WriteString(rw, events)
+		rw.(http.Flusher).Flush()
+		// the stream stays open
+		<-done
+	}))
+}
+
+func runSSEMetric(t *testing.T, url string) v1alpha1.Measurement {
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result < 0.05",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:            url,
+				JSONPath:       "{$.errorRate}",
+				TimeoutSeconds: 1,
+				SSE:            true,
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+	return provider.Run(newAnalysisRun(), metric)
+}
+
+func TestSSEFirstEvent(t *testing.T) {
+	done := make(chan struct{})
+	server := newSSEServer(": connected\n\nevent: metric\ndata: {\"errorRate\":\ndata:  0.01}\n\ndata: {\"errorRate\": 0.5}\n\n", done)
+	defer server.Close()
+	defer close(done)
+
+	measurement := runSSEMetric(t, server.URL)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, "0.01", measurement.Value)
+}
+
+func TestSSENoEvent(t *testing.T) {
+	done := make(chan struct{})
+	server := newSSEServer(": connected\n\n", done)
+	defer server.Close()
+	defer close(done)
+
+	measurement := runSSEMetric(t, server.URL)
+	assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase)
+	assert.True(t, strings.HasPrefix(measurement.Message, "no event received from the event stream: "), measurement.Message)
+	assert.Equal(t, ErrorCauseConnection, measurement.Metadata[ErrorCauseMetadataKey])
+}
+
+func TestReadFirstEvent(t *testing.T) {
+	data, err := readFirstEvent(strings.NewReader("data: {\"ok\": true}"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ok": true}`, string(data))
+
+	_, err = readFirstEvent(strings.NewReader("retry: 1000\r\n\r\n"))
+	assert.EqualError(t, err, "the event stream ended without an event")
+}
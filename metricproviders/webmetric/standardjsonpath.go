@@ -0,0 +1,34 @@
+package webmetric
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/theory/jsonpath"
+)
+
+// standardJSONPath is a JSONPath query with the syntax and semantics of RFC 9535, as used by most JSONPath tools,
+// rather than the template syntax of k8s.io/client-go/util/jsonpath. A query which selects no value is not an error
+type standardJSONPath struct {
+	path *jsonpath.Path
+}
+
+// parseStandardJSONPath parses an RFC 9535 JSONPath query, e.g. "$.data[?@.status == 'ok'].value"
+func parseStandardJSONPath(query string) (*standardJSONPath, error) {
+	path, err := jsonpath.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", query, err)
+	}
+	return &standardJSONPath{path: path}, nil
+}
+
+// FindResults returns the values selected in the data, in the format of k8s.io/client-go/util/jsonpath
+func (j *standardJSONPath) FindResults(data any) ([][]reflect.Value, error) {
+	nodes := j.path.Select(data)
+	results := make([]reflect.Value, len(nodes))
+	for i := range nodes {
+		// a valid value even for a null node, which reflect.ValueOf(nil) is not
+		results[i] = reflect.ValueOf(&nodes[i]).Elem()
+	}
+	return [][]reflect.Value{results}, nil
+}
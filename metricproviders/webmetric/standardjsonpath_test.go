@@ -0,0 +1,185 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const jsonPathDocument = `{
+	"data": {
+		"app.kubernetes.io/name": "checkout",
+		"services": [
+			{"name": "checkout", "status": "ok", "latency": 80, "errors": null},
+			{"name": "cart", "status": "ok", "latency": 120},
+			{"name": "search", "status": "degraded", "latency": 60}
+		],
+		"threshold": 100
+	}
+}`
+
+func TestStandardJSONPath(t *testing.T) {
+	var data any
+	assert.NoError(t, json.Unmarshal([]byte(jsonPathDocument), &data))
+
+	tests := []struct {
+		query          string
+		expectedValues []any
+	}{
+		{query: "$.data.threshold", expectedValues: []any{float64(100)}},
+		{query: "$['data']['app.kubernetes.io/name']", expectedValues: []any{"checkout"}},
+		{query: `$.data["app.kubernetes.io/name"]`, expectedValues: []any{"checkout"}},
+		{query: "$.data.services[0].name", expectedValues: []any{"checkout"}},
+		{query: "$.data.services[-1].name", expectedValues: []any{"search"}},
+		{query: "$.data.services[5].name", expectedValues: []any{}},
+		{query: "$.data.services[*].name", expectedValues: []any{"checkout", "cart", "search"}},
+		{query: "$.data.services[1:].name", expectedValues: []any{"cart", "search"}},
+		{query: "$.data.services[::-2].name", expectedValues: []any{"search", "checkout"}},
+		{query: "$.data.services[0,2].name", expectedValues: []any{"checkout", "search"}},
+		{query: "$..latency", expectedValues: []any{float64(80), float64(120), float64(60)}},
+		{query: "$.data.services[0].errors", expectedValues: []any{nil}},
+		{query: "$.data.missing", expectedValues: []any{}},
+		{query: "$.data.services[?@.status == 'ok'].name", expectedValues: []any{"checkout", "cart"}},
+		{query: "$.data.services[?(@.status == 'ok' && @.latency < 100)].name", expectedValues: []any{"checkout"}},
+		{query: "$.data.services[?@.latency > $.data.threshold || @.status != 'ok'].name", expectedValues: []any{"cart", "search"}},
+		{query: "$.data.services[?!(@.latency >= 80)].name", expectedValues: []any{"search"}},
+		{query: "$.data.services[?@.errors].name", expectedValues: []any{"checkout"}},
+		{query: "$.data.services[?@.errors == null].name", expectedValues: []any{"checkout"}},
+		{query: "$.data.services[?@.missing == @.other].name", expectedValues: []any{"checkout", "cart", "search"}},
+		{query: "$.data.services[?length(@.name) > 5].name", expectedValues: []any{"checkout", "search"}},
+		{query: "$.data.services[?match(@.name, 'c.*')].name", expectedValues: []any{"checkout", "cart"}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			parser, err := parseStandardJSONPath(test.query)
+			assert.NoError(t, err)
+			results, err := parser.FindResults(data)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValues, getValues(results))
+		})
+	}
+}
+
+func TestStandardJSONPathInvalid(t *testing.T) {
+	tests := []struct {
+		query         string
+		expectedError string
+	}{
+		{query: "{$.data}", expectedError: `invalid JSONPath "{$.data}": jsonpath: unexpected '{' at position 1`},
+		{query: "$.data[0", expectedError: `invalid JSONPath "$.data[0": jsonpath: unexpected eof at position 9`},
+		{query: "$.data.", expectedError: `invalid JSONPath "$.data.": jsonpath: unexpected eof at position 8`},
+		{query: "$.data['name]", expectedError: `invalid JSONPath "$.data['name]": jsonpath: unterminated string literal at position 14`},
+		{query: "$.data[?1]", expectedError: `invalid JSONPath "$.data[?1]": jsonpath: invalid comparison operator at position 10`},
+		{query: "$.data x", expectedError: `invalid JSONPath "$.data x": jsonpath: unexpected blank space at position 7`},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := parseStandardJSONPath(test.query)
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestJSONPathEngine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, jsonPathDocument)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name             string
+		engine           v1alpha1.WebMetricJSONPathEngine
+		jsonPath         string
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+	}{
+		{
+			name:             "kubernetes syntax with the default engine",
+			jsonPath:         "{$.data.services[?(@.status == 'ok')].latency}",
+			successCondition: "result == 80",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "80",
+		},
+		{
+			// outside of braces, the kubernetes engine selects the query as text, which is not comparable to a number
+			name:             "standard syntax with the kubernetes engine",
+			engine:           v1alpha1.WebMetricJSONPathEngineKubernetes,
+			jsonPath:         "$.data.threshold",
+			successCondition: "result == 100",
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+		},
+		{
+			name:             "standard syntax with the standard engine",
+			engine:           v1alpha1.WebMetricJSONPathEngineStandard,
+			jsonPath:         "$.data.threshold",
+			successCondition: "result == 100",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "100",
+		},
+		{
+			name:             "logical filter with the standard engine",
+			engine:           v1alpha1.WebMetricJSONPathEngineStandard,
+			jsonPath:         "$.data.services[?@.status == 'ok' && @.latency > $.data.threshold].name",
+			successCondition: "result == 'cart'",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `"cart"`,
+		},
+		{
+			name:             "whole body with the standard engine",
+			engine:           v1alpha1.WebMetricJSONPathEngineStandard,
+			successCondition: "result.data.threshold == 100",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:            server.URL,
+						JSONPath:       test.jsonPath,
+						JSONPathEngine: test.engine,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			if test.expectedValue != "" {
+				assert.Equal(t, test.expectedValue, measurement.Value)
+			}
+		})
+	}
+}
+
+func TestJSONPathEngineInvalid(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{JSONPath: "{$.data}", JSONPathEngine: v1alpha1.WebMetricJSONPathEngineStandard},
+		},
+	}
+	_, err := NewWebMetricJsonParser(metric)
+	assert.EqualError(t, err, `invalid JSONPath "{$.data}": jsonpath: unexpected '{' at position 1`)
+
+	metric.Provider.Web.JSONPathEngine = "jsonata"
+	_, err = NewWebMetricJsonParser(metric)
+	assert.EqualError(t, err, "unknown jsonPathEngine: jsonata")
+}
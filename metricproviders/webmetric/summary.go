@@ -0,0 +1,73 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
+)
+
+// The measurement metadata keys of the ValueSummary
+const (
+	ValuesCountMetadataKey = "valuesCount"
+	ValuesMinMetadataKey   = "valuesMin"
+	ValuesMaxMetadataKey   = "valuesMax"
+	ValuesMeanMetadataKey  = "valuesMean"
+)
+
+// valueSummary is the count, min, max and mean of the numeric values of an analysis run
+type valueSummary struct {
+	count         int64
+	min, max, sum float64
+}
+
+// addValueSummary adds the value of the measurement to the summary of the previous measurements of the metric, in the
+// metadata of the measurement. The summary is carried from measurement to measurement in their metadata, so that it
+// covers the measurements which are no longer retained in the analysis run
+func addValueSummary(metadata map[string]string, run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, value string) map[string]string {
+	summary := previousSummary(run, metric)
+	var number float64
+	if err := json.Unmarshal([]byte(value), &number); err == nil {
+		if summary.count == 0 || number < summary.min {
+			summary.min = number
+		}
+		if summary.count == 0 || number > summary.max {
+			summary.max = number
+		}
+		summary.sum += number
+		summary.count++
+	}
+	if summary.count == 0 {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata[ValuesCountMetadataKey] = strconv.FormatInt(summary.count, 10)
+	metadata[ValuesMinMetadataKey] = strconv.FormatFloat(summary.min, 'f', -1, 64)
+	metadata[ValuesMaxMetadataKey] = strconv.FormatFloat(summary.max, 'f', -1, 64)
+	metadata[ValuesMeanMetadataKey] = strconv.FormatFloat(summary.sum/float64(summary.count), 'f', -1, 64)
+	return metadata
+}
+
+// previousSummary returns the summary of the last measurement of the metric which has one, or an empty summary if
+// there is none
+func previousSummary(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) valueSummary {
+	measurements := analysisutil.ArrayMeasurement(run, metric.Name)
+	for i := len(measurements) - 1; i >= 0; i-- {
+		metadata := measurements[i].Metadata
+		count, err := strconv.ParseInt(metadata[ValuesCountMetadataKey], 10, 64)
+		if err != nil {
+			continue
+		}
+		minValue, minErr := strconv.ParseFloat(metadata[ValuesMinMetadataKey], 64)
+		maxValue, maxErr := strconv.ParseFloat(metadata[ValuesMaxMetadataKey], 64)
+		mean, meanErr := strconv.ParseFloat(metadata[ValuesMeanMetadataKey], 64)
+		if minErr != nil || maxErr != nil || meanErr != nil {
+			continue
+		}
+		return valueSummary{count: count, min: minValue, max: maxValue, sum: mean * float64(count)}
+	}
+	return valueSummary{}
+}
@@ -0,0 +1,92 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunWithValueSummary(t *testing.T) {
+	var value string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if value == "" {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"latency": %s}`, value)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:          server.URL,
+				JSONPath:     "{$.latency}",
+				ValueSummary: true,
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	run := newAnalysisRun()
+	run.Status.MetricResults = []v1alpha1.MetricResult{{Name: metric.Name}}
+	tests := []struct {
+		value            string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedMetadata map[string]string
+	}{
+		{
+			value:            "40",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedMetadata: map[string]string{"valuesCount": "1", "valuesMin": "40", "valuesMax": "40", "valuesMean": "40"},
+		},
+		{
+			value:            "120",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedMetadata: map[string]string{"valuesCount": "2", "valuesMin": "40", "valuesMax": "120", "valuesMean": "80"},
+		},
+		// a measurement without a value does not carry the summary
+		{
+			value:            "",
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMetadata: map[string]string{ErrorCauseMetadataKey: ErrorCauseStatusCode},
+		},
+		// a non numeric value is not summarized
+		{
+			value:            `"n/a"`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedMetadata: map[string]string{"valuesCount": "2", "valuesMin": "40", "valuesMax": "120", "valuesMean": "80"},
+		},
+		{
+			value:            "20.5",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedMetadata: map[string]string{"valuesCount": "3", "valuesMin": "20.5", "valuesMax": "120", "valuesMean": "60.166666666666664"},
+		},
+	}
+	for i, test := range tests {
+		value = test.value
+		measurement := provider.Run(run, metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, "measurement %d: %s", i, measurement.Message)
+		assert.Equal(t, test.expectedMetadata, measurement.Metadata, "measurement %d", i)
+		run.Status.MetricResults[0].Measurements = append(run.Status.MetricResults[0].Measurements, measurement)
+	}
+
+	// the summary covers the measurements which are no longer retained
+	run.Status.MetricResults[0].Measurements = run.Status.MetricResults[0].Measurements[4:]
+	value = "60"
+	measurement := provider.Run(run, metric)
+	assert.Equal(t, map[string]string{"valuesCount": "4", "valuesMin": "20.5", "valuesMax": "120", "valuesMean": "60.125"}, measurement.Metadata)
+}
@@ -0,0 +1,48 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// fetchThreshold fetches the ThresholdUrl of the metric with a GET request, with the headers and authentication of
+// the metric, and returns the threshold selected from its response
+func (p *Provider) fetchThreshold(metric v1alpha1.Metric) (any, error) {
+	parser, err := newJSONParser(metric.Provider.Web, "threshold", metric.Provider.Web.ThresholdJsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid thresholdJsonPath: %v", err)
+	}
+
+	response, err := p.fetch(withoutMeasurementRequest(metric), metric.Provider.Web.ThresholdUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("threshold request failed: %w", err)
+	}
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return nil, &parseError{err: fmt.Errorf("threshold response is not a JSON document: %v", err)}
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("Could not find thresholdJsonPath in body: %s", err)}
+	}
+	val, _, err := getValue(fullResults)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("threshold: %v", err)}
+	}
+	return val, nil
+}
+
+// withoutMeasurementRequest returns a copy of the metric sending GET requests with its headers and authentication, but
+// none of the method, body and response handling of the measurement request
+func withoutMeasurementRequest(metric v1alpha1.Metric) v1alpha1.Metric {
+	web := *metric.Provider.Web
+	web.Method = ""
+	web.JSONBody = nil
+	web.GRPCWeb = false
+	web.Location = nil
+	web.RetryCondition = ""
+	metric.Provider.Web = &web
+	return metric
+}
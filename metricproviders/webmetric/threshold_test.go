@@ -0,0 +1,100 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestThresholdURL(t *testing.T) {
+	tests := []struct {
+		name              string
+		thresholdStatus   int
+		thresholdResponse string
+		thresholdJSONPath string
+		expectedPhase     v1alpha1.AnalysisPhase
+		expectedMessage   string
+	}{
+		{
+			name:              "value below the threshold",
+			thresholdStatus:   http.StatusOK,
+			thresholdResponse: `{"slo": {"maxErrorRate": 0.05}}`,
+			thresholdJSONPath: "{$.slo.maxErrorRate}",
+			expectedPhase:     v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:              "value above the threshold",
+			thresholdStatus:   http.StatusOK,
+			thresholdResponse: `0.01`,
+			expectedPhase:     v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			name:            "threshold fetch failure",
+			thresholdStatus: http.StatusInternalServerError,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "threshold request failed: received non 2xx response code: 500",
+		},
+		{
+			name:              "threshold is not JSON",
+			thresholdStatus:   http.StatusOK,
+			thresholdResponse: `five percent`,
+			expectedPhase:     v1alpha1.AnalysisPhaseError,
+			expectedMessage:   "threshold response is not a JSON document: invalid character 'i' in literal false (expecting 'a')",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			thresholdServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Equal(t, "value", req.Header.Get("key"))
+				body, _ := io.ReadAll(req.Body)
+				assert.Empty(t, body)
+				if test.thresholdStatus != http.StatusOK {
+					rw.WriteHeader(test.thresholdStatus)
+					return
+				}
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.thresholdResponse)
+			}))
+			defer thresholdServer.Close()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, `{"errorRate": 0.02}`)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result <= threshold",
+				FailureCondition: "result > threshold",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:               server.URL,
+						Method:            v1alpha1.WebMetricMethodPost,
+						Body:              `{"service": "my-service"}`,
+						Headers:           []v1alpha1.WebMetricHeader{{Key: "key", Value: "value"}},
+						JSONPath:          "{$.errorRate}",
+						ThresholdUrl:      thresholdServer.URL,
+						ThresholdJsonPath: test.thresholdJSONPath,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package webmetric
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasttemplate"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const nowPlaceholder = "now"
+
+// Formats of the $(now) placeholders
+const (
+	timeFormatRFC3339 = "rfc3339"
+	timeFormatUnix    = "unix"
+	timeFormatUnixMs  = "unixms"
+)
+
+// resolveTimePlaceholders substitutes the $(now [offset] [format]) placeholders of the template with the time of the
+// measurement, shifted by the offset, e.g. $(now -5m unix) for the epoch seconds of five minutes ago. The time is
+// formatted as RFC3339 by default. Other placeholders are kept as is
+func resolveTimePlaceholders(template string, now time.Time, escape func(string) string) (string, error) {
+	if !strings.Contains(template, placeholderOpenBracket+nowPlaceholder) {
+		return template, nil
+	}
+	t, err := fasttemplate.NewTemplate(template, placeholderOpenBracket, placeholderCloseBracket)
+	if err != nil {
+		return "", err
+	}
+	var invalidErr error
+	resolved := t.ExecuteFuncString(func(w io.Writer, tag string) (int, error) {
+		fields := strings.Fields(tag)
+		if len(fields) == 0 || fields[0] != nowPlaceholder {
+			return w.Write([]byte(placeholderOpenBracket + tag + placeholderCloseBracket))
+		}
+		value, err := formatTimePlaceholder(now, fields[1:])
+		if err != nil {
+			invalidErr = fmt.Errorf("invalid %s%s%s placeholder: %v", placeholderOpenBracket, tag, placeholderCloseBracket, err)
+			return 0, nil
+		}
+		return w.Write([]byte(escape(value)))
+	})
+	return resolved, invalidErr
+}
+
+// formatTimePlaceholder returns the time shifted by the optional offset of the arguments, in their optional format
+func formatTimePlaceholder(now time.Time, args []string) (string, error) {
+	if len(args) > 0 && (strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[0], "+")) {
+		offset, err := time.ParseDuration(args[0])
+		if err != nil {
+			return "", err
+		}
+		now = now.Add(offset)
+		args = args[1:]
+	}
+	format := timeFormatRFC3339
+	if len(args) > 0 {
+		format = args[0]
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		return "", fmt.Errorf("unexpected %q", strings.Join(args, " "))
+	}
+	switch format {
+	case timeFormatRFC3339:
+		return now.UTC().Format(time.RFC3339), nil
+	case timeFormatUnix:
+		return strconv.FormatInt(now.Unix(), 10), nil
+	case timeFormatUnixMs:
+		return strconv.FormatInt(now.UnixMilli(), 10), nil
+	}
+	return "", fmt.Errorf("unknown format %q: it must be rfc3339, unix or unixms", format)
+}
+
+// resolveTimes returns a copy of the metric with the $(now) placeholders of its URLs substituted with the query escaped
+// time
+func resolveTimes(metric v1alpha1.Metric, now time.Time) (v1alpha1.Metric, error) {
+	return resolveURLs(metric, func(template string) (string, error) {
+		return resolveTimePlaceholders(template, now, url.QueryEscape)
+	})
+}
+
+// resolveBodyTimes substitutes the $(now) placeholders of the body with the time
+func resolveBodyTimes(body []byte, now time.Time) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	resolved, err := resolveTimePlaceholders(string(body), now, func(value string) string { return value })
+	return []byte(resolved), err
+}
@@ -0,0 +1,100 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestResolveTimePlaceholders(t *testing.T) {
+	now := time.Date(2024, 5, 6, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	tests := []struct {
+		template      string
+		expected      string
+		expectedError string
+	}{
+		{template: "$(now)", expected: "2024-05-06T10:30:00Z"},
+		{template: "$(now -5m)", expected: "2024-05-06T10:25:00Z"},
+		{template: "$(now +1h30m rfc3339)", expected: "2024-05-06T12:00:00Z"},
+		{template: "$(now unix )", expected: "1714991400"},
+		{template: "$(now -5m unix)", expected: "1714991100"},
+		{template: "$(now -1s unixms)", expected: "1714991399000"},
+		{template: "start=$(now -5m unix)&end=$(now unix)", expected: "start=1714991100&end=1714991400"},
+		{template: "$(pagination.cursor) $(nowhere)", expected: "$(pagination.cursor) $(nowhere)"},
+		{template: "$(now -5x)", expectedError: `invalid $(now -5x) placeholder: time: unknown unit "x" in duration "-5x"`},
+		{template: "$(now iso)", expectedError: `invalid $(now iso) placeholder: unknown format "iso": it must be rfc3339, unix or unixms`},
+		{template: "$(now -5m unix ms)", expectedError: `invalid $(now -5m unix ms) placeholder: unexpected "ms"`},
+	}
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			resolved, err := resolveTimePlaceholders(test.template, now, func(value string) string { return value })
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, resolved)
+		})
+	}
+}
+
+func TestRunWithTimeWindow(t *testing.T) {
+	var query url.Values
+	var body struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query = req.URL.Query()
+		bodyBytes, _ := io.ReadAll(req.Body)
+		assert.NoError(t, json.Unmarshal(bodyBytes, &body))
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"value": 0.99}`)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result > 0.9",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				Method:   v1alpha1.WebMetricMethodPost,
+				URL:      server.URL + "?from=$(now -5m unix)&to=$(now unix)",
+				Body:     `{"start": "$(now -5m)", "end": "$(now)"}`,
+				JSONPath: "{$.value}",
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+
+	start, err := time.Parse(time.RFC3339, body.Start)
+	assert.NoError(t, err)
+	end, err := time.Parse(time.RFC3339, body.End)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, end.Sub(start))
+	assert.WithinDuration(t, measurement.StartedAt.Time, end, time.Second)
+	assert.Equal(t, start.Unix(), mustParseInt(t, query.Get("from")))
+	assert.Equal(t, end.Unix(), mustParseInt(t, query.Get("to")))
+}
+
+func mustParseInt(t *testing.T, s string) int64 {
+	var i int64
+	assert.NoError(t, json.Unmarshal([]byte(s), &i))
+	return i
+}
@@ -0,0 +1,268 @@
+package webmetric
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+var (
+	tlsTransportsMu sync.Mutex
+	// tlsTransports are shared, like the transport of the clients without a TLS config, so their idle connections
+	// are reused between measurements
+	tlsTransports = map[tlsTransportKey]*http.Transport{}
+)
+
+// tlsTransportKey identifies the transports with the same TLS settings
+type tlsTransportKey struct {
+	pins          string
+	spkiHashes    string
+	serverName    string
+	insecure      bool
+	insecureHosts string
+	cipherSuites  string
+}
+
+// normalizePins returns the SHA-256 fingerprints in lower case hex, without separators
+func normalizePins(pins []string) ([]string, error) {
+	normalized := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		p := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(pin), ":", ""))
+		if decoded, err := hex.DecodeString(p); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid pinnedSHA256 fingerprint: %s", pin)
+		}
+		normalized = append(normalized, p)
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// normalizeSPKIHashes returns the SHA-256 hashes of the SubjectPublicKeyInfo in standard base64
+func normalizeSPKIHashes(hashes []string) ([]string, error) {
+	normalized := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		h := strings.TrimPrefix(strings.TrimSpace(hash), "sha256//")
+		if decoded, err := base64.StdEncoding.DecodeString(h); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid spkiSHA256 hash: %s", hash)
+		}
+		normalized = append(normalized, h)
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// parseCipherSuites returns the IDs of the TLS 1.2 cipher suites by their names, rejecting the insecure suites
+func parseCipherSuites(names []string) ([]uint16, error) {
+	suites := map[string]*tls.CipherSuite{}
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite
+	}
+	insecure := map[string]bool{}
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = true
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		suite, ok := suites[name]
+		switch {
+		case insecure[name]:
+			return nil, fmt.Errorf("insecure cipher suite in cipherSuites: %s", name)
+		case !ok:
+			return nil, fmt.Errorf("unknown cipher suite in cipherSuites: %s", name)
+		case !slices.Contains(suite.SupportedVersions, tls.VersionTLS12):
+			return nil, fmt.Errorf("cipher suite %s in cipherSuites is a TLS 1.3 suite, which cannot be configured", name)
+		}
+		ids = append(ids, suite.ID)
+	}
+	return ids, nil
+}
+
+// normalizeHosts returns the insecure host names in lower case, without a trailing dot
+func normalizeHosts(hosts []string) ([]string, error) {
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		h := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+		if h == "" || strings.ContainsAny(h, ":/") {
+			return nil, fmt.Errorf("invalid insecureHosts host: %q", host)
+		}
+		normalized = append(normalized, h)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// tlsTransport returns a transport with the TLS settings of the metric. The server name overrides the URL host for
+// SNI and the verification of the certificate. Pins trust the servers presenting a certificate with one of the
+// SHA-256 fingerprints, instead of the certificates signed by a trusted CA. The SPKI hashes further require a certificate
+// with one of the public keys, once the certificates are trusted. The certificates of the insecure hosts are not verified.
+// The cipher suites are the only ones offered, over TLS 1.2 at most
+func tlsTransport(tlsConfig *v1alpha1.WebMetricTLSConfig, insecure bool, insecureHosts []string) (*http.Transport, error) {
+	normalized, err := normalizePins(tlsConfig.PinnedSHA256)
+	if err != nil {
+		return nil, err
+	}
+	spkiHashes, err := normalizeSPKIHashes(tlsConfig.SPKISHA256)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := normalizeHosts(insecureHosts)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := parseCipherSuites(tlsConfig.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	if insecure {
+		// nothing is verified anyway
+		hosts = nil
+	}
+	key := tlsTransportKey{
+		pins:          strings.Join(normalized, ","),
+		spkiHashes:    strings.Join(spkiHashes, ","),
+		serverName:    tlsConfig.ServerName,
+		insecure:      insecure,
+		insecureHosts: strings.Join(hosts, ","),
+		cipherSuites:  fmt.Sprint(cipherSuites),
+	}
+
+	tlsTransportsMu.Lock()
+	defer tlsTransportsMu.Unlock()
+	if t, ok := tlsTransports[key]; ok {
+		return t, nil
+	}
+	t := transport.Clone()
+	t.TLSClientConfig = &tls.Config{
+		ServerName:         tlsConfig.ServerName,
+		InsecureSkipVerify: insecure,
+	}
+	if len(cipherSuites) > 0 {
+		// the TLS 1.3 cipher suites are always offered otherwise
+		t.TLSClientConfig.CipherSuites = cipherSuites
+		t.TLSClientConfig.MaxVersion = tls.VersionTLS12
+	}
+	if len(normalized) > 0 {
+		// the pins are verified instead of the chain of trust
+		t.TLSClientConfig.InsecureSkipVerify = true
+		t.TLSClientConfig.VerifyPeerCertificate = verifyPins(normalized)
+	}
+	if len(spkiHashes) > 0 {
+		// the public keys are verified after the certificates are trusted
+		t.TLSClientConfig.VerifyPeerCertificate = verifyAll(t.TLSClientConfig.VerifyPeerCertificate, verifySPKIHashes(spkiHashes))
+	}
+	if len(hosts) > 0 {
+		// the certificates are verified once the server name of the connection is known, which
+		// VerifyPeerCertificate is not given
+		t.TLSClientConfig.InsecureSkipVerify = true
+		t.TLSClientConfig.VerifyPeerCertificate = nil
+		t.TLSClientConfig.VerifyConnection = verifyUnlessInsecureHost(hosts, normalized, spkiHashes)
+	}
+	tlsTransports[key] = t
+	return t, nil
+}
+
+// verifyPins requires the leaf certificate of the server to have one of the SHA-256 fingerprints. The other
+// certificates the server presents are not verified, so appending a pinned certificate to another leaf does not pass
+func verifyPins(pins []string) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) > 0 {
+			fingerprint := sha256.Sum256(rawCerts[0])
+			if slices.Contains(pins, hex.EncodeToString(fingerprint[:])) {
+				return nil
+			}
+		}
+		return errors.New("the server certificate does not match the pinned SHA-256 fingerprints")
+	}
+}
+
+// verifyAll returns a verification of the peer certificates failing when any of the verifications fails
+func verifyAll(verifications ...func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		for _, verify := range verifications {
+			if verify == nil {
+				continue
+			}
+			if err := verify(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// verifySPKIHashes requires a certificate of a verified chain to have a public key with one of the SPKI hashes. Without
+// verified chains, e.g. for pinned or insecure connections, only the leaf certificate is trusted to be the server's, by
+// the handshake. Unlike a certificate fingerprint, the hash of a key survives the renewals of the certificate
+func verifySPKIHashes(hashes []string) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(verifiedChains) == 0 && len(rawCerts) > 0 {
+			leaf, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			verifiedChains = [][]*x509.Certificate{{leaf}}
+		}
+		for _, chain := range verifiedChains {
+			for _, cert := range chain {
+				hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+				if slices.Contains(hashes, base64.StdEncoding.EncodeToString(hash[:])) {
+					return nil
+				}
+			}
+		}
+		return errors.New("none of the server public keys matches the spkiSHA256 hashes")
+	}
+}
+
+// verifyUnlessInsecureHost skips the verification of the connections to the insecure hosts. The certificates of the
+// other servers are verified against the pins if any, or else against the system roots, like the default verification,
+// and then against the SPKI hashes if any
+func verifyUnlessInsecureHost(hosts, pins, spkiHashes []string) func(cs tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if slices.Contains(hosts, strings.TrimSuffix(strings.ToLower(cs.ServerName), ".")) {
+			return nil
+		}
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("the server presented no certificate")
+		}
+		rawCerts := make([][]byte, 0, len(cs.PeerCertificates))
+		for _, cert := range cs.PeerCertificates {
+			rawCerts = append(rawCerts, cert.Raw)
+		}
+		var verifiedChains [][]*x509.Certificate
+		if len(pins) > 0 {
+			if err := verifyPins(pins)(rawCerts, nil); err != nil {
+				return err
+			}
+		} else {
+			intermediates := x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			chains, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				DNSName:       cs.ServerName,
+				Intermediates: intermediates,
+			})
+			if err != nil {
+				return err
+			}
+			verifiedChains = chains
+		}
+		if len(spkiHashes) > 0 {
+			return verifySPKIHashes(spkiHashes)(rawCerts, verifiedChains)
+		}
+		return nil
+	}
+}
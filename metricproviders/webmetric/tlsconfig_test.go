@@ -0,0 +1,562 @@
+package webmetric
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestPinnedSHA256(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+	pin := hex.EncodeToString(fingerprint[:])
+	otherPin := strings.Repeat("ab", sha256.Size)
+
+	tests := []struct {
+		name            string
+		tlsConfig       *v1alpha1.WebMetricTLSConfig
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMessage string
+	}{
+		{
+			name:          "matching pin",
+			tlsConfig:     &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{otherPin, pin}},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "matching pin with colons in upper case",
+			tlsConfig:     &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{colonSeparated(strings.ToUpper(pin))}},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:            "non matching pin",
+			tlsConfig:       &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{otherPin}},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "the server certificate does not match the pinned SHA-256 fingerprints",
+		},
+		{
+			name:            "no pins verifies the chain of trust",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "certificate signed by unknown authority",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:       server.URL,
+						JSONPath:  "{$.ok}",
+						TLSConfig: test.tlsConfig,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Contains(t, measurement.Message, test.expectedMessage)
+		})
+	}
+}
+
+func TestPinnedSHA256Invalid(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:       "https://example.com",
+				TLSConfig: &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{"abcd"}},
+			},
+		},
+	}
+	_, err := NewWebMetricHttpClient(metric)
+	assert.EqualError(t, err, "invalid pinnedSHA256 fingerprint: abcd")
+}
+
+func TestSPKISHA256(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	spkiHash := base64.StdEncoding.EncodeToString(hash[:])
+	otherHash := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+	pin := hex.EncodeToString(fingerprint[:])
+
+	tests := []struct {
+		name            string
+		insecure        bool
+		tlsConfig       *v1alpha1.WebMetricTLSConfig
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedMessage string
+	}{
+		{
+			name:          "matching hash of a trusted certificate",
+			tlsConfig:     &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{pin}, SPKISHA256: []string{otherHash, spkiHash}},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "matching hash with the sha256 prefix",
+			insecure:      true,
+			tlsConfig:     &v1alpha1.WebMetricTLSConfig{SPKISHA256: []string{"sha256//" + spkiHash}},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:            "non matching hash of a trusted certificate",
+			tlsConfig:       &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{pin}, SPKISHA256: []string{otherHash}},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "none of the server public keys matches the spkiSHA256 hashes",
+		},
+		{
+			name:            "non matching hash without verification",
+			insecure:        true,
+			tlsConfig:       &v1alpha1.WebMetricTLSConfig{SPKISHA256: []string{otherHash}},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "none of the server public keys matches the spkiSHA256 hashes",
+		},
+		{
+			name:            "the hashes do not replace the chain of trust",
+			tlsConfig:       &v1alpha1.WebMetricTLSConfig{SPKISHA256: []string{spkiHash}},
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "certificate signed by unknown authority",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:       server.URL,
+						JSONPath:  "{$.ok}",
+						Insecure:  test.insecure,
+						TLSConfig: test.tlsConfig,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Contains(t, measurement.Message, test.expectedMessage)
+		})
+	}
+}
+
+func TestSPKISHA256Invalid(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:       "https://example.com",
+				TLSConfig: &v1alpha1.WebMetricTLSConfig{SPKISHA256: []string{"abcd"}},
+			},
+		},
+	}
+	_, err := NewWebMetricHttpClient(metric)
+	assert.EqualError(t, err, "invalid spkiSHA256 hash: abcd")
+}
+
+func colonSeparated(s string) string {
+	pairs := make([]string, 0, len(s)/2)
+	for i := 0; i < len(s); i += 2 {
+		pairs = append(pairs, s[i:i+2])
+	}
+	return strings.Join(pairs, ":")
+}
+
+func TestTLSServerName(t *testing.T) {
+	var serverName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+
+	tests := []struct {
+		name               string
+		tlsConfig          *v1alpha1.WebMetricTLSConfig
+		insecure           bool
+		expectedServerName string
+	}{
+		{
+			name:               "server name with insecure",
+			tlsConfig:          &v1alpha1.WebMetricTLSConfig{ServerName: "metrics.example.com"},
+			insecure:           true,
+			expectedServerName: "metrics.example.com",
+		},
+		{
+			name: "server name with pins",
+			tlsConfig: &v1alpha1.WebMetricTLSConfig{
+				ServerName:   "example.com",
+				PinnedSHA256: []string{hex.EncodeToString(fingerprint[:])},
+			},
+			expectedServerName: "example.com",
+		},
+		{
+			name:      "no server name is sent for an IP address",
+			tlsConfig: &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{hex.EncodeToString(fingerprint[:])}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serverName = ""
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:       server.URL,
+						JSONPath:  "{$.ok}",
+						Insecure:  test.insecure,
+						TLSConfig: test.tlsConfig,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedServerName, serverName)
+		})
+	}
+}
+
+func TestInsecureHosts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+
+	tests := []struct {
+		name          string
+		insecureHosts []string
+		pins          []string
+		expectedPhase v1alpha1.AnalysisPhase
+		expectedError string
+	}{
+		{
+			name:          "listed host",
+			insecureHosts: []string{"internal.example.org", "Example.COM."},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "host not listed is verified",
+			insecureHosts: []string{"internal.example.org"},
+			expectedPhase: v1alpha1.AnalysisPhaseError,
+			expectedError: "certificate signed by unknown authority",
+		},
+		{
+			name:          "host not listed is verified against the pins",
+			insecureHosts: []string{"internal.example.org"},
+			pins:          []string{hex.EncodeToString(fingerprint[:])},
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			name:          "host not listed does not match the pins",
+			insecureHosts: []string{"internal.example.org"},
+			pins:          []string{strings.Repeat("0", 64)},
+			expectedPhase: v1alpha1.AnalysisPhaseError,
+			expectedError: "the server certificate does not match the pinned SHA-256 fingerprints",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:           server.URL,
+						JSONPath:      "{$.ok}",
+						InsecureHosts: test.insecureHosts,
+						// the certificate of the test server is valid for example.com
+						TLSConfig: &v1alpha1.WebMetricTLSConfig{ServerName: "example.com", PinnedSHA256: test.pins},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Contains(t, measurement.Message, test.expectedError)
+		})
+	}
+}
+
+func TestInvalidInsecureHosts(t *testing.T) {
+	metric := v1alpha1.Metric{
+		Name: "foo",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:           "https://example.com",
+				InsecureHosts: []string{"example.com:443"},
+			},
+		},
+	}
+	_, err := NewWebMetricHttpClient(metric)
+	assert.EqualError(t, err, `invalid insecureHosts host: "example.com:443"`)
+}
+
+func TestTLSCipherSuites(t *testing.T) {
+	var offered []uint16
+	var maxVersion uint16
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			offered = hello.CipherSuites
+			maxVersion = slices.Max(hello.SupportedVersions)
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result == true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.ok}",
+				Insecure: true,
+				TLSConfig: &v1alpha1.WebMetricTLSConfig{
+					CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+				},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	// the order of the suites is chosen by crypto/tls
+	assert.ElementsMatch(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, offered)
+	assert.Equal(t, uint16(tls.VersionTLS12), maxVersion)
+}
+
+func TestTLSCipherSuitesInvalid(t *testing.T) {
+	tests := []struct {
+		cipherSuite          string
+		expectedErrorMessage string
+	}{
+		{
+			cipherSuite:          "TLS_FOO_WITH_BAR",
+			expectedErrorMessage: "unknown cipher suite in cipherSuites: TLS_FOO_WITH_BAR",
+		},
+		{
+			cipherSuite:          "TLS_RSA_WITH_RC4_128_SHA",
+			expectedErrorMessage: "insecure cipher suite in cipherSuites: TLS_RSA_WITH_RC4_128_SHA",
+		},
+		{
+			cipherSuite:          "TLS_AES_128_GCM_SHA256",
+			expectedErrorMessage: "cipher suite TLS_AES_128_GCM_SHA256 in cipherSuites is a TLS 1.3 suite, which cannot be configured",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.cipherSuite, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name: "foo",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:       "https://example.com",
+						TLSConfig: &v1alpha1.WebMetricTLSConfig{CipherSuites: []string{test.cipherSuite}},
+					},
+				},
+			}
+			_, err := NewWebMetricHttpClient(metric)
+			assert.EqualError(t, err, test.expectedErrorMessage)
+		})
+	}
+}
+
+// newAppendedCertServer starts a TLS server presenting a leaf certificate of its own followed by a legitimate
+// certificate it has no key for, which it returns
+func newAppendedCertServer(t *testing.T) (*httptest.Server, *x509.Certificate) {
+	newCert := func(name string) ([]byte, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: name},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		assert.NoError(t, err)
+		return der, key
+	}
+	legitimate, _ := newCert("legitimate")
+	leaf, leafKey := newCert("attacker")
+	legitimateCert, err := x509.ParseCertificate(legitimate)
+	assert.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{leaf, legitimate}, PrivateKey: leafKey}},
+	}
+	server.StartTLS()
+	return server, legitimateCert
+}
+
+func TestPinnedSHA256AppendedCertificate(t *testing.T) {
+	server, legitimate := newAppendedCertServer(t)
+	defer server.Close()
+	fingerprint := sha256.Sum256(legitimate.Raw)
+
+	for _, insecureHosts := range [][]string{nil, {"internal.example.org"}} {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result == true",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:           server.URL,
+					JSONPath:      "{$.ok}",
+					InsecureHosts: insecureHosts,
+					TLSConfig:     &v1alpha1.WebMetricTLSConfig{PinnedSHA256: []string{hex.EncodeToString(fingerprint[:])}},
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase, measurement.Message)
+		assert.Contains(t, measurement.Message, "the server certificate does not match the pinned SHA-256 fingerprints")
+	}
+}
+
+func TestSPKISHA256AppendedCertificate(t *testing.T) {
+	server, legitimate := newAppendedCertServer(t)
+	defer server.Close()
+	hash := sha256.Sum256(legitimate.RawSubjectPublicKeyInfo)
+	spkiHash := base64.StdEncoding.EncodeToString(hash[:])
+	leafFingerprint := sha256.Sum256(server.TLS.Certificates[0].Certificate[0])
+	leafPin := hex.EncodeToString(leafFingerprint[:])
+
+	tests := []struct {
+		name          string
+		insecure      bool
+		insecureHosts []string
+		pins          []string
+	}{
+		{name: "insecure", insecure: true},
+		{name: "pinned leaf", pins: []string{leafPin}},
+		{name: "pinned leaf of a host not listed as insecure", insecureHosts: []string{"internal.example.org"}, pins: []string{leafPin}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:           server.URL,
+						JSONPath:      "{$.ok}",
+						Insecure:      test.insecure,
+						InsecureHosts: test.insecureHosts,
+						TLSConfig:     &v1alpha1.WebMetricTLSConfig{PinnedSHA256: test.pins, SPKISHA256: []string{spkiHash}},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase, measurement.Message)
+			assert.Contains(t, measurement.Message, "none of the server public keys matches the spkiSHA256 hashes")
+		})
+	}
+}
+
+func TestVerifySPKIHashesVerifiedChains(t *testing.T) {
+	server, legitimate := newAppendedCertServer(t)
+	defer server.Close()
+	hash := sha256.Sum256(legitimate.RawSubjectPublicKeyInfo)
+	verify := verifySPKIHashes([]string{base64.StdEncoding.EncodeToString(hash[:])})
+	rawCerts := server.TLS.Certificates[0].Certificate
+	leaf, err := x509.ParseCertificate(rawCerts[0])
+	assert.NoError(t, err)
+
+	// the appended certificate is presented, but is not part of the verified chain
+	assert.EqualError(t, verify(rawCerts, [][]*x509.Certificate{{leaf}}), "none of the server public keys matches the spkiSHA256 hashes")
+	assert.NoError(t, verify(rawCerts, [][]*x509.Certificate{{leaf}, {leaf, legitimate}}))
+}
@@ -0,0 +1,44 @@
+package webmetric
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const tracerName = "github.com/argoproj/argo-rollouts/metricproviders/webmetric"
+
+// startSpan starts the span of a web metric request with the registered tracer provider, as a child of the span of
+// the request context if any. The trace context is propagated to the web metric endpoint in the traceparent header
+func startSpan(metric v1alpha1.Metric, request *http.Request) (*http.Request, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(request.Context(), "webmetric "+request.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(request.Method),
+			semconv.URLFull(redactCredentialsString(request.URL.String(), metric.Provider.Web)),
+		),
+	)
+	request = request.WithContext(ctx)
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(request.Header))
+	return request, span
+}
+
+// endSpan records the outcome of a web metric request and ends its span
+func endSpan(span trace.Span, response *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+		}
+	}
+	span.End()
+}
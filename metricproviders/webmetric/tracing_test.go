@@ -0,0 +1,118 @@
+package webmetric
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunIsTraced(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	defaultProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(defaultProvider)
+
+	var traceContexts []trace.SpanContext
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(req.Header))
+		traceContexts = append(traceContexts, trace.SpanContextFromContext(ctx))
+		if req.URL.Path == "/error" {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/ok", "/error"} {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL: server.URL + path + "?service=checkout",
+					Authentication: v1alpha1.Authentication{
+						APIKey: &v1alpha1.APIKeyConfig{Key: "secret", In: v1alpha1.APIKeyLocationQuery, Name: "api_key"},
+					},
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+		provider.Run(newAnalysisRun(), metric)
+	}
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	for i, span := range spans {
+		assert.Equal(t, "webmetric GET", span.Name)
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+		// the trace context of the span is injected in the request
+		assert.True(t, traceContexts[i].IsValid())
+		assert.Equal(t, span.SpanContext.TraceID(), traceContexts[i].TraceID())
+		assert.Equal(t, span.SpanContext.SpanID(), traceContexts[i].SpanID())
+
+		attributes := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes {
+			attributes[kv.Key] = kv.Value
+		}
+		assert.Equal(t, "GET", attributes["http.request.method"].AsString())
+		assert.Contains(t, attributes["url.full"].AsString(), "service=checkout")
+		assert.Contains(t, attributes["url.full"].AsString(), "api_key=*****")
+		assert.NotContains(t, attributes["url.full"].AsString(), "secret")
+	}
+	assert.EqualValues(t, 200, spanAttribute(spans[0], "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.EqualValues(t, 500, spanAttribute(spans[1], "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
+
+func TestRunWithParentSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	defaultProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(defaultProvider)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "analysis")
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://metrics.test", nil)
+	assert.NoError(t, err)
+	metric := v1alpha1.Metric{Provider: v1alpha1.MetricProvider{Web: &v1alpha1.WebMetric{}}}
+	request, span := startSpan(metric, request)
+	endSpan(span, &http.Response{StatusCode: http.StatusOK}, nil)
+	parent.End()
+
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	assert.NotEmpty(t, request.Header.Get("traceparent"))
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
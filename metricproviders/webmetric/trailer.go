@@ -0,0 +1,54 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/evaluate"
+)
+
+// parseTrailerResponse evaluates the value selected by the TrailerPath in the trailers of the response
+func (p *Provider) parseTrailerResponse(metric v1alpha1.Metric, response *webResponse, previous any) (string, v1alpha1.AnalysisPhase, error) {
+	trailerParser := jsonpath.New("trailer")
+	if err := trailerParser.Parse(metric.Provider.Web.TrailerPath); err != nil {
+		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("invalid trailerPath: %v", err)
+	}
+	trailers := trailerValues(response.trailer)
+	fullResults, err := trailerParser.FindResults(trailers)
+	if err != nil {
+		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("Could not find trailerPath in trailers: %s", err)
+	}
+	val, valString, err := getValue(fullResults)
+	if err != nil {
+		return "", v1alpha1.AnalysisPhaseError, err
+	}
+
+	vars := map[string]any{
+		"statusCode":     response.statusCode,
+		"responseTimeMs": response.duration.Milliseconds(),
+		"responseBytes":  len(response.body),
+		"trailers":       trailers,
+		"previous":       previous,
+	}
+	status, err := evaluate.EvaluateResultWithVars(val, vars, metric, p.logCtx)
+	return valString, status, asEvaluationError(err)
+}
+
+// trailerValues returns the first value of each trailer by canonical name, decoded as JSON when valid
+func trailerValues(trailer http.Header) map[string]any {
+	values := make(map[string]any, len(trailer))
+	for key := range trailer {
+		value := trailer.Get(key)
+		var decoded any
+		if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+			values[key] = decoded
+		} else {
+			values[key] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,83 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestTrailerPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/grpc-web+proto")
+		rw.Header().Set("Trailer", "X-Metric-Value, Grpc-Status, Grpc-Message")
+		rw.WriteHeader(http.StatusOK)
+		// the body is not JSON
+		io.WriteString(rw, "\x00\x00\x00\x00\x02\x08\x01")
+		rw.Header().Set("X-Metric-Value", "0.97")
+		rw.Header().Set("Grpc-Status", "0")
+		rw.Header().Set("Grpc-Message", "OK")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name             string
+		trailerPath      string
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedMessage  string
+	}{
+		{
+			name:             "numeric trailer",
+			trailerPath:      "{$.X-Metric-Value}",
+			successCondition: "result >= 0.95",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "0.97",
+		},
+		{
+			name:             "string trailer with the other trailers in the condition",
+			trailerPath:      "{$.Grpc-Message}",
+			successCondition: "result == 'OK' && trailers['Grpc-Status'] == 0",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `"OK"`,
+		},
+		{
+			name:             "missing trailer",
+			trailerPath:      "{$.X-Other}",
+			successCondition: "true",
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMessage:  "Could not find trailerPath in trailers: X-Other is not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:         server.URL,
+						TrailerPath: test.trailerPath,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// transformEnv declares the variables of the transform expressions: the selected value, the parsed body, the status
+// code, the response time, the size of the body and the first value of each response header
+var transformEnv, transformEnvErr = cel.NewEnv(
+	cel.Variable("result", cel.DynType),
+	cel.Variable("body", cel.DynType),
+	cel.Variable("statusCode", cel.IntType),
+	cel.Variable("responseTimeMs", cel.IntType),
+	cel.Variable("responseBytes", cel.IntType),
+	cel.Variable("headers", cel.MapType(cel.StringType, cel.StringType)),
+)
+
+// transform evaluates the CEL transform expression and returns its output as a JSON value
+func transform(expression string, result, body any, response *webResponse) (any, string, error) {
+	if transformEnvErr != nil {
+		return nil, "", transformEnvErr
+	}
+	ast, issues := transformEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, "", fmt.Errorf("invalid transform expression: %v", issues.Err())
+	}
+	program, err := transformEnv.Program(ast)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid transform expression: %v", err)
+	}
+	out, _, err := program.Eval(map[string]any{
+		"result":         result,
+		"body":           body,
+		"statusCode":     response.statusCode,
+		"responseTimeMs": response.duration.Milliseconds(),
+		"responseBytes":  len(response.body),
+		"headers":        firstHeaderValues(response.header),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("could not evaluate transform expression: %v", err)
+	}
+
+	native, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, "", fmt.Errorf("transform expression must produce a JSON value: %v", err)
+	}
+	val := native.(*structpb.Value).AsInterface()
+	valBytes, err := json.Marshal(val)
+	return val, string(valBytes), err
+}
+
+func firstHeaderValues(header http.Header) map[string]string {
+	values := make(map[string]string, len(header))
+	for key := range header {
+		values[key] = header.Get(key)
+	}
+	return values
+}
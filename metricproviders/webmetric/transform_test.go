@@ -0,0 +1,102 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestTransform(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("X-Region", "eu-west-1")
+		io.WriteString(rw, `{"latency": {"p99": 1250}, "requests": 200, "errors": 5, "status": {"name": "healthy"}}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name             string
+		jsonPath         string
+		transform        string
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedMessage  string
+	}{
+		{
+			name:             "unit conversion",
+			transform:        "body.latency.p99 / 1000.0",
+			successCondition: "result < 2",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "1.25",
+		},
+		{
+			name:             "arithmetic on several fields",
+			transform:        "body.errors / body.requests * 100.0",
+			successCondition: "result < 1",
+			expectedPhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedValue:    "2.5",
+		},
+		{
+			name:             "field selection of the selected value",
+			jsonPath:         "{$.status}",
+			transform:        "result.name",
+			successCondition: "result == 'healthy'",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `"healthy"`,
+		},
+		{
+			name:             "response variables",
+			transform:        `{"ok": statusCode == 200 && headers["X-Region"] == "eu-west-1", "fast": responseTimeMs < 10000}`,
+			successCondition: "result.ok && result.fast",
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `{"fast":true,"ok":true}`,
+		},
+		{
+			name:             "invalid expression",
+			transform:        "body.latency.p99 +",
+			successCondition: "true",
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMessage:  "invalid transform expression",
+		},
+		{
+			name:             "evaluation error",
+			transform:        "body.missing * 2.0",
+			successCondition: "true",
+			expectedPhase:    v1alpha1.AnalysisPhaseError,
+			expectedMessage:  "could not evaluate transform expression: no such key: missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: test.successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:       server.URL,
+						JSONPath:  test.jsonPath,
+						Transform: test.transform,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Contains(t, measurement.Message, test.expectedMessage)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package webmetric
+
+import (
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// ValidateMetrics validates the settings of the web metrics which can be checked before they are measured, so an
+// invalid metric errors its analysis run at once instead of each of its measurements
+func ValidateMetrics(metrics []v1alpha1.Metric) error {
+	for i, metric := range metrics {
+		if err := validateMetric(metric); err != nil {
+			return fmt.Errorf("metrics[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func validateMetric(metric v1alpha1.Metric) error {
+	if metric.Provider.Web == nil {
+		return nil
+	}
+	if metric.Provider.Web.RetryOnInconclusive != nil {
+		if _, err := retryOnInconclusiveDelay(metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package webmetric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestValidateMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		timeoutSeconds int64
+		retry          *v1alpha1.WebMetricRetryOnInconclusive
+		expectedError  string
+	}{
+		{
+			name: "without retries",
+		},
+		{
+			name:           "retries within the timeout",
+			timeoutSeconds: 60,
+			retry:          &v1alpha1.WebMetricRetryOnInconclusive{Count: 3, Delay: "10s"},
+		},
+		{
+			name:           "retries beyond the timeout",
+			timeoutSeconds: 30,
+			retry:          &v1alpha1.WebMetricRetryOnInconclusive{Count: 3, Delay: "10s"},
+			expectedError:  "metrics[0]: retryOnInconclusive delays of 30s must add up to less than the timeoutSeconds of 30s",
+		},
+		{
+			name:  "retries within the default timeout",
+			retry: &v1alpha1.WebMetricRetryOnInconclusive{Count: 3, Delay: "3s"},
+		},
+		{
+			name:          "retries beyond the default timeout",
+			retry:         &v1alpha1.WebMetricRetryOnInconclusive{Count: 2, Delay: "5s"},
+			expectedError: "metrics[0]: retryOnInconclusive delays of 10s must add up to less than the timeoutSeconds of 10s",
+		},
+		{
+			name:          "invalid delay",
+			retry:         &v1alpha1.WebMetricRetryOnInconclusive{Count: 1, Delay: "soon"},
+			expectedError: `metrics[0]: invalid retryOnInconclusive delay: time: invalid duration "soon"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := []v1alpha1.Metric{{
+				Name: "foo",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{TimeoutSeconds: test.timeoutSeconds, RetryOnInconclusive: test.retry},
+				},
+			}}
+			err := ValidateMetrics(metrics)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
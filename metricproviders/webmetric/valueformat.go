@@ -0,0 +1,48 @@
+package webmetric
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+const (
+	// RawValueMetadataKey is the measurement metadata key holding the value of a measurement before its ValueFormat
+	RawValueMetadataKey = "rawValue"
+
+	// percentValueFormat displays a ratio as a percentage, e.g. 0.0234 as 2.34%
+	percentValueFormat = "percent"
+)
+
+// formatValue returns the numeric value formatted with the ValueFormat of the metric for display. Values which are not
+// numbers are returned as is
+func formatValue(web *v1alpha1.WebMetric, value string) (string, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value, nil
+	}
+	if web.ValueFormat == percentValueFormat {
+		return strconv.FormatFloat(number*100, 'f', 2, 64) + "%", nil
+	}
+	formatted := fmt.Sprintf(web.ValueFormat, number)
+	if strings.Contains(formatted, "%!") {
+		return "", fmt.Errorf("invalid valueFormat %q: it must be %s or format a single number", web.ValueFormat, percentValueFormat)
+	}
+	return formatted, nil
+}
+
+// withFormattedValue returns the value formatted for display, keeping the raw value in the metadata so that the next
+// measurements evaluate it rather than the formatted one
+func withFormattedValue(web *v1alpha1.WebMetric, value string, metadata map[string]string) (string, map[string]string, error) {
+	formatted, err := formatValue(web, value)
+	if err != nil || formatted == value {
+		return value, metadata, err
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata[RawValueMetadataKey] = value
+	return formatted, metadata, nil
+}
@@ -0,0 +1,118 @@
+package webmetric
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestRunWithValueFormat(t *testing.T) {
+	tests := []struct {
+		name             string
+		valueFormat      string
+		successCondition string
+		response         string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedRawValue string
+		expectedMessage  string
+	}{
+		{
+			name:             "percent",
+			valueFormat:      "percent",
+			response:         `{"errorRate": 0.0234}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "2.34%",
+			expectedRawValue: "0.0234",
+		},
+		{
+			name:             "the raw value is evaluated",
+			valueFormat:      "percent",
+			response:         `{"errorRate": 0.07}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedValue:    "7.00%",
+			expectedRawValue: "0.07",
+		},
+		{
+			name:             "printf format",
+			valueFormat:      "%.3f errors/s",
+			response:         `{"errorRate": 0.0234}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    "0.023 errors/s",
+			expectedRawValue: "0.0234",
+		},
+		{
+			name:            "invalid format",
+			valueFormat:     "%.2f / %.2f",
+			response:        `{"errorRate": 0.0234}`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: `invalid valueFormat "%.2f / %.2f": it must be percent or format a single number`,
+		},
+		{
+			name:             "values which are not numbers are not formatted",
+			valueFormat:      "percent",
+			successCondition: `result == "none"`,
+			response:         `{"errorRate": "none"}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `"none"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result < 0.05",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:         server.URL,
+						JSONPath:    "{$.errorRate}",
+						ValueFormat: test.valueFormat,
+					},
+				},
+			}
+			if test.successCondition != "" {
+				metric.SuccessCondition = test.successCondition
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			if test.expectedRawValue == "" {
+				assert.NotContains(t, measurement.Metadata, RawValueMetadataKey)
+			} else {
+				assert.Equal(t, test.expectedRawValue, measurement.Metadata[RawValueMetadataKey])
+			}
+		})
+	}
+}
+
+func TestPreviousValueOfFormattedValue(t *testing.T) {
+	run := newAnalysisRun()
+	run.Status.MetricResults = []v1alpha1.MetricResult{{
+		Name: "foo",
+		Measurements: []v1alpha1.Measurement{{
+			Value:    "2.34%",
+			Metadata: map[string]string{RawValueMetadataKey: "0.0234"},
+		}},
+	}}
+	assert.Equal(t, 0.0234, previousValue(run, v1alpha1.Metric{Name: "foo"}))
+}
@@ -0,0 +1,80 @@
+package webmetric
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// failurePayload is the default body of the OnFailureWebhook request
+type failurePayload struct {
+	Metric      string                 `json:"metric"`
+	Phase       v1alpha1.AnalysisPhase `json:"phase"`
+	Value       string                 `json:"value,omitempty"`
+	Message     string                 `json:"message,omitempty"`
+	AnalysisRun string                 `json:"analysisRun,omitempty"`
+	Namespace   string                 `json:"namespace,omitempty"`
+}
+
+// notifyFailure posts the failed measurement to the OnFailureWebhook of the metric. Like the HTTPMeasurementSink, it
+// does not use the client of the metric, so the credentials of the metric are not sent to the webhook. Errors are only
+// logged since the notification must not affect the measurement
+func (p *Provider) notifyFailure(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) {
+	webhook := metric.Provider.Web.OnFailureWebhook
+	body, err := failureWebhookBody(webhook.Body, run, metric, measurement)
+	if err != nil {
+		p.logCtx.Warnf("Failed to render the failure webhook body: %v", err)
+		return
+	}
+
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, strings.NewReader(body))
+	if err != nil {
+		p.logCtx.Warnf("Failed to create the failure webhook request: %v", err)
+		return
+	}
+	request.Header.Set(ContentTypeKey, ContentTypeJsonValue)
+	for _, header := range webhook.Headers {
+		request.Header.Set(header.Key, header.Value)
+	}
+
+	// the webhook is another endpoint than the metric: the credentials and TLS settings of the metric do not apply
+	client := &http.Client{Timeout: p.client.Timeout, Transport: transport}
+	response, err := client.Do(request)
+	if err != nil {
+		p.logCtx.Warnf("Failed to notify the failure webhook: %v", err)
+		return
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		p.logCtx.Warnf("Failure webhook returned non 2xx response code: %v", response.StatusCode)
+	}
+}
+
+// failureWebhookBody renders the webhook body template, or the default JSON payload when no template is set
+func failureWebhookBody(template string, run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) (string, error) {
+	payload := failurePayload{
+		Metric:      metric.Name,
+		Phase:       measurement.Phase,
+		Value:       measurement.Value,
+		Message:     measurement.Message,
+		AnalysisRun: run.Name,
+		Namespace:   run.Namespace,
+	}
+	if template == "" {
+		bodyBytes, err := json.Marshal(payload)
+		return string(bodyBytes), err
+	}
+
+	return resolvePlaceholders(template, map[string]string{
+		"metric.name":           payload.Metric,
+		"measurement.phase":     string(payload.Phase),
+		"measurement.value":     payload.Value,
+		"measurement.message":   payload.Message,
+		"analysisRun.name":      payload.AnalysisRun,
+		"analysisRun.namespace": payload.Namespace,
+	})
+}
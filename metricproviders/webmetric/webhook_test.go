@@ -0,0 +1,181 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestOnFailureWebhook(t *testing.T) {
+	tests := []struct {
+		name               string
+		webServerStatus    int
+		webServerResponse  string
+		webhookStatus      int
+		body               string
+		expectedPhase      v1alpha1.AnalysisPhase
+		expectedValue      string
+		expectedNotified   bool
+		expectedNotifyBody string
+	}{
+		{
+			name:              "success does not notify",
+			webServerStatus:   200,
+			webServerResponse: `{"ok": true}`,
+			webhookStatus:     200,
+			expectedPhase:     v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:     "true",
+		},
+		{
+			name:               "failure notifies with the default payload",
+			webServerStatus:    200,
+			webServerResponse:  `{"ok": false}`,
+			webhookStatus:      200,
+			expectedPhase:      v1alpha1.AnalysisPhaseFailed,
+			expectedValue:      "false",
+			expectedNotified:   true,
+			expectedNotifyBody: `{"metric":"foo","phase":"Failed","value":"false","analysisRun":"run","namespace":"ns"}`,
+		},
+		{
+			name:               "error notifies with the templated payload",
+			webServerStatus:    500,
+			webhookStatus:      200,
+			body:               `{"text": "$(analysisRun.namespace)/$(analysisRun.name) $(metric.name): $(measurement.phase) - $( measurement.message )"}`,
+			expectedPhase:      v1alpha1.AnalysisPhaseError,
+			expectedNotified:   true,
+			expectedNotifyBody: `{"text": "ns/run foo: Error - received non 2xx response code: 500"}`,
+		},
+		{
+			name:               "webhook failure does not affect the measurement",
+			webServerStatus:    200,
+			webServerResponse:  `{"ok": false}`,
+			webhookStatus:      500,
+			expectedPhase:      v1alpha1.AnalysisPhaseFailed,
+			expectedValue:      "false",
+			expectedNotified:   true,
+			expectedNotifyBody: `{"metric":"foo","phase":"Failed","value":"false","analysisRun":"run","namespace":"ns"}`,
+		},
+		{
+			name:              "unresolved placeholder does not affect the measurement",
+			webServerStatus:   200,
+			webServerResponse: `{"ok": false}`,
+			webhookStatus:     200,
+			body:              `$(unknown)`,
+			expectedPhase:     v1alpha1.AnalysisPhaseFailed,
+			expectedValue:     "false",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notified := false
+			var notifyBody string
+			webhook := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				notified = true
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, ContentTypeJsonValue, req.Header.Get(ContentTypeKey))
+				assert.Equal(t, "value", req.Header.Get("key"))
+				bodyBytes, _ := io.ReadAll(req.Body)
+				notifyBody = string(bodyBytes)
+				rw.WriteHeader(test.webhookStatus)
+			}))
+			defer webhook.Close()
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if test.webServerStatus != 200 {
+					http.Error(rw, http.StatusText(test.webServerStatus), test.webServerStatus)
+					return
+				}
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.webServerResponse)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result == true",
+				FailureCondition: "result == false",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						JSONPath: "{$.ok}",
+						OnFailureWebhook: &v1alpha1.WebMetricWebhook{
+							URL:     webhook.URL,
+							Headers: []v1alpha1.WebMetricHeader{{Key: "key", Value: "value"}},
+							Body:    test.body,
+						},
+					},
+				},
+			}
+			run := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(run, metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedNotified, notified)
+			if test.expectedNotified {
+				assert.Equal(t, test.expectedNotifyBody, notifyBody)
+			}
+		})
+	}
+}
+
+func TestOnFailureWebhookWithoutMetricCredentials(t *testing.T) {
+	tokenServer := mockOAuthServer("my-token")
+	defer tokenServer.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer my-token", req.Header.Get("Authorization"))
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": false}`)
+	}))
+	defer server.Close()
+	notified := false
+	var webhookAuthorization []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		notified = true
+		webhookAuthorization = req.Header.Values("Authorization")
+	}))
+	defer webhook.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result == true",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.ok}",
+				Authentication: v1alpha1.Authentication{
+					OAuth2: v1alpha1.OAuth2Config{
+						TokenURL:     tokenServer.URL + "/ok",
+						ClientID:     "myClientID",
+						ClientSecret: "mySecret",
+					},
+				},
+				OnFailureWebhook: &v1alpha1.WebMetricWebhook{URL: webhook.URL},
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseFailed, measurement.Phase, measurement.Message)
+	assert.True(t, notified)
+	assert.Empty(t, webhookAuthorization)
+}
@@ -2,6 +2,8 @@ package webmetric
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -10,17 +12,24 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/util/jsonpath"
 
 	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	listers "github.com/argoproj/argo-rollouts/pkg/client/listers/rollouts/v1alpha1"
+	analysisutil "github.com/argoproj/argo-rollouts/utils/analysis"
 	"github.com/argoproj/argo-rollouts/utils/evaluate"
-	metricutil "github.com/argoproj/argo-rollouts/utils/metric"
 	timeutil "github.com/argoproj/argo-rollouts/utils/time"
 )
 
@@ -29,6 +38,9 @@ const (
 	ProviderType         = "Web"
 	ContentTypeKey       = "Content-Type"
 	ContentTypeJsonValue = "application/json"
+	// AcceptEncodingValue advertises the content encodings decoded by contentDecoders, unless the metric sets another
+	// Accept-Encoding header
+	AcceptEncodingValue = "gzip, deflate, br"
 )
 
 // Provider contains all the required components to run a WebMetric query
@@ -36,7 +48,25 @@ const (
 type Provider struct {
 	logCtx     log.Entry
 	client     *http.Client
-	jsonParser *jsonpath.JSONPath
+	jsonParser JSONParser
+	// bodyFrom is the body loaded from the BodyFrom of the metric
+	bodyFrom []byte
+	// serviceURL is the URL of the Service of the ServiceRef of the metric
+	serviceURL string
+	// kubeclientset gets the ConfigMap of the BodyFrom of the metric
+	kubeclientset kubernetes.Interface
+	// services looks the Service of the ServiceRef of the metric up
+	services corelisters.ServiceLister
+	// analysisRuns looks the previous AnalysisRuns up for the PreviousRunValue of the metric
+	analysisRuns listers.AnalysisRunLister
+	// attempts are the requests of the current measurement, for the MeasurementLogLevel of the metric
+	attempts requestAttempts
+	// sinks receive every measurement, besides the MeasurementSink of the metric
+	sinks []MeasurementSink
+	// requestLog keeps the requests of the measurement when the metric has a RequestLogSize
+	requestLog *requestLog
+	// validatedResponseKey identifies the metric of the measurement for its ConditionalRequests
+	validatedResponseKey string
 }
 
 // Type indicates provider is a WebMetric provider
@@ -50,6 +80,34 @@ func (p *Provider) GetMetadata(metric v1alpha1.Metric) map[string]string {
 }
 
 func (p *Provider) Run(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) v1alpha1.Measurement {
+	if metric.Provider.Web.RequestLogSize > 0 {
+		p.requestLog = requestLogFor(requestLogKey(run, metric), int(metric.Provider.Web.RequestLogSize))
+	}
+	if metric.Provider.Web.ConditionalRequests {
+		p.validatedResponseKey = validatedResponseKey(run, metric)
+	}
+	p.attempts = requestAttempts{}
+	measurement := p.runMeasurement(run, metric)
+	if metric.Provider.Web.RetryOnInconclusive != nil {
+		measurement = p.retryOnInconclusive(run, metric, measurement)
+	}
+	if metric.Provider.Web.UseLastGoodOnError {
+		measurement = useLastGoodValue(run, metric, measurement)
+	}
+	if metric.Provider.Web.Preflight != "" {
+		measurement = withFailedStage(measurement)
+	}
+	// the message may echo a request URL or header, with its credentials
+	measurement.Message = redactMessage(measurement.Message, metric.Provider.Web)
+	p.logMeasurement(metric, measurement)
+	if metric.Provider.Web.OnFailureWebhook != nil && (measurement.Phase == v1alpha1.AnalysisPhaseFailed || measurement.Phase == v1alpha1.AnalysisPhaseError) {
+		p.notifyFailure(run, metric, measurement)
+	}
+	p.sendMeasurement(run, metric, measurement)
+	return measurement
+}
+
+func (p *Provider) runMeasurement(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) v1alpha1.Measurement {
 	startTime := timeutil.MetaNow()
 
 	// Measurement to pass back
@@ -57,95 +115,834 @@ func (p *Provider) Run(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) v1alph
 		StartedAt: &startTime,
 	}
 
-	method := v1alpha1.WebMetricMethodGet
-	if metric.Provider.Web.Method != "" {
-		method = metric.Provider.Web.Method
+	metric = withDefaults(metric)
+	if err := p.loadBodyFrom(run.Namespace, metric); err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	if err := p.loadServiceRef(run.Namespace, metric); err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	var err error
+	if metric.Provider.Web.ServiceRef != nil {
+		metric, err = p.withServiceURL(metric)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+	metric, err = resolveRunMetadata(run, metric)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	metric, err = resolveTimes(metric, startTime.Time)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	if metric.Provider.Web.CorrelationIdHeader != "" {
+		metric = withCorrelationID(run, metric)
+	}
+	if duplicates := duplicateHeaders(metric.Provider.Web); len(duplicates) > 0 {
+		p.logCtx.Warnf("Web metric headers %s are set several times, only the last value of each is sent; use headerMode add to send all the values", strings.Join(duplicates, ", "))
+	}
+
+	if metric.Provider.Web.Preflight != "" {
+		if err := p.preflight(metric); err != nil {
+			return markPreflightFailed(metric.Provider.Web, measurement, err)
+		}
+	}
+
+	body, err := p.requestBody(metric.Provider.Web)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	body, err = resolveBodyRunMetadata(run, body)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	body, err = resolveBodyPreviousValue(run, metric, body)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	body, err = resolveBodyTimes(body, startTime.Time)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	if metric.Provider.Web.GRPCWeb {
+		if method, _ := requestMethod(metric.Provider.Web); method != v1alpha1.WebMetricMethodPost {
+			return markMeasurementError(measurement, errors.New("GRPCWeb can only be used with the POST WebMetric Method type"))
+		}
+		body = grpcWebFrame(body)
+	}
+	if metric.Provider.Web.PreRequest != nil {
+		metric, err = p.withPreRequest(metric)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+
+	var firstSample *rateSample
+	if metric.Provider.Web.RateOfChange != nil {
+		firstSample, err = p.takeFirstSample(metric, body)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+
+	measurementMetric := metric
+	if metric.Provider.Web.IdempotencyKeyHeader != "" {
+		measurementMetric = withIdempotencyKey(metric)
+	}
+	response, err := p.fetchResponse(measurementMetric, body)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	if metric.Provider.Web.FollowLink != "" {
+		response, err = p.followLink(measurementMetric, response)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+	if metric.Provider.Web.AbortCondition != "" {
+		abort, err := checkAbortCondition(metric.Provider.Web, response)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+		if abort {
+			return abortMeasurement(measurement)
+		}
+	}
+
+	if err := validateResponse(metric.Provider.Web, response); err != nil {
+		measurement.Phase = v1alpha1.AnalysisPhaseFailed
+		measurement.Message = err.Error()
+		finishedTime := timeutil.MetaNow()
+		measurement.FinishedAt = &finishedTime
+		return measurement
+	}
+
+	if metric.Provider.Web.GRPCWeb {
+		measurement, err = grpcWebMeasurement(measurement, response)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+		finishedTime := timeutil.MetaNow()
+		measurement.FinishedAt = &finishedTime
+		return measurement
+	}
+
+	if metric.Provider.Web.ExpectedBody != nil {
+		measurement, err = expectedBodyMeasurement(measurement, metric.Provider.Web.ExpectedBody, response)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+		finishedTime := timeutil.MetaNow()
+		measurement.FinishedAt = &finishedTime
+		return measurement
+	}
+
+	var baseline any
+	if metric.Provider.Web.Baseline != nil {
+		baseline, err = p.fetchBaseline(metric, body)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+	var threshold any
+	if metric.Provider.Web.ThresholdUrl != "" {
+		threshold, err = p.fetchThreshold(metric)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+
+	var previousRun any
+	if metric.Provider.Web.PreviousRunValue {
+		previousRun, err = p.previousRunValue(run, metric)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+
+	value, status, err := p.parseResponse(metric, response, evaluationInputs{
+		previous:          previousValue(run, metric),
+		baseline:          baseline,
+		threshold:         threshold,
+		firstSample:       firstSample,
+		windowSampleCount: windowSampleCount(run, metric),
+		previousRun:       previousRun,
+	})
+	if err != nil {
+		return markMeasurementError(measurement, asParseError(err))
+	}
+	metadata, err := responseMetadata(metric.Provider.Web, response)
+	if err != nil {
+		return markMeasurementError(measurement, err)
+	}
+	if metric.Provider.Web.ValueSummary {
+		metadata = addValueSummary(metadata, run, metric, value)
+	}
+	if minSampleCount := metric.Provider.Web.MinSampleCount; minSampleCount != nil && minSampleCount.Window > 1 {
+		metadata = addSampleCount(metadata, metric.Provider.Web, response)
+	}
+	if metric.Provider.Web.ValueFormat != "" {
+		value, metadata, err = withFormattedValue(metric.Provider.Web, value, metadata)
+		if err != nil {
+			return markMeasurementError(measurement, err)
+		}
+	}
+	measurement.Metadata = metadata
+
+	measurement.Value = value
+	measurement.Phase = status
+	finishedTime := timeutil.MetaNow()
+	measurement.FinishedAt = &finishedTime
+
+	return measurement
+}
+
+// evaluationInputs are the values besides the response available to the conditions
+type evaluationInputs struct {
+	// previous is the value of the previous measurement of the metric
+	previous any
+	// baseline is the value of the Baseline request
+	baseline any
+	// threshold is the value of the ThresholdUrl request
+	threshold any
+	// firstSample is the first sample of the RateOfChange
+	firstSample *rateSample
+	// windowSampleCount is the sample count of the previous measurements of the MinSampleCount window
+	windowSampleCount float64
+	// previousRun is the final value of the metric in the previous AnalysisRun, for the PreviousRunValue
+	previousRun any
+}
+
+// fetchResponse fetches the response of the metric, over several requests if it is paginated
+func (p *Provider) fetchResponse(metric v1alpha1.Metric, body []byte) (*webResponse, error) {
+	if metric.Provider.Web.Pagination != nil {
+		return p.fetchPages(metric, body)
+	}
+	if len(metric.Provider.Web.FallbackUrls) > 0 {
+		return p.fetchWithFallback(metric, body)
 	}
+	return p.fetch(metric, metric.Provider.Web.URL, body)
+}
 
-	url := metric.Provider.Web.URL
+// webResponse is a response received from the web metric endpoint
+type webResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	// trailer holds the trailers received after the body
+	trailer http.Header
+	// duration is the time until the response headers were received
+	duration time.Duration
+}
 
-	stringBody := metric.Provider.Web.Body
-	jsonBody := metric.Provider.Web.JSONBody
+// requestBody returns the payload of the web metric request, or nil if it has none
+func (p *Provider) requestBody(web *v1alpha1.WebMetric) ([]byte, error) {
+	method, err := requestMethod(web)
+	if err != nil {
+		return nil, err
+	}
 
-	var body io.Reader
+	stringBody := web.Body
+	jsonBody := web.JSONBody
 
 	if stringBody != "" && jsonBody != nil {
-		return metricutil.MarkMeasurementError(measurement, fmt.Errorf("use either Body or JSONBody; both cannot exists for WebMetric payload"))
+		return nil, fmt.Errorf("use either Body or JSONBody; both cannot exists for WebMetric payload")
 	} else if (stringBody != "" || jsonBody != nil) && method == v1alpha1.WebMetricMethodGet {
-		return metricutil.MarkMeasurementError(measurement, fmt.Errorf("Body/JSONBody can only be used with POST or PUT WebMetric Method types"))
+		return nil, fmt.Errorf("Body/JSONBody can only be used with POST or PUT WebMetric Method types")
+	}
+
+	if web.BodyFrom != nil {
+		if method == v1alpha1.WebMetricMethodGet {
+			return nil, fmt.Errorf("BodyFrom can only be used with POST or PUT WebMetric Method types")
+		}
+		if p.bodyFrom == nil {
+			return nil, fmt.Errorf("BodyFrom of WebMetric was not loaded")
+		}
+		return p.bodyFrom, nil
 	}
 
 	if stringBody != "" {
-		body = strings.NewReader(stringBody)
+		return []byte(stringBody), nil
 	} else if jsonBody != nil {
-		bodyBytes, err := jsonBody.MarshalJSON()
-		if err != nil {
-			return metricutil.MarkMeasurementError(measurement, err)
-		}
-		body = bytes.NewBuffer(bodyBytes)
+		return jsonBody.MarshalJSON()
+	}
+	return nil, nil
+}
+
+// fetch sends the web metric request to the url and reads the response
+func (p *Provider) fetch(metric v1alpha1.Metric, url string, body []byte) (*webResponse, error) {
+	return p.fetchContext(context.Background(), metric, url, body)
+}
+
+// fetchContext sends the web metric request to the URL with the context
+func (p *Provider) fetchContext(ctx context.Context, metric v1alpha1.Metric, url string, body []byte) (*webResponse, error) {
+	method, err := requestMethod(metric.Provider.Web)
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
 	}
 
 	// Create request
-	request, err := http.NewRequest(string(method), url, body)
+	request, err := http.NewRequestWithContext(ctx, string(method), url, bodyReader)
 	if err != nil {
-		return metricutil.MarkMeasurementError(measurement, err)
+		return nil, err
 	}
 
 	request.Header = make(http.Header)
 
-	for _, header := range metric.Provider.Web.Headers {
-		request.Header.Set(header.Key, header.Value)
+	setHeaders(request.Header, metric.Provider.Web)
+	if metric.Provider.Web.JSONBody != nil {
+		contentType := ContentTypeJsonValue
+		if metric.Provider.Web.JSONContentType != "" {
+			contentType = metric.Provider.Web.JSONContentType
+		}
+		request.Header.Set(ContentTypeKey, contentType)
+	}
+	if metric.Provider.Web.GRPCWeb && request.Header.Get(ContentTypeKey) == "" {
+		request.Header.Set(ContentTypeKey, GRPCWebContentTypeValue)
+	}
+	if request.Header.Get("Accept-Encoding") == "" {
+		request.Header.Set("Accept-Encoding", AcceptEncodingValue)
+	}
+	if metric.Provider.Web.PromText != nil && request.Header.Get("Accept") == "" {
+		request.Header.Set("Accept", PromTextAcceptValue)
+	}
+	if metric.Provider.Web.SSE && request.Header.Get("Accept") == "" {
+		request.Header.Set("Accept", SSEAcceptValue)
+	}
+	if expected := metric.Provider.Web.ExpectedContentTypes; len(expected) > 0 && request.Header.Get("Accept") == "" {
+		request.Header.Set("Accept", strings.Join(expected, ", "))
+	}
+	if err := setCredentials(request, metric.Provider.Web); err != nil {
+		return nil, err
+	}
+
+	var response *webResponse
+	if metric.Provider.Web.ConditionalRequests {
+		response, err = p.conditionalDo(metric, request, body)
+	} else {
+		response, err = p.send(metric, request, body)
+	}
+	if err == nil && metric.Provider.Web.RetryCondition != "" {
+		if err := checkRetryCondition(metric.Provider.Web, response); err != nil {
+			return nil, err
+		}
+	}
+	return response, err
+}
+
+// send sends the request, sharing the response of an identical request in flight if the metric coalesces requests
+func (p *Provider) send(metric v1alpha1.Metric, request *http.Request, body []byte) (*webResponse, error) {
+	start := time.Now()
+	var response *webResponse
+	var err error
+	if metric.Provider.Web.Coalesce {
+		response, err = p.coalescedDo(metric, request, body)
+	} else {
+		response, err = p.do(metric, request)
 	}
-	if jsonBody != nil {
-		request.Header.Set(ContentTypeKey, ContentTypeJsonValue)
+	p.logRequest(metric, request, start, response, err)
+	p.attempts.record(request, start, response, err)
+	return response, err
+}
+
+// do sends the web metric request and reads the response
+func (p *Provider) do(metric v1alpha1.Metric, request *http.Request) (*webResponse, error) {
+	request, cancel, err := p.waitRateLimit(metric, request)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	request, release, err := p.acquireHostSlot(metric, request)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	// Send Request
+	request, span := startSpan(metric, request)
+	requestStart := time.Now()
 	response, err := p.client.Do(request)
+	err = redactCredentials(classifyDNSError(err), metric.Provider.Web)
+	endSpan(span, response, err)
 	if err != nil {
-		return metricutil.MarkMeasurementError(measurement, err)
-	} else if response.StatusCode < 200 || response.StatusCode >= 300 {
-		return metricutil.MarkMeasurementError(measurement, fmt.Errorf("received non 2xx response code: %v", response.StatusCode))
+		return nil, &connectionError{err: err}
+	}
+	defer func() {
+		// the body is drained so the connection can be reused by the next request, except an event stream which may
+		// never end
+		if !metric.Provider.Web.SSE {
+			io.Copy(io.Discard, response.Body)
+		}
+		response.Body.Close()
+	}()
+	duration := time.Since(requestStart)
+	notModified := response.StatusCode == http.StatusNotModified && metric.Provider.Web.ConditionalRequests
+	// the redirect holds the value of a Location metric
+	redirect := metric.Provider.Web.Location != nil && response.StatusCode >= 300 && response.StatusCode < 400
+	if (response.StatusCode < 200 || response.StatusCode >= 300) && !notModified && !redirect {
+		return nil, &statusCodeError{statusCode: response.StatusCode}
 	}
 
-	value, status, err := p.parseResponse(metric, response)
+	var deadline *bodyDeadline
+	if timeout := metric.Provider.Web.ResponseBodyTimeoutSeconds; timeout > 0 {
+		deadline = startBodyDeadline(response.Body, time.Duration(timeout)*time.Second)
+	}
+	bodyBytes, err := readBody(response, metric.Provider.Web.SSE)
+	if deadline != nil && deadline.stop() {
+		return nil, &connectionError{err: fmt.Errorf("the response body was not received within responseBodyTimeoutSeconds of %ds", metric.Provider.Web.ResponseBodyTimeoutSeconds)}
+	}
 	if err != nil {
-		return metricutil.MarkMeasurementError(measurement, err)
+		return nil, err
+	}
+	if !metric.Provider.Web.SSE {
+		// the trailers are only received once the body is read to the end, which a decoded body may not have reached
+		io.Copy(io.Discard, response.Body)
 	}
 
-	measurement.Value = value
-	measurement.Phase = status
-	finishedTime := timeutil.MetaNow()
-	measurement.FinishedAt = &finishedTime
+	return &webResponse{
+		statusCode: response.StatusCode,
+		header:     response.Header,
+		body:       bodyBytes,
+		trailer:    response.Trailer,
+		duration:   duration,
+	}, nil
+}
 
-	return measurement
+// readBody reads the decoded body of the response, or the data of its first event for an event stream
+func readBody(response *http.Response, sse bool) ([]byte, error) {
+	reader, err := decodeContent(response)
+	if err != nil {
+		return nil, err
+	}
+	if sse {
+		return readFirstEvent(reader)
+	}
+	bodyBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("Received no bytes in response: %v", err)
+	}
+	return bodyBytes, nil
+}
+
+// preflight checks that the preflight URL of the metric returns a 2xx response, with the headers of the metric
+func (p *Provider) preflight(metric v1alpha1.Metric) error {
+	request, err := http.NewRequest(http.MethodGet, metric.Provider.Web.Preflight, nil)
+	if err != nil {
+		return err
+	}
+	setHeaders(request.Header, metric.Provider.Web)
+	if err := setCredentials(request, metric.Provider.Web); err != nil {
+		return err
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return redactCredentials(classifyDNSError(err), metric.Provider.Web)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("received non 2xx response code: %v", response.StatusCode)
+	}
+	return nil
 }
 
-func (p *Provider) parseResponse(metric v1alpha1.Metric, response *http.Response) (string, v1alpha1.AnalysisPhase, error) {
+func (p *Provider) parseResponse(metric v1alpha1.Metric, response *webResponse, inputs evaluationInputs) (string, v1alpha1.AnalysisPhase, error) {
+	if response.statusCode == http.StatusNoContent {
+		// there is nothing to extract a value from
+		if web := metric.Provider.Web; web.JSONPath != "" || web.JSONPointer != "" || web.JSONStringPath != "" || web.PromText != nil || web.Transform != "" || web.TrailerPath != "" || web.DerivedValue != nil || web.Ratio != nil || web.Grafana != nil || web.WeightedScore != nil || web.XMLPath != "" || web.Location != nil {
+			return "", v1alpha1.AnalysisPhaseError, errors.New("received 204 No Content response, there is no value to extract")
+		}
+		return "", v1alpha1.AnalysisPhaseSuccessful, nil
+	}
+
+	if metric.Provider.Web.PromText != nil {
+		return p.parsePromTextResponse(metric, response, inputs.previous)
+	}
+	if metric.Provider.Web.TrailerPath != "" {
+		return p.parseTrailerResponse(metric, response, inputs.previous)
+	}
+	if metric.Provider.Web.Location != nil {
+		return p.parseLocationResponse(metric, response, inputs.previous)
+	}
+	if metric.Provider.Web.XMLPath != "" && isXMLResponse(response) {
+		return p.parseXMLResponse(metric, response, inputs.previous)
+	}
+
 	var data any
+	var err error
+	if expected := metric.Provider.Web.ExpectedContentTypes; len(expected) > 0 {
+		data, err = decodeNegotiatedBody(expected, response)
+		if err != nil {
+			return "", v1alpha1.AnalysisPhaseError, err
+		}
+	} else if err = json.Unmarshal(response.body, &data); err != nil {
+		// non JSON body return as string
+		return string(response.body), v1alpha1.AnalysisPhaseSuccessful, nil
+	}
+	if metric.Provider.Web.JSONStringPath != "" {
+		data, err = decodeJSONString(metric.Provider.Web, data)
+		if err != nil {
+			return "", v1alpha1.AnalysisPhaseError, err
+		}
+	} else if len(metric.Provider.Web.Decoders) > 0 {
+		return "", v1alpha1.AnalysisPhaseError, errors.New("decoders require a jsonStringPath")
+	}
+
+	// vars are the variables available to the conditions besides the result
+	vars := map[string]any{
+		"statusCode":     response.statusCode,
+		"responseTimeMs": response.duration.Milliseconds(),
+		"responseBytes":  len(response.body),
+		"body":           data,
+		"previous":       inputs.previous,
+	}
+	if metric.Provider.Web.Flatten {
+		vars["flat"] = flatten(data)
+	}
+	if metric.Provider.Web.Baseline != nil {
+		vars["baseline"] = inputs.baseline
+	}
+	if metric.Provider.Web.ThresholdUrl != "" {
+		vars["threshold"] = inputs.threshold
+	}
+	if metric.Provider.Web.PreviousRunValue {
+		vars["previousRun"] = inputs.previousRun
+	}
 
-	bodyBytes, err := io.ReadAll(response.Body)
+	if metric.Provider.Web.PendingCondition != "" {
+		// the result of a pending response may not exist yet, so the condition is evaluated against the whole body
+		pending, err := evaluate.EvalConditionWithVars(data, vars, metric.Provider.Web.PendingCondition)
+		if err != nil {
+			return "", v1alpha1.AnalysisPhaseError, asEvaluationError(err)
+		}
+		if pending {
+			return "", v1alpha1.AnalysisPhaseInconclusive, nil
+		}
+	}
+
+	val, valString, err := p.selectValue(metric.Provider.Web, data, response)
+	if errors.Is(err, errZeroDenominator) {
+		// e.g. there were no requests to tell the error ratio of
+		return "", v1alpha1.AnalysisPhaseInconclusive, nil
+	}
 	if err != nil {
-		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("Received no bytes in response: %v", err)
+		return "", v1alpha1.AnalysisPhaseError, err
+	}
+	if metric.Provider.Web.Band != nil {
+		if err := addBand(vars, metric.Provider.Web, data); err != nil {
+			return "", v1alpha1.AnalysisPhaseError, err
+		}
+	}
+	if inputs.firstSample != nil {
+		if err := addRateOfChange(vars, inputs.firstSample, val, time.Now()); err != nil {
+			return "", v1alpha1.AnalysisPhaseError, err
+		}
+	}
+	if metric.Provider.Web.MinSampleCount != nil {
+		root, err := unwrapRoot(metric.Provider.Web, data)
+		if err != nil {
+			return "", v1alpha1.AnalysisPhaseError, err
+		}
+		enough, err := hasMinSampleCount(metric.Provider.Web.MinSampleCount, root, inputs.windowSampleCount)
+		if err != nil {
+			return "", v1alpha1.AnalysisPhaseError, err
+		}
+		if !enough {
+			// the value is kept in the measurement, but is not trusted to be evaluated
+			return valString, v1alpha1.AnalysisPhaseInconclusive, nil
+		}
+	}
+
+	if metric.Provider.Web.MatchMode != "" {
+		status, err := evaluateEach(metric.Provider.Web.MatchMode, val, vars, metric, p.logCtx)
+		return valString, status, asEvaluationError(err)
 	}
+	status, err := evaluate.EvaluateResultWithVars(val, vars, metric, p.logCtx)
+	return valString, status, asEvaluationError(err)
+}
 
-	err = json.Unmarshal(bodyBytes, &data)
+func (p *Provider) parsePromTextResponse(metric v1alpha1.Metric, response *webResponse, previous any) (string, v1alpha1.AnalysisPhase, error) {
+	val, err := promTextValue(metric.Provider.Web.PromText, response.body)
 	if err != nil {
-		// non JSON body return as string
-		return string(bodyBytes), v1alpha1.AnalysisPhaseSuccessful, nil
+		return "", v1alpha1.AnalysisPhaseError, err
+	}
+
+	vars := map[string]any{
+		"statusCode":     response.statusCode,
+		"responseTimeMs": response.duration.Milliseconds(),
+		"responseBytes":  len(response.body),
+		"previous":       previous,
+	}
+	status, err := evaluate.EvaluateResultWithVars(val, vars, metric, p.logCtx)
+	return strconv.FormatFloat(val, 'f', -1, 64), status, asEvaluationError(err)
+}
+
+// selectValue returns the value of the response to evaluate: the extracted value, aggregated, transformed and coerced
+func (p *Provider) selectValue(web *v1alpha1.WebMetric, data any, response *webResponse) (any, string, error) {
+	root, err := unwrapRoot(web, data)
+	if err != nil {
+		return nil, "", err
+	}
+	var val any
+	var valString string
+	if web.DerivedValue != nil {
+		val, valString, err = deriveValue(web.DerivedValue, root)
+	} else if web.Ratio != nil {
+		val, valString, err = ratioValue(web.Ratio, root)
+	} else if web.Grafana != nil {
+		val, valString, err = grafanaValue(web.Grafana, root)
+	} else if web.WeightedScore != nil {
+		val, valString, err = weightedScore(web.WeightedScore, root)
+	} else {
+		val, valString, err = p.extractValue(web, root)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if web.Aggregation != "" {
+		val, valString, err = aggregate(web.Aggregation, val)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if web.Transform != "" {
+		val, valString, err = transform(web.Transform, val, data, response)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if web.CoerceTo != "" {
+		val, valString, err = coerce(web.CoerceTo, val)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return val, valString, nil
+}
+
+// extractValue returns the value of the JSONPointer or JSONPath of the metric in the data
+func (p *Provider) extractValue(web *v1alpha1.WebMetric, data any) (any, string, error) {
+	if web.JSONPointer != "" {
+		if web.Aggregation == v1alpha1.WebMetricAggregationMatches {
+			return nil, "", fmt.Errorf("%s aggregation requires a jsonPath", web.Aggregation)
+		}
+		val, err := resolveJSONPointer(web.JSONPointer, data)
+		if err != nil {
+			return nil, "", err
+		}
+		valBytes, err := json.Marshal(val)
+		return val, string(valBytes), err
+	}
+	if web.JSONPathMaxNodes > 0 && exceedsJSONNodes(data, web.JSONPathMaxNodes) {
+		return nil, "", fmt.Errorf("the response has more than the jsonPathMaxNodes of %d nodes to evaluate the JSONPath over", web.JSONPathMaxNodes)
 	}
 
 	fullResults, err := p.jsonParser.FindResults(data)
 	if err != nil {
-		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("Could not find JSONPath in body: %s", err)
+		return nil, "", fmt.Errorf("Could not find JSONPath in body: %s", err)
+	}
+	if web.Aggregation == v1alpha1.WebMetricAggregationMatches {
+		// the matches are counted, even if there are none or a single one
+		values := getValues(fullResults)
+		valBytes, err := json.Marshal(values)
+		return values, string(valBytes), err
+	}
+	if web.MatchMode != "" && web.Aggregation == "" {
+		// the matched values are evaluated one by one, even if there is a single one
+		values := getValues(fullResults)
+		valBytes, err := json.Marshal(values)
+		return values, string(valBytes), err
+	}
+	if web.Aggregation != "" {
+		// all the matched values are aggregated together
+		if values := getValues(fullResults); len(values) != 1 {
+			valBytes, err := json.Marshal(values)
+			return values, string(valBytes), err
+		}
+	}
+	if web.DefaultValue != "" && len(getValues(fullResults)) == 0 {
+		// the JSONPath matched nothing, e.g. a filter matching no element
+		val := textValue(web.DefaultValue)
+		valBytes, err := json.Marshal(val)
+		return val, string(valBytes), err
+	}
+	return getValue(fullResults)
+}
+
+// decodeJSONString returns the JSON document held by the string at the JSONStringPath of the data, once decoded by
+// the decoders of the metric
+func decodeJSONString(web *v1alpha1.WebMetric, data any) (any, error) {
+	parser := jsonpath.New("jsonString")
+	if err := parser.Parse(web.JSONStringPath); err != nil {
+		return nil, fmt.Errorf("invalid jsonStringPath: %v", err)
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("Could not find jsonStringPath in body: %s", err)
 	}
-	val, valString, err := getValue(fullResults)
+	val, _, err := getValue(fullResults)
 	if err != nil {
-		return "", v1alpha1.AnalysisPhaseError, err
+		return nil, err
 	}
+	encoded, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("jsonStringPath must select a string, got %T", val)
+	}
+	document, err := decode(web.Decoders, encoded)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(document, &decoded); err != nil {
+		return nil, fmt.Errorf("could not decode the JSON string at jsonStringPath: %v", err)
+	}
+	return decoded, nil
+}
 
-	status, err := evaluate.EvaluateResult(val, metric, p.logCtx)
-	return valString, status, err
+// previousValue returns the value of the last measurement of the metric which has one, or nil if there is none. The
+// raw value of a formatted value is returned
+func previousValue(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric) any {
+	measurements := analysisutil.ArrayMeasurement(run, metric.Name)
+	for i := len(measurements) - 1; i >= 0; i-- {
+		value := measurements[i].Value
+		if raw, ok := measurements[i].Metadata[RawValueMetadataKey]; ok {
+			value = raw
+		}
+		if value == "" {
+			continue
+		}
+		var previous any
+		if err := json.Unmarshal([]byte(value), &previous); err != nil {
+			// values of non JSON responses are stored as is
+			return value
+		}
+		return previous
+	}
+	return nil
+}
+
+// responseMetadata extracts the values of the metadata paths from a JSON response. Paths without a value are omitted
+func responseMetadata(web *v1alpha1.WebMetric, response *webResponse) (map[string]string, error) {
+	metadataPaths := web.MetadataPaths
+	if len(metadataPaths) == 0 {
+		return nil, nil
+	}
+	var data any
+	if err := json.Unmarshal(response.body, &data); err != nil {
+		return nil, nil
+	}
+	data, err := unwrapRoot(web, data)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]string{}
+	for name, path := range metadataPaths {
+		parser := jsonpath.New(name)
+		parser.AllowMissingKeys(true)
+		if err := parser.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid metadata path '%s': %v", name, err)
+		}
+		fullResults, err := parser.FindResults(data)
+		if err != nil {
+			return nil, fmt.Errorf("Could not find metadata path '%s' in body: %s", name, err)
+		}
+		if _, valString, err := getValue(fullResults); err == nil {
+			metadata[name] = valString
+		}
+	}
+	return metadata, nil
+}
+
+// validateResponse checks the response time and headers against the expectations of the metric, before its body is
+// evaluated
+func validateResponse(web *v1alpha1.WebMetric, response *webResponse) error {
+	if web.MaxLatencyMs > 0 && response.duration > time.Duration(web.MaxLatencyMs)*time.Millisecond {
+		return fmt.Errorf("response time %dms exceeds the maxLatencyMs of %dms", response.duration.Milliseconds(), web.MaxLatencyMs)
+	}
+
+	if expected := web.ExpectedETag; expected != "" {
+		if etag := response.header.Get("ETag"); !etagMatches(expected, etag) {
+			return fmt.Errorf("response ETag %q does not match the expected ETag %q", etag, expected)
+		}
+	}
+
+	names := make([]string, 0, len(web.RequireResponseHeaders))
+	for name := range web.RequireResponseHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values, ok := response.header[http.CanonicalHeaderKey(name)]
+		if !ok {
+			return fmt.Errorf("response header %s is missing", name)
+		}
+		expected := web.RequireResponseHeaders[name]
+		if expected != "" && !slices.Contains(values, expected) {
+			return fmt.Errorf("response header %s is %q, expected %q", name, strings.Join(values, ", "), expected)
+		}
+	}
+	return nil
+}
+
+// etagMatches compares an expected ETag with the ETag header of a response, regardless of quoting and weakness
+func etagMatches(expected, etag string) bool {
+	normalize := func(tag string) string {
+		return strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+	}
+	return etag != "" && normalize(expected) == normalize(etag)
+}
+
+// contentDecoders decode a response body by its Content-Encoding. The transport only decompresses gzip, and only
+// transparently when it negotiated the encoding itself, so the encodings of the AcceptEncodingValue, or requested
+// through the metric headers, are handled here
+var contentDecoders = map[string]func(io.Reader) (io.Reader, error){
+	"identity": func(r io.Reader) (io.Reader, error) { return r, nil },
+	"gzip":     func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"x-gzip":   func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"deflate":  func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+	"br":       func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+}
+
+// decodeContent returns a reader of the response body with all its content encodings removed
+func decodeContent(response *http.Response) (io.Reader, error) {
+	var reader io.Reader = response.Body
+	encodings := strings.Split(response.Header.Get("Content-Encoding"), ",")
+	// encodings are listed in the order they were applied
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" {
+			continue
+		}
+		decoder, ok := contentDecoders[encoding]
+		if !ok {
+			return nil, fmt.Errorf("unsupported response Content-Encoding: %s", encoding)
+		}
+		decoded, err := decoder(reader)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode %s response body: %v", encoding, err)
+		}
+		reader = decoded
+	}
+	return reader, nil
+}
+
+// getValues returns all the values matched by a JSON Path
+func getValues(fullResults [][]reflect.Value) []any {
+	values := []any{}
+	for _, results := range fullResults {
+		for _, r := range results {
+			values = append(values, r.Interface())
+		}
+	}
+	return values
 }
 
 func getValue(fullResults [][]reflect.Value) (any, string, error) {
@@ -159,6 +956,16 @@ func getValue(fullResults [][]reflect.Value) (any, string, error) {
 	return nil, "", errors.New("result of web metric produced no value")
 }
 
+// textValue decodes the text as JSON when valid, so numbers and booleans can be compared like in JSON responses, and
+// returns it as is otherwise
+func textValue(text string) any {
+	var decoded any
+	if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+		return decoded
+	}
+	return text
+}
+
 // Resume should not be used the WebMetric provider since all the work should occur in the Run method
 func (p *Provider) Resume(run *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) v1alpha1.Measurement {
 	p.logCtx.Warn("WebMetric provider should not execute the Resume method")
@@ -176,53 +983,150 @@ func (p *Provider) GarbageCollect(run *v1alpha1.AnalysisRun, metric v1alpha1.Met
 	return nil
 }
 
+// transport is shared by the clients of all web metrics. A client is created for every measurement, so sharing the
+// transport keeps idle connections to the metric endpoints alive between measurements
+var transport *http.Transport = http.DefaultTransport.(*http.Transport).Clone()
+
 var insecureTransport *http.Transport = &http.Transport{
 	TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 }
 
-func NewWebMetricHttpClient(metric v1alpha1.Metric) (*http.Client, error) {
-	var timeout time.Duration
-	var oauthCfg clientcredentials.Config
-
+// requestTimeout returns the timeout of the requests of the metric, with its defaults applied
+func requestTimeout(metric v1alpha1.Metric) time.Duration {
 	// Using a default timeout of 10 seconds
 	if metric.Provider.Web.TimeoutSeconds <= 0 {
-		timeout = time.Duration(10) * time.Second
-	} else {
-		timeout = time.Duration(metric.Provider.Web.TimeoutSeconds) * time.Second
+		return time.Duration(10) * time.Second
 	}
+	return time.Duration(metric.Provider.Web.TimeoutSeconds) * time.Second
+}
+
+func NewWebMetricHttpClient(metric v1alpha1.Metric) (*http.Client, error) {
+	metric = withDefaults(metric)
 
 	c := &http.Client{
-		Timeout: timeout,
+		Timeout:   requestTimeout(metric),
+		Transport: transport,
 	}
 	if metric.Provider.Web.Insecure {
 		c.Transport = insecureTransport
 	}
-	if metric.Provider.Web.Authentication.OAuth2.TokenURL != "" {
-		if metric.Provider.Web.Authentication.OAuth2.ClientID == "" || metric.Provider.Web.Authentication.OAuth2.ClientSecret == "" {
-			return nil, errors.New("missing mandatory parameter in metric for OAuth2 setup")
+	if tlsConfig := metric.Provider.Web.TLSConfig; len(metric.Provider.Web.InsecureHosts) > 0 ||
+		tlsConfig != nil && (len(tlsConfig.PinnedSHA256) > 0 || len(tlsConfig.SPKISHA256) > 0 || len(tlsConfig.CipherSuites) > 0 ||
+			tlsConfig.ServerName != "") {
+		if tlsConfig == nil {
+			tlsConfig = &v1alpha1.WebMetricTLSConfig{}
+		}
+		t, err := tlsTransport(tlsConfig, metric.Provider.Web.Insecure, metric.Provider.Web.InsecureHosts)
+		if err != nil {
+			return nil, err
+		}
+		c.Transport = t
+	}
+	if metric.Provider.Web.ProxyUrl != "" {
+		t, err := proxyTransport(c.Transport.(*http.Transport), metric.Provider.Web.ProxyUrl)
+		if err != nil {
+			return nil, err
+		}
+		c.Transport = t
+	}
+	if metric.Provider.Web.ForceHttp1 {
+		c.Transport = http1Transport(c.Transport.(*http.Transport))
+	}
+	if metric.Provider.Web.DisableKeepAlives {
+		c.Transport = noKeepAliveTransport(c.Transport.(*http.Transport))
+	}
+	if metric.Provider.Web.DNSCacheTTLSeconds > 0 {
+		c.Transport = dnsCachingTransport(c.Transport.(*http.Transport), time.Duration(metric.Provider.Web.DNSCacheTTLSeconds)*time.Second)
+	}
+	if web := metric.Provider.Web; web.DialTimeoutSeconds > 0 || web.TLSHandshakeTimeoutSeconds > 0 {
+		c.Transport = connectTimeoutTransport(c.Transport.(*http.Transport),
+			time.Duration(web.DialTimeoutSeconds)*time.Second, time.Duration(web.TLSHandshakeTimeoutSeconds)*time.Second)
+	}
+	if metric.Provider.Web.MaxRedirects != nil {
+		maxRedirects := int(*metric.Provider.Web.MaxRedirects)
+		c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		}
+	}
+	if metric.Provider.Web.Location != nil {
+		// the value is in the Location of the redirect
+		c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
 		}
-		oauthCfg = clientcredentials.Config{
-			ClientID:     metric.Provider.Web.Authentication.OAuth2.ClientID,
-			ClientSecret: metric.Provider.Web.Authentication.OAuth2.ClientSecret,
-			TokenURL:     metric.Provider.Web.Authentication.OAuth2.TokenURL,
-			Scopes:       metric.Provider.Web.Authentication.OAuth2.Scopes,
+	}
+	if err := validateAuthentications(metric.Provider.Web); err != nil {
+		return nil, err
+	}
+	if oauth2Cfg := oauth2Config(metric.Provider.Web); oauth2Cfg != nil {
+		// the token is fetched with a copy of the client, before its transport is wrapped with the token source
+		tokenClient := *c
+		var fetch func(ctx context.Context) (*oauth2.Token, error)
+		if oauth2Cfg.PrivateKeyJwt != nil {
+			if oauth2Cfg.ClientID == "" {
+				return nil, errors.New("missing mandatory parameter in metric for OAuth2 setup")
+			}
+			pkts, err := newPrivateKeyJWTTokenSource(context.Background(), *oauth2Cfg)
+			if err != nil {
+				return nil, err
+			}
+			fetch = pkts.tokenWithContext
+		} else {
+			if oauth2Cfg.ClientID == "" || oauth2Cfg.ClientSecret == "" {
+				return nil, errors.New("missing mandatory parameter in metric for OAuth2 setup")
+			}
+			oauthCfg := clientcredentials.Config{
+				ClientID:     oauth2Cfg.ClientID,
+				ClientSecret: oauth2Cfg.ClientSecret,
+				TokenURL:     oauth2Cfg.TokenURL,
+				Scopes:       oauth2Scopes(oauth2Cfg),
+			}
+			fetch = oauthCfg.Token
+		}
+		c.Transport = &requestTokenTransport{
+			base: c.Transport,
+			fetch: func(ctx context.Context) (*oauth2.Token, error) {
+				return fetch(context.WithValue(ctx, oauth2.HTTPClient, &tokenClient))
+			},
 		}
-		return oauthCfg.Client(context.WithValue(context.Background(), oauth2.HTTPClient, c)), nil
 	}
 	return c, nil
 }
 
-func NewWebMetricJsonParser(metric v1alpha1.Metric) (*jsonpath.JSONPath, error) {
-	jsonParser := jsonpath.New("metrics")
-	jsonPath := metric.Provider.Web.JSONPath
-	if jsonPath == "" {
-		jsonPath = "{$}"
+// JSONParser finds the values of a JSON Path in a decoded JSON document
+type JSONParser interface {
+	FindResults(data any) ([][]reflect.Value, error)
+}
+
+func NewWebMetricJsonParser(metric v1alpha1.Metric) (JSONParser, error) {
+	if metric.Provider.Web.JSONPath != "" && metric.Provider.Web.JSONPointer != "" {
+		return nil, errors.New("use either JSONPath or JSONPointer; both cannot exists for WebMetric")
+	}
+	return newJSONParser(metric.Provider.Web, "metrics", metric.Provider.Web.JSONPath)
+}
+
+// newJSONParser parses a JSON Path with the JSONPathEngine of the metric. An empty path selects the whole document
+func newJSONParser(web *v1alpha1.WebMetric, name, path string) (JSONParser, error) {
+	switch web.JSONPathEngine {
+	case "", v1alpha1.WebMetricJSONPathEngineKubernetes:
+		if path == "" {
+			path = "{$}"
+		}
+		parser := jsonpath.New(name)
+		err := parser.Parse(path)
+		return parser, err
+	case v1alpha1.WebMetricJSONPathEngineStandard:
+		if path == "" {
+			path = "$"
+		}
+		return parseStandardJSONPath(path)
 	}
-	err := jsonParser.Parse(jsonPath)
-	return jsonParser, err
+	return nil, fmt.Errorf("unknown jsonPathEngine: %s", web.JSONPathEngine)
 }
 
-func NewWebMetricProvider(logCtx log.Entry, client *http.Client, jsonParser *jsonpath.JSONPath) *Provider {
+func NewWebMetricProvider(logCtx log.Entry, client *http.Client, jsonParser JSONParser) *Provider {
 	return &Provider{
 		logCtx:     logCtx,
 		client:     client,
@@ -2,14 +2,22 @@ package webmetric
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -631,6 +639,28 @@ func TestRunSuite(t *testing.T) {
 			expectedValue:    `{"a":1,"b":true,"c":[1,2,3,4],"d":null}`,
 			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
 		},
+		// When_customJsonContentType_Then_server_gets_it_Then_Succeed
+		{
+			webServerStatus:   200,
+			webServerResponse: `{"a": 1, "b": true, "c": [1, 2, 3, 4], "d": null}`,
+			metric: v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result.a > 0 && result.b && all(result.c, {# < 5}) && result.d == nil",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						Method: v1alpha1.WebMetricMethodPost,
+						// URL:      server.URL,
+						Headers:         []v1alpha1.WebMetricHeader{{Key: "key", Value: "value"}},
+						JSONBody:        json.RawMessage(`{"data": {"type": "query"}}`),
+						JSONContentType: "application/vnd.api+json",
+					},
+				},
+			},
+			expectedMethod:   "POST",
+			expectedJsonBody: `{"data": {"type": "query"}}`,
+			expectedValue:    `{"a":1,"b":true,"c":[1,2,3,4],"d":null}`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+		},
 		// When_sendingJsonBodyWithGet_Then_Failure
 		{
 			metric: v1alpha1.Metric{
@@ -753,7 +783,11 @@ func TestRunSuite(t *testing.T) {
 			if test.expectedJsonBody != "" {
 				bodyBytes, _ := io.ReadAll(req.Body)
 				assert.Equal(t, test.expectedJsonBody, string(bodyBytes))
-				assert.Equal(t, ContentTypeJsonValue, req.Header.Get(ContentTypeKey))
+				expectedContentType := ContentTypeJsonValue
+				if test.metric.Provider.Web.JSONContentType != "" {
+					expectedContentType = test.metric.Provider.Web.JSONContentType
+				}
+				assert.Equal(t, expectedContentType, req.Header.Get(ContentTypeKey))
 			}
 
 			if test.webServerStatus < 200 || test.webServerStatus >= 300 {
@@ -915,3 +949,857 @@ func mockOAuthKOResponse(w http.ResponseWriter, r *http.Request) {
 	sc := http.StatusUnauthorized
 	w.WriteHeader(sc)
 }
+
+func TestRunWithContentEncoding(t *testing.T) {
+	body := `{"a": 1, "b": true}`
+	encode := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+	}
+
+	tests := []struct {
+		encoding             string
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedErrorMessage string
+	}{
+		{encoding: "gzip", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{encoding: "deflate", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{encoding: "deflate, gzip", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{encoding: "br", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{encoding: "br, gzip", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{encoding: "zstd", expectedPhase: v1alpha1.AnalysisPhaseError, expectedErrorMessage: "unsupported response Content-Encoding: zstd"},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "gzip, deflate, br", req.Header.Get("Accept-Encoding"))
+			var encoded bytes.Buffer
+			encoded.WriteString(body)
+			for _, encoding := range strings.Split(test.encoding, ",") {
+				encoder, ok := encode[strings.TrimSpace(encoding)]
+				if !ok {
+					continue
+				}
+				var buf bytes.Buffer
+				w := encoder(&buf)
+				w.Write(encoded.Bytes())
+				w.Close()
+				encoded = buf
+			}
+			rw.Header().Set("Content-Type", "application/json")
+			rw.Header().Set("Content-Encoding", test.encoding)
+			rw.Write(encoded.Bytes())
+		}))
+		defer server.Close()
+
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.a > 0 && result.b",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:     server.URL,
+					Headers: []v1alpha1.WebMetricHeader{{Key: "Accept-Encoding", Value: "gzip, deflate, br"}},
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, test.encoding)
+		if test.expectedPhase == v1alpha1.AnalysisPhaseSuccessful {
+			assert.Equal(t, `{"a":1,"b":true}`, measurement.Value)
+		} else {
+			assert.Equal(t, test.expectedErrorMessage, measurement.Message)
+		}
+	}
+}
+
+func TestRunWithAdvertisedBrotli(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// the metric sets no Accept-Encoding header
+		assert.Equal(t, AcceptEncodingValue, req.Header.Get("Accept-Encoding"))
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Content-Encoding", "br")
+		w := brotli.NewWriter(rw)
+		io.WriteString(w, `{"a": 1, "b": true}`)
+		w.Close()
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "result.a > 0 && result.b",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL: server.URL,
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	measurement := provider.Run(newAnalysisRun(), metric)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	assert.Equal(t, `{"a":1,"b":true}`, measurement.Value)
+}
+
+func TestRunWithMaxRedirects(t *testing.T) {
+	// /redirect/<n> redirects n times before returning the metric
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var remaining int
+		fmt.Sscanf(req.URL.Path, "/redirect/%d", &remaining)
+		if remaining > 0 {
+			http.Redirect(rw, req, fmt.Sprintf("/redirect/%d", remaining-1), http.StatusFound)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	int64Ptr := func(i int64) *int64 { return &i }
+	tests := []struct {
+		redirects            int
+		maxRedirects         *int64
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedErrorMessage string
+	}{
+		{redirects: 5, maxRedirects: nil, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{redirects: 3, maxRedirects: int64Ptr(3), expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{redirects: 4, maxRedirects: int64Ptr(3), expectedPhase: v1alpha1.AnalysisPhaseError, expectedErrorMessage: "stopped after 3 redirects"},
+		{redirects: 0, maxRedirects: int64Ptr(0), expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{redirects: 1, maxRedirects: int64Ptr(0), expectedPhase: v1alpha1.AnalysisPhaseError, expectedErrorMessage: "stopped after 0 redirects"},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:          fmt.Sprintf("%s/redirect/%d", server.URL, test.redirects),
+					MaxRedirects: test.maxRedirects,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase)
+		assert.Contains(t, measurement.Message, test.expectedErrorMessage)
+	}
+}
+
+func TestRunWithResponseVariables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusAccepted)
+		io.WriteString(rw, `{"data": {"errors": 1, "total": 100}, "status": "ok"}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		condition     string
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		{
+			condition:     `statusCode == 202 && responseTimeMs < 10000 && body.status == "ok" && result.errors / result.total < 0.05`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			condition:     `statusCode == 200 && body.status == "ok"`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+		},
+		{
+			condition:     `responseTimeMs < 0 || body.data.errors > 0`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: test.condition,
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:      server.URL,
+					JSONPath: "{$.data}",
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, test.condition)
+		assert.Equal(t, `{"errors":1,"total":100}`, measurement.Value)
+	}
+}
+
+func TestRunWithResponseBytes(t *testing.T) {
+	tests := []struct {
+		name          string
+		items         int
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		{name: "truncated export", items: 2, expectedPhase: v1alpha1.AnalysisPhaseFailed},
+		{name: "complete export", items: 200, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := make([]string, test.items)
+			for i := range items {
+				items[i] = `{"id": "item"}`
+			}
+			body := `{"items": [` + strings.Join(items, ",") + `]}`
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, body)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "responseBytes > 1000",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:         server.URL,
+						JSONPath:    "{$.items}",
+						Aggregation: v1alpha1.WebMetricAggregationCount,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, strconv.Itoa(test.items), measurement.Value)
+		})
+	}
+}
+
+func TestRunWithExpectedETag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if etag := req.URL.Query().Get("etag"); etag != "" {
+			rw.Header().Set("ETag", etag)
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		etag          string
+		expectedETag  string
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		{etag: `"abc123"`, expectedETag: "", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{etag: `"abc123"`, expectedETag: "abc123", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{etag: `"abc123"`, expectedETag: `"abc123"`, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{etag: `W/"abc123"`, expectedETag: "abc123", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{etag: `"def456"`, expectedETag: "abc123", expectedPhase: v1alpha1.AnalysisPhaseFailed},
+		{etag: "", expectedETag: "abc123", expectedPhase: v1alpha1.AnalysisPhaseFailed},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:          server.URL + "?etag=" + url.QueryEscape(test.etag),
+					ExpectedETag: test.expectedETag,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase)
+		assert.NotNil(t, measurement.FinishedAt)
+		if test.expectedPhase == v1alpha1.AnalysisPhaseFailed {
+			assert.Contains(t, measurement.Message, "does not match the expected ETag \"abc123\"")
+			assert.Empty(t, measurement.Value)
+		} else {
+			assert.Equal(t, `{"ok":true}`, measurement.Value)
+		}
+	}
+}
+
+func TestRunReusesConnections(t *testing.T) {
+	for _, insecure := range []bool{false, true} {
+		var connections atomic.Int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Query().Get("fail") != "" {
+				http.Error(rw, "failed measurement with a body to drain", http.StatusInternalServerError)
+				return
+			}
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, `{"ok": true}`)
+		}))
+		server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				connections.Add(1)
+			}
+		}
+		if insecure {
+			server.StartTLS()
+		} else {
+			server.Start()
+		}
+
+		for i, query := range []string{"", "?fail=true", "", ""} {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result.ok",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL + query,
+						Insecure: insecure,
+					},
+				},
+			}
+			// a new client and provider are created for every measurement, as done by the controller
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			if query == "" {
+				assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, "measurement %d", i)
+			} else {
+				assert.Equal(t, v1alpha1.AnalysisPhaseError, measurement.Phase, "measurement %d", i)
+			}
+		}
+		server.Close()
+		assert.Equal(t, int32(1), connections.Load(), "insecure: %v", insecure)
+	}
+}
+
+func TestRunWithMetadataPaths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"data": {"errorRate": 0.5, "errors": 5, "samples": 10, "service": "checkout"}}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		condition            string
+		metadataPaths        map[string]string
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedMetadata     map[string]string
+		expectedErrorMessage string
+	}{
+		{
+			condition:     "result < 1",
+			metadataPaths: nil,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+		},
+		{
+			condition: "result < 1",
+			metadataPaths: map[string]string{
+				"errors":  "{$.data.errors}",
+				"samples": "{$.data.samples}",
+				"service": "{$.data.service}",
+				"missing": "{$.data.missing}",
+			},
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedMetadata: map[string]string{"errors": "5", "samples": "10", "service": `"checkout"`},
+		},
+		{
+			condition:        "result < 0.1",
+			metadataPaths:    map[string]string{"errors": "{$.data.errors}"},
+			expectedPhase:    v1alpha1.AnalysisPhaseFailed,
+			expectedMetadata: map[string]string{"errors": "5"},
+		},
+		{
+			condition:            "result < 1",
+			metadataPaths:        map[string]string{"errors": "{$.data.errors"},
+			expectedPhase:        v1alpha1.AnalysisPhaseError,
+			expectedErrorMessage: "invalid metadata path 'errors'",
+		},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: test.condition,
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:           server.URL,
+					JSONPath:      "{$.data.errorRate}",
+					MetadataPaths: test.metadataPaths,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase)
+		assert.Equal(t, test.expectedMetadata, measurement.Metadata)
+		if test.expectedErrorMessage != "" {
+			assert.Contains(t, measurement.Message, test.expectedErrorMessage)
+		} else {
+			assert.Equal(t, "0.5", measurement.Value)
+		}
+	}
+}
+
+func TestRunWithPreviousValue(t *testing.T) {
+	var value string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if value == "" {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"requests": %s}`, value)
+	}))
+	defer server.Close()
+
+	metric := v1alpha1.Metric{
+		Name:             "foo",
+		SuccessCondition: "previous == nil || (result >= previous * 0.9 && result <= previous * 1.1)",
+		Provider: v1alpha1.MetricProvider{
+			Web: &v1alpha1.WebMetric{
+				URL:      server.URL,
+				JSONPath: "{$.requests}",
+			},
+		},
+	}
+	jsonparser, err := NewWebMetricJsonParser(metric)
+	assert.NoError(t, err)
+	client, err := NewWebMetricHttpClient(metric)
+	assert.NoError(t, err)
+	provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+	run := newAnalysisRun()
+	run.Status.MetricResults = []v1alpha1.MetricResult{{Name: metric.Name}}
+	tests := []struct {
+		value         string
+		expectedPhase v1alpha1.AnalysisPhase
+	}{
+		// the first measurement has no previous value
+		{value: "100", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{value: "105", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{value: "150", expectedPhase: v1alpha1.AnalysisPhaseFailed},
+		// a measurement without a value is skipped
+		{value: "", expectedPhase: v1alpha1.AnalysisPhaseError},
+		{value: "140", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+	}
+	for i, test := range tests {
+		value = test.value
+		measurement := provider.Run(run, metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, "measurement %d: %s", i, measurement.Message)
+		run.Status.MetricResults[0].Measurements = append(run.Status.MetricResults[0].Measurements, measurement)
+	}
+}
+
+func TestRunWithPreflight(t *testing.T) {
+	queried := false
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "value", req.Header.Get("key"))
+		switch req.URL.Path {
+		case "/healthz":
+			assert.Equal(t, http.MethodGet, req.Method)
+			rw.WriteHeader(http.StatusOK)
+		case "/unhealthy":
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			queried = true
+			rw.Header().Set("Content-Type", "application/json")
+			io.WriteString(rw, `{"ok": true}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		preflight            string
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedQueried      bool
+		expectedErrorMessage string
+	}{
+		{preflight: "", expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedQueried: true},
+		{preflight: server.URL + "/healthz", expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedQueried: true},
+		{
+			preflight:            server.URL + "/unhealthy",
+			expectedPhase:        v1alpha1.AnalysisPhaseInconclusive,
+			expectedErrorMessage: "preflight check failed: received non 2xx response code: 503",
+		},
+		{
+			preflight:            "http://127.0.0.1:0/healthz",
+			expectedPhase:        v1alpha1.AnalysisPhaseInconclusive,
+			expectedErrorMessage: "preflight check failed: ",
+		},
+	}
+
+	for _, test := range tests {
+		queried = false
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:       server.URL + "/query",
+					Headers:   []v1alpha1.WebMetricHeader{{Key: "key", Value: "value"}},
+					Preflight: test.preflight,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase)
+		assert.Equal(t, test.expectedQueried, queried)
+		assert.NotNil(t, measurement.FinishedAt)
+		assert.Contains(t, measurement.Message, test.expectedErrorMessage)
+	}
+}
+
+func TestRunWithPendingCondition(t *testing.T) {
+	var response string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, response)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		response         string
+		pendingCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+	}{
+		// the pending response has no errorRate to evaluate
+		{response: `{"state": "pending"}`, pendingCondition: `result.state == "pending"`, expectedPhase: v1alpha1.AnalysisPhaseInconclusive},
+		{response: `{"state": "pending"}`, pendingCondition: `body.state == "pending"`, expectedPhase: v1alpha1.AnalysisPhaseInconclusive},
+		{response: `{"state": "pending"}`, pendingCondition: "", expectedPhase: v1alpha1.AnalysisPhaseError},
+		{response: `{"state": "ready", "errorRate": 0.01}`, pendingCondition: `result.state == "pending"`, expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedValue: "0.01"},
+		{response: `{"state": "ready", "errorRate": 0.5}`, pendingCondition: `result.state == "pending"`, expectedPhase: v1alpha1.AnalysisPhaseFailed, expectedValue: "0.5"},
+		{response: `{"state": "ready", "errorRate": 0.01}`, pendingCondition: `result.state ==`, expectedPhase: v1alpha1.AnalysisPhaseError},
+	}
+
+	for _, test := range tests {
+		response = test.response
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result < 0.05",
+			FailureCondition: "result >= 0.05",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:              server.URL,
+					JSONPath:         "{$.errorRate}",
+					PendingCondition: test.pendingCondition,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+		assert.Equal(t, test.expectedValue, measurement.Value)
+	}
+}
+
+func TestRunWithRequireResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Data-Version", "v2")
+		rw.Header().Set("X-Request-Id", "1234")
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		requireHeaders       map[string]string
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedErrorMessage string
+	}{
+		{requireHeaders: map[string]string{"X-Data-Version": "v2"}, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		// header names are case insensitive, and an empty value only requires the header
+		{requireHeaders: map[string]string{"x-data-version": "v2", "X-Request-Id": ""}, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{
+			requireHeaders:       map[string]string{"X-Data-Version": "v2", "X-Backend": ""},
+			expectedPhase:        v1alpha1.AnalysisPhaseFailed,
+			expectedErrorMessage: "response header X-Backend is missing",
+		},
+		{
+			requireHeaders:       map[string]string{"X-Data-Version": "v3"},
+			expectedPhase:        v1alpha1.AnalysisPhaseFailed,
+			expectedErrorMessage: `response header X-Data-Version is "v2", expected "v3"`,
+		},
+	}
+
+	for _, test := range tests {
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result.ok",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:                    server.URL,
+					RequireResponseHeaders: test.requireHeaders,
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase)
+		assert.Equal(t, test.expectedErrorMessage, measurement.Message)
+	}
+}
+
+func TestRunWithMaxLatency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if delay, err := time.ParseDuration(req.URL.Query().Get("delay")); err == nil {
+			time.Sleep(delay)
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"errorRate": %s}`, req.URL.Query().Get("errorRate"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name                 string
+		query                string
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedErrorMessage string
+	}{
+		{name: "fast and good value", query: "errorRate=0.01", expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{name: "fast but bad value", query: "errorRate=0.2", expectedPhase: v1alpha1.AnalysisPhaseFailed},
+		{
+			name:                 "good value but slow",
+			query:                "errorRate=0.01&delay=300ms",
+			expectedPhase:        v1alpha1.AnalysisPhaseFailed,
+			expectedErrorMessage: "exceeds the maxLatencyMs of 200ms",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result < 0.05",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:          server.URL + "?" + test.query,
+						JSONPath:     "{$.errorRate}",
+						MaxLatencyMs: 200,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Contains(t, measurement.Message, test.expectedErrorMessage)
+			if test.expectedErrorMessage != "" {
+				// the conditions are not evaluated
+				assert.Empty(t, measurement.Value)
+			}
+		})
+	}
+}
+
+func TestRunWithJSONStringPath(t *testing.T) {
+	var response string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, response)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		response             string
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedValue        string
+		expectedErrorMessage string
+	}{
+		{response: `{"payload": "{\"value\": 42}"}`, expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedValue: "42"},
+		{response: `{"payload": "{\"value\": 7}"}`, expectedPhase: v1alpha1.AnalysisPhaseFailed, expectedValue: "7"},
+		{
+			response:             `{"payload": "{\"value\": 42"}`,
+			expectedPhase:        v1alpha1.AnalysisPhaseError,
+			expectedErrorMessage: "could not decode the JSON string at jsonStringPath",
+		},
+		{
+			response:             `{"payload": {"value": 42}}`,
+			expectedPhase:        v1alpha1.AnalysisPhaseError,
+			expectedErrorMessage: "jsonStringPath must select a string, got map[string]interface {}",
+		},
+		{
+			response:             `{"other": "{}"}`,
+			expectedPhase:        v1alpha1.AnalysisPhaseError,
+			expectedErrorMessage: "Could not find jsonStringPath in body",
+		},
+	}
+
+	for _, test := range tests {
+		response = test.response
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result > 10 && body.value == result",
+			Provider: v1alpha1.MetricProvider{
+				Web: &v1alpha1.WebMetric{
+					URL:            server.URL,
+					JSONStringPath: "{$.payload}",
+					JSONPath:       "{$.value}",
+				},
+			},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+		assert.Equal(t, test.expectedValue, measurement.Value)
+		assert.Contains(t, measurement.Message, test.expectedErrorMessage)
+	}
+}
+
+func TestRunWithNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		web                  v1alpha1.WebMetric
+		expectedPhase        v1alpha1.AnalysisPhase
+		expectedErrorMessage string
+	}{
+		{web: v1alpha1.WebMetric{}, expectedPhase: v1alpha1.AnalysisPhaseSuccessful},
+		{
+			web:                  v1alpha1.WebMetric{JSONPath: "{$.data}"},
+			expectedPhase:        v1alpha1.AnalysisPhaseError,
+			expectedErrorMessage: "received 204 No Content response, there is no value to extract",
+		},
+		{
+			web:                  v1alpha1.WebMetric{PromText: &v1alpha1.WebMetricPromText{Metric: "up"}},
+			expectedPhase:        v1alpha1.AnalysisPhaseError,
+			expectedErrorMessage: "received 204 No Content response, there is no value to extract",
+		},
+	}
+
+	for _, test := range tests {
+		web := test.web
+		web.Method = v1alpha1.WebMetricMethodPost
+		web.URL = server.URL
+		metric := v1alpha1.Metric{
+			Name:             "foo",
+			SuccessCondition: "result == true",
+			Provider:         v1alpha1.MetricProvider{Web: &web},
+		}
+		jsonparser, err := NewWebMetricJsonParser(metric)
+		assert.NoError(t, err)
+		client, err := NewWebMetricHttpClient(metric)
+		assert.NoError(t, err)
+		provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+		measurement := provider.Run(newAnalysisRun(), metric)
+		assert.Equal(t, test.expectedPhase, measurement.Phase)
+		assert.Equal(t, test.expectedErrorMessage, measurement.Message)
+		assert.Empty(t, measurement.Value)
+	}
+}
+
+func TestRunWithDefaultValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		io.WriteString(rw, `{"errors": [{"service": "api", "count": 7}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		service         string
+		defaultValue    string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{name: "match", service: "api", defaultValue: "0", expectedPhase: v1alpha1.AnalysisPhaseFailed, expectedValue: "7"},
+		{name: "empty match with a default", service: "web", defaultValue: "0", expectedPhase: v1alpha1.AnalysisPhaseSuccessful, expectedValue: "0"},
+		{
+			name:            "empty match without a default",
+			service:         "web",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "result of web metric produced no value",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result < 5",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:          server.URL,
+						JSONPath:     `{$.errors[?(@.service=="` + test.service + `")].count}`,
+						DefaultValue: test.defaultValue,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
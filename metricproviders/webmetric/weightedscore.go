@@ -0,0 +1,87 @@
+package webmetric
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+// weightedScore returns the weighted share of the healthy components of the response, from 0 when none is healthy
+// to 1 when all of them are
+func weightedScore(score *v1alpha1.WebMetricWeightedScore, data any) (any, string, error) {
+	components, err := scoreComponents(score, data)
+	if err != nil {
+		return nil, "", err
+	}
+	var healthy, total float64
+	for i, component := range components {
+		status, err := componentValue("statusPath", score.StatusPath, component)
+		if err != nil {
+			return nil, "", fmt.Errorf("weightedScore component %d: %v", i, err)
+		}
+		weight := 1.0
+		if score.WeightPath != "" {
+			val, err := componentValue("weightPath", score.WeightPath, component)
+			if err != nil {
+				return nil, "", fmt.Errorf("weightedScore component %d: %v", i, err)
+			}
+			var ok bool
+			if weight, ok = toFloat(val); !ok || weight < 0 {
+				return nil, "", fmt.Errorf("weightedScore component %d requires a non-negative numeric weight, got: %v", i, val)
+			}
+		}
+		total += weight
+		if slices.Contains(score.HealthyStatuses, fmt.Sprint(status)) {
+			healthy += weight
+		}
+	}
+	if total == 0 {
+		return nil, "", errors.New("weightedScore components have a total weight of 0")
+	}
+	result := healthy / total
+	return result, strconv.FormatFloat(result, 'f', -1, 64), nil
+}
+
+// scoreComponents returns the components at the ComponentsPath, whether it selects an array or each component
+func scoreComponents(score *v1alpha1.WebMetricWeightedScore, data any) ([]any, error) {
+	parser := jsonpath.New("components")
+	if err := parser.Parse(score.ComponentsPath); err != nil {
+		return nil, fmt.Errorf("invalid weightedScore componentsPath: %v", err)
+	}
+	fullResults, err := parser.FindResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("Could not find weightedScore componentsPath in body: %s", err)
+	}
+	components := getValues(fullResults)
+	if len(components) == 1 {
+		if array, ok := components[0].([]any); ok {
+			components = array
+		}
+	}
+	if len(components) == 0 {
+		return nil, errors.New("weightedScore componentsPath produced no component")
+	}
+	return components, nil
+}
+
+// componentValue returns the value at the path of the component
+func componentValue(name, path string, component any) (any, error) {
+	parser := jsonpath.New(name)
+	if err := parser.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	fullResults, err := parser.FindResults(component)
+	if err != nil {
+		return nil, fmt.Errorf("could not find %s: %s", name, err)
+	}
+	val, _, err := getValue(fullResults)
+	if err != nil {
+		return nil, fmt.Errorf("%s produced no value", name)
+	}
+	return val, nil
+}
@@ -0,0 +1,102 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestWeightedScore(t *testing.T) {
+	tests := []struct {
+		name            string
+		response        string
+		weightPath      string
+		expectedPhase   v1alpha1.AnalysisPhase
+		expectedValue   string
+		expectedMessage string
+	}{
+		{
+			name:          "fully healthy",
+			response:      `{"components": [{"status": "OK", "weight": 3}, {"status": "OK", "weight": 1}]}`,
+			weightPath:    "{.weight}",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "1",
+		},
+		{
+			name:          "degraded light component",
+			response:      `{"components": [{"status": "OK", "weight": 9}, {"status": "DOWN", "weight": 1}]}`,
+			weightPath:    "{.weight}",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.9",
+		},
+		{
+			name:          "degraded heavy component",
+			response:      `{"components": [{"status": "DEGRADED", "weight": 3}, {"status": "OK", "weight": 1}]}`,
+			weightPath:    "{.weight}",
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "0.25",
+		},
+		{
+			name:          "unweighted components",
+			response:      `{"components": [{"status": "OK"}, {"status": "HEALTHY"}, {"status": "OK"}, {"status": "DOWN"}]}`,
+			expectedPhase: v1alpha1.AnalysisPhaseFailed,
+			expectedValue: "0.75",
+		},
+		{
+			name:            "non numeric weight",
+			response:        `{"components": [{"status": "OK", "weight": "high"}]}`,
+			weightPath:      "{.weight}",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "weightedScore component 0 requires a non-negative numeric weight, got: high",
+		},
+		{
+			name:            "no weight",
+			response:        `{"components": [{"status": "OK", "weight": 0}]}`,
+			weightPath:      "{.weight}",
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "weightedScore components have a total weight of 0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: "result >= 0.8",
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL: server.URL,
+						WeightedScore: &v1alpha1.WebMetricWeightedScore{
+							ComponentsPath:  "{$.components}",
+							StatusPath:      "{.status}",
+							WeightPath:      test.weightPath,
+							HealthyStatuses: []string{"OK", "HEALTHY"},
+						},
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
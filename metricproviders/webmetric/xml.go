@@ -0,0 +1,129 @@
+package webmetric
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/evaluate"
+)
+
+const (
+	xmlAttributePrefix = "-"
+	xmlTextKey         = "#text"
+)
+
+// isXMLResponse returns whether the response is an XML document: by its Content-Type when it is XML or JSON, and by
+// attempting to parse it as JSON otherwise
+func isXMLResponse(response *webResponse) bool {
+	mediaType, _, _ := mime.ParseMediaType(response.header.Get(ContentTypeKey))
+	switch {
+	case strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml"):
+		return true
+	case strings.HasSuffix(mediaType, "/json") || strings.HasSuffix(mediaType, "+json"):
+		return false
+	}
+	return !json.Valid(response.body) && bytes.HasPrefix(bytes.TrimSpace(response.body), []byte("<"))
+}
+
+// parseXMLResponse evaluates the value selected by the XMLPath in the XML document of the response
+func (p *Provider) parseXMLResponse(metric v1alpha1.Metric, response *webResponse, previous any) (string, v1alpha1.AnalysisPhase, error) {
+	xmlParser := jsonpath.New("xml")
+	if err := xmlParser.Parse(metric.Provider.Web.XMLPath); err != nil {
+		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("invalid xmlPath: %v", err)
+	}
+	data, err := decodeXML(response.body)
+	if err != nil {
+		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("could not decode the XML response: %v", err)
+	}
+	fullResults, err := xmlParser.FindResults(data)
+	if err != nil {
+		return "", v1alpha1.AnalysisPhaseError, fmt.Errorf("Could not find xmlPath in body: %s", err)
+	}
+	val, valString, err := getValue(fullResults)
+	if err != nil {
+		return "", v1alpha1.AnalysisPhaseError, err
+	}
+
+	vars := map[string]any{
+		"statusCode":     response.statusCode,
+		"responseTimeMs": response.duration.Milliseconds(),
+		"responseBytes":  len(response.body),
+		"body":           data,
+		"previous":       previous,
+	}
+	status, err := evaluate.EvaluateResultWithVars(val, vars, metric, p.logCtx)
+	return valString, status, asEvaluationError(err)
+}
+
+// decodeXML converts the XML document to an object of its root element. Elements with neither attributes nor children
+// are their text, decoded as JSON when valid
+func decodeXML(body []byte) (map[string]any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := decodeXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{start.Name.Local: root}, nil
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	element := map[string]any{}
+	for _, attr := range start.Attr {
+		element[xmlAttributePrefix+attr.Name.Local] = textValue(attr.Value)
+	}
+	// children are the child elements by name, repeated elements becoming lists
+	children := map[string][]any{}
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			children[t.Name.Local] = append(children[t.Name.Local], child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			trimmed := strings.TrimSpace(text.String())
+			if len(start.Attr) == 0 && len(children) == 0 {
+				return textValue(trimmed), nil
+			}
+			if trimmed != "" {
+				element[xmlTextKey] = textValue(trimmed)
+			}
+			for name, values := range children {
+				if len(values) == 1 {
+					element[name] = values[0]
+				} else {
+					element[name] = values
+				}
+			}
+			return element, nil
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package webmetric
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+)
+
+func TestXMLResponse(t *testing.T) {
+	tests := []struct {
+		name             string
+		contentType      string
+		response         string
+		xmlPath          string
+		successCondition string
+		expectedPhase    v1alpha1.AnalysisPhase
+		expectedValue    string
+		expectedMessage  string
+	}{
+		{
+			name:          "json response",
+			contentType:   "application/json",
+			response:      `{"data": {"successRate": 0.99}}`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:          "xml response",
+			contentType:   "application/xml; charset=utf-8",
+			response:      `<?xml version="1.0"?><response><data><successRate>0.99</successRate></data></response>`,
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:          "xml response without content type",
+			response:      "<response>\n  <data>\n    <successRate>0.99</successRate>\n  </data>\n</response>",
+			expectedPhase: v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue: "0.99",
+		},
+		{
+			name:             "xml attributes and repeated elements",
+			contentType:      "text/xml",
+			response:         `<response status="ok"><item>1</item><item>2</item><note lang="en">fine</note></response>`,
+			xmlPath:          "{$.response}",
+			successCondition: `result['-status'] == "ok" && len(result.item) == 2 && result.note['#text'] == "fine"`,
+			expectedPhase:    v1alpha1.AnalysisPhaseSuccessful,
+			expectedValue:    `{"-status":"ok","item":[1,2],"note":{"#text":"fine","-lang":"en"}}`,
+		},
+		{
+			name:            "invalid xml",
+			contentType:     "application/xml",
+			response:        `<response><data>`,
+			expectedPhase:   v1alpha1.AnalysisPhaseError,
+			expectedMessage: "could not decode the XML response: XML syntax error on line 1: unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if test.contentType != "" {
+					rw.Header().Set("Content-Type", test.contentType)
+				}
+				io.WriteString(rw, test.response)
+			}))
+			defer server.Close()
+
+			xmlPath := test.xmlPath
+			if xmlPath == "" {
+				xmlPath = "{$.response.data.successRate}"
+			}
+			successCondition := test.successCondition
+			if successCondition == "" {
+				successCondition = "result > 0.95"
+			}
+			metric := v1alpha1.Metric{
+				Name:             "foo",
+				SuccessCondition: successCondition,
+				Provider: v1alpha1.MetricProvider{
+					Web: &v1alpha1.WebMetric{
+						URL:      server.URL,
+						JSONPath: "{$.data.successRate}",
+						XMLPath:  xmlPath,
+					},
+				},
+			}
+			jsonparser, err := NewWebMetricJsonParser(metric)
+			assert.NoError(t, err)
+			client, err := NewWebMetricHttpClient(metric)
+			assert.NoError(t, err)
+			provider := NewWebMetricProvider(*log.WithField("test", "test"), client, jsonparser)
+
+			measurement := provider.Run(newAnalysisRun(), metric)
+			assert.Equal(t, test.expectedPhase, measurement.Phase, measurement.Message)
+			assert.Equal(t, test.expectedValue, measurement.Value)
+			assert.Equal(t, test.expectedMessage, measurement.Message)
+		})
+	}
+}
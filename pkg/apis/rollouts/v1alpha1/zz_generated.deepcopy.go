@@ -74,6 +74,22 @@ func (in *ALBTrafficRouting) DeepCopy() *ALBTrafficRouting {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIKeyConfig) DeepCopyInto(out *APIKeyConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIKeyConfig.
+func (in *APIKeyConfig) DeepCopy() *APIKeyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(APIKeyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AmbassadorTrafficRouting) DeepCopyInto(out *AmbassadorTrafficRouting) {
 	*out = *in
@@ -650,6 +666,21 @@ func (in *Authentication) DeepCopyInto(out *Authentication) {
 	*out = *in
 	out.Sigv4 = in.Sigv4
 	in.OAuth2.DeepCopyInto(&out.OAuth2)
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(APIKeyConfig)
+		**out = **in
+	}
+	if in.Basic != nil {
+		in, out := &in.Basic, &out.Basic
+		*out = new(BasicAuthConfig)
+		**out = **in
+	}
+	if in.Bearer != nil {
+		in, out := &in.Bearer, &out.Bearer
+		*out = new(BearerAuthConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -679,6 +710,38 @@ func (in *AwsResourceRef) DeepCopy() *AwsResourceRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicAuthConfig) DeepCopyInto(out *BasicAuthConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuthConfig.
+func (in *BasicAuthConfig) DeepCopy() *BasicAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BearerAuthConfig) DeepCopyInto(out *BearerAuthConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BearerAuthConfig.
+func (in *BearerAuthConfig) DeepCopy() *BearerAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BearerAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BlueGreenStatus) DeepCopyInto(out *BlueGreenStatus) {
 	*out = *in
@@ -1137,6 +1200,22 @@ func (in *ClusterAnalysisTemplateList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyRef) DeepCopyInto(out *ConfigMapKeyRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyRef.
+func (in *ConfigMapKeyRef) DeepCopy() *ConfigMapKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatadogMetric) DeepCopyInto(out *DatadogMetric) {
 	*out = *in
@@ -1894,6 +1973,11 @@ func (in *OAuth2Config) DeepCopyInto(out *OAuth2Config) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.PrivateKeyJwt != nil {
+		in, out := &in.PrivateKeyJwt, &out.PrivateKeyJwt
+		*out = new(PrivateKeyJWTConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2023,6 +2107,29 @@ func (in *PreferredDuringSchedulingIgnoredDuringExecution) DeepCopy() *Preferred
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateKeyJWTConfig) DeepCopyInto(out *PrivateKeyJWTConfig) {
+	*out = *in
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateKeyJWTConfig.
+func (in *PrivateKeyJWTConfig) DeepCopy() *PrivateKeyJWTConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateKeyJWTConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusMetric) DeepCopyInto(out *PrometheusMetric) {
 	*out = *in
@@ -3128,6 +3235,152 @@ func (in *WebMetric) DeepCopyInto(out *WebMetric) {
 		copy(*out, *in)
 	}
 	in.Authentication.DeepCopyInto(&out.Authentication)
+	if in.MaxRedirects != nil {
+		in, out := &in.MaxRedirects, &out.MaxRedirects
+		*out = new(int64)
+		**out = **in
+	}
+	if in.OnFailureWebhook != nil {
+		in, out := &in.OnFailureWebhook, &out.OnFailureWebhook
+		*out = new(WebMetricWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pagination != nil {
+		in, out := &in.Pagination, &out.Pagination
+		*out = new(WebMetricPagination)
+		**out = **in
+	}
+	if in.BodyFrom != nil {
+		in, out := &in.BodyFrom, &out.BodyFrom
+		*out = new(WebMetricBodyFrom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetadataPaths != nil {
+		in, out := &in.MetadataPaths, &out.MetadataPaths
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PromText != nil {
+		in, out := &in.PromText, &out.PromText
+		*out = new(WebMetricPromText)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequireResponseHeaders != nil {
+		in, out := &in.RequireResponseHeaders, &out.RequireResponseHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(WebMetricRateLimit)
+		**out = **in
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(WebMetricTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MeasurementSink != nil {
+		in, out := &in.MeasurementSink, &out.MeasurementSink
+		*out = new(WebMetricMeasurementSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authentications != nil {
+		in, out := &in.Authentications, &out.Authentications
+		*out = make([]Authentication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MinSampleCount != nil {
+		in, out := &in.MinSampleCount, &out.MinSampleCount
+		*out = new(WebMetricMinSampleCount)
+		**out = **in
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		*out = new(WebMetricBaseline)
+		**out = **in
+	}
+	if in.RateOfChange != nil {
+		in, out := &in.RateOfChange, &out.RateOfChange
+		*out = new(WebMetricRateOfChange)
+		**out = **in
+	}
+	if in.DerivedValue != nil {
+		in, out := &in.DerivedValue, &out.DerivedValue
+		*out = new(WebMetricDerivedValue)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FallbackUrls != nil {
+		in, out := &in.FallbackUrls, &out.FallbackUrls
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Location != nil {
+		in, out := &in.Location, &out.Location
+		*out = new(WebMetricLocation)
+		**out = **in
+	}
+	if in.PreRequest != nil {
+		in, out := &in.PreRequest, &out.PreRequest
+		*out = new(WebMetricPreRequest)
+		**out = **in
+	}
+	if in.Band != nil {
+		in, out := &in.Band, &out.Band
+		*out = new(WebMetricBand)
+		**out = **in
+	}
+	if in.InsecureHosts != nil {
+		in, out := &in.InsecureHosts, &out.InsecureHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Grafana != nil {
+		in, out := &in.Grafana, &out.Grafana
+		*out = new(WebMetricGrafana)
+		**out = **in
+	}
+	if in.Decoders != nil {
+		in, out := &in.Decoders, &out.Decoders
+		*out = make([]WebMetricDecoder, len(*in))
+		copy(*out, *in)
+	}
+	if in.WeightedScore != nil {
+		in, out := &in.WeightedScore, &out.WeightedScore
+		*out = new(WebMetricWeightedScore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpectedBody != nil {
+		in, out := &in.ExpectedBody, &out.ExpectedBody
+		*out = new(WebMetricExpectedBody)
+		**out = **in
+	}
+	if in.ServiceRef != nil {
+		in, out := &in.ServiceRef, &out.ServiceRef
+		*out = new(WebMetricServiceRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpectedContentTypes != nil {
+		in, out := &in.ExpectedContentTypes, &out.ExpectedContentTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryOnInconclusive != nil {
+		in, out := &in.RetryOnInconclusive, &out.RetryOnInconclusive
+		*out = new(WebMetricRetryOnInconclusive)
+		**out = **in
+	}
+	if in.Ratio != nil {
+		in, out := &in.Ratio, &out.Ratio
+		*out = new(WebMetricRatio)
+		**out = **in
+	}
 	return
 }
 
@@ -3141,6 +3394,114 @@ func (in *WebMetric) DeepCopy() *WebMetric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricBand) DeepCopyInto(out *WebMetricBand) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricBand.
+func (in *WebMetricBand) DeepCopy() *WebMetricBand {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricBand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricBaseline) DeepCopyInto(out *WebMetricBaseline) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricBaseline.
+func (in *WebMetricBaseline) DeepCopy() *WebMetricBaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricBaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricBodyFrom) DeepCopyInto(out *WebMetricBodyFrom) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(ConfigMapKeyRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricBodyFrom.
+func (in *WebMetricBodyFrom) DeepCopy() *WebMetricBodyFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricBodyFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricDerivedValue) DeepCopyInto(out *WebMetricDerivedValue) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricDerivedValue.
+func (in *WebMetricDerivedValue) DeepCopy() *WebMetricDerivedValue {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricDerivedValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricExpectedBody) DeepCopyInto(out *WebMetricExpectedBody) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricExpectedBody.
+func (in *WebMetricExpectedBody) DeepCopy() *WebMetricExpectedBody {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricExpectedBody)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricGrafana) DeepCopyInto(out *WebMetricGrafana) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricGrafana.
+func (in *WebMetricGrafana) DeepCopy() *WebMetricGrafana {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricGrafana)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebMetricHeader) DeepCopyInto(out *WebMetricHeader) {
 	*out = *in
@@ -3157,6 +3518,272 @@ func (in *WebMetricHeader) DeepCopy() *WebMetricHeader {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricLocation) DeepCopyInto(out *WebMetricLocation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricLocation.
+func (in *WebMetricLocation) DeepCopy() *WebMetricLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricMeasurementSink) DeepCopyInto(out *WebMetricMeasurementSink) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]WebMetricHeader, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricMeasurementSink.
+func (in *WebMetricMeasurementSink) DeepCopy() *WebMetricMeasurementSink {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricMeasurementSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricMinSampleCount) DeepCopyInto(out *WebMetricMinSampleCount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricMinSampleCount.
+func (in *WebMetricMinSampleCount) DeepCopy() *WebMetricMinSampleCount {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricMinSampleCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricPagination) DeepCopyInto(out *WebMetricPagination) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricPagination.
+func (in *WebMetricPagination) DeepCopy() *WebMetricPagination {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricPagination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricPreRequest) DeepCopyInto(out *WebMetricPreRequest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricPreRequest.
+func (in *WebMetricPreRequest) DeepCopy() *WebMetricPreRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricPreRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricPromText) DeepCopyInto(out *WebMetricPromText) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricPromText.
+func (in *WebMetricPromText) DeepCopy() *WebMetricPromText {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricPromText)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricRateLimit) DeepCopyInto(out *WebMetricRateLimit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricRateLimit.
+func (in *WebMetricRateLimit) DeepCopy() *WebMetricRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricRateOfChange) DeepCopyInto(out *WebMetricRateOfChange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricRateOfChange.
+func (in *WebMetricRateOfChange) DeepCopy() *WebMetricRateOfChange {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricRateOfChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricRatio) DeepCopyInto(out *WebMetricRatio) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricRatio.
+func (in *WebMetricRatio) DeepCopy() *WebMetricRatio {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricRatio)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricRetryOnInconclusive) DeepCopyInto(out *WebMetricRetryOnInconclusive) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricRetryOnInconclusive.
+func (in *WebMetricRetryOnInconclusive) DeepCopy() *WebMetricRetryOnInconclusive {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricRetryOnInconclusive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricServiceRef) DeepCopyInto(out *WebMetricServiceRef) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricServiceRef.
+func (in *WebMetricServiceRef) DeepCopy() *WebMetricServiceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricServiceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricTLSConfig) DeepCopyInto(out *WebMetricTLSConfig) {
+	*out = *in
+	if in.PinnedSHA256 != nil {
+		in, out := &in.PinnedSHA256, &out.PinnedSHA256
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SPKISHA256 != nil {
+		in, out := &in.SPKISHA256, &out.SPKISHA256
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricTLSConfig.
+func (in *WebMetricTLSConfig) DeepCopy() *WebMetricTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricWebhook) DeepCopyInto(out *WebMetricWebhook) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]WebMetricHeader, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricWebhook.
+func (in *WebMetricWebhook) DeepCopy() *WebMetricWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebMetricWeightedScore) DeepCopyInto(out *WebMetricWeightedScore) {
+	*out = *in
+	if in.HealthyStatuses != nil {
+		in, out := &in.HealthyStatuses, &out.HealthyStatuses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebMetricWeightedScore.
+func (in *WebMetricWeightedScore) DeepCopy() *WebMetricWeightedScore {
+	if in == nil {
+		return nil
+	}
+	out := new(WebMetricWeightedScore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WeightDestination) DeepCopyInto(out *WeightDestination) {
 	*out = *in
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/strings/slices"
 
+	"github.com/argoproj/argo-rollouts/metricproviders/webmetric"
 	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
 	"github.com/argoproj/argo-rollouts/rollout/trafficrouting/ambassador"
 	"github.com/argoproj/argo-rollouts/rollout/trafficrouting/appmesh"
@@ -584,6 +585,10 @@ func validateAnalysisMetrics(metrics []v1alpha1.Metric, args []v1alpha1.Argument
 	}
 
 	for i, metric := range metrics {
+		// the undeclared args of web metrics are all reported at once, rather than the first one failing to resolve
+		if err := webmetric.ValidateArgPlaceholders(metric, args); err != nil {
+			return nil, err
+		}
 		resolvedMetric, err := analysisutil.ResolveMetricArgs(metric, args)
 		if err != nil {
 			return nil, err
@@ -748,6 +748,31 @@ func TestValidateAnalysisTemplateWithType(t *testing.T) {
 		assert.Empty(t, allErrs)
 	})
 
+	t.Run("validate web metric analysisTemplate arguments - failure", func(t *testing.T) {
+		rollout := getAlbRollout("alb-ingress")
+		template := getAnalysisTemplatesWithType()
+		template.AnalysisTemplates[0].Spec.Args = []v1alpha1.Argument{
+			{
+				Name: "service-name",
+			},
+		}
+		template.AnalysisTemplates[0].Spec.Metrics[0].Provider.Web = &v1alpha1.WebMetric{
+			URL: "http://my-server.com/api/v1/measurement?service={{ args.service-name }}&env={{ args.env }}",
+			Headers: []v1alpha1.WebMetricHeader{
+				{Key: "X-Team", Value: "{{args.team}}"},
+			},
+		}
+		allErrs := ValidateAnalysisTemplateWithType(rollout, template.AnalysisTemplates[0], nil, template.TemplateType, GetAnalysisTemplateWithTypeFieldPath(template.TemplateType, template.CanaryStepIndex))
+		assert.Len(t, allErrs, 1)
+		msg := "AnalysisTemplate analysis-template-name: web metric metric1-name references undeclared args: {{ args.env }}, {{args.team}}"
+		expectedError := field.Invalid(GetAnalysisTemplateWithTypeFieldPath(template.TemplateType, template.CanaryStepIndex), template.AnalysisTemplates[0].Name, msg)
+		assert.Equal(t, expectedError.Error(), allErrs[0].Error())
+
+		template.AnalysisTemplates[0].Spec.Args = append(template.AnalysisTemplates[0].Spec.Args, v1alpha1.Argument{Name: "env"}, v1alpha1.Argument{Name: "team"})
+		allErrs = ValidateAnalysisTemplateWithType(rollout, template.AnalysisTemplates[0], nil, template.TemplateType, GetAnalysisTemplateWithTypeFieldPath(template.TemplateType, template.CanaryStepIndex))
+		assert.Empty(t, allErrs)
+	})
+
 	t.Run("validate background analysisTemplate - failure", func(t *testing.T) {
 		rollout := getAlbRollout("alb-ingress")
 		template := getAnalysisTemplatesWithType()
@@ -151,6 +151,31 @@ export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1ALBTrafficR
      */
     ingresses?: Array<string>;
 }
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1APIKeyConfig
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1APIKeyConfig {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1APIKeyConfig
+     */
+    key?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1APIKeyConfig
+     */
+    in?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1APIKeyConfig
+     */
+    name?: string;
+}
 /**
  * 
  * @export
@@ -521,6 +546,24 @@ export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authenticat
      * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authentication
      */
     oauth2?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1OAuth2Config;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1APIKeyConfig}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authentication
+     */
+    apiKey?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1APIKeyConfig;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BasicAuthConfig}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authentication
+     */
+    basic?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BasicAuthConfig;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BearerAuthConfig}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authentication
+     */
+    bearer?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BearerAuthConfig;
 }
 /**
  * 
@@ -547,6 +590,38 @@ export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1AwsResource
      */
     fullName?: string;
 }
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BasicAuthConfig
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BasicAuthConfig {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BasicAuthConfig
+     */
+    username?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BasicAuthConfig
+     */
+    password?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BearerAuthConfig
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BearerAuthConfig {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1BearerAuthConfig
+     */
+    tokenFile?: string;
+}
 /**
  * 
  * @export
@@ -1013,6 +1088,25 @@ export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1CloudWatchM
      */
     value?: string;
 }
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1ConfigMapKeyRef
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1ConfigMapKeyRef {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1ConfigMapKeyRef
+     */
+    name?: string;
+    /**
+     * Key is the key of the ConfigMap to select from.
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1ConfigMapKeyRef
+     */
+    key?: string;
+}
 /**
  * 
  * @export
@@ -1742,6 +1836,12 @@ export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1OAuth2Confi
      * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1OAuth2Config
      */
     scopes?: Array<string>;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1OAuth2Config
+     */
+    privateKeyJwt?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig;
 }
 /**
  * 
@@ -1863,6 +1963,37 @@ export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PreferredDu
      */
     weight?: number;
 }
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig
+     */
+    privateKey?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig
+     */
+    keyId?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig
+     */
+    algorithm?: string;
+    /**
+     * 
+     * @type {{ [key: string]: string; }}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1PrivateKeyJWTConfig
+     */
+    claims?: { [key: string]: string; };
+}
 /**
  * 
  * @export
@@ -3192,25 +3323,915 @@ export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric {
      * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
      */
     authentication?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authentication;
-}
-/**
- * 
- * @export
- * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader
- */
-export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader {
     /**
      * 
      * @type {string}
-     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
      */
-    key?: string;
+    maxRedirects?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWebhook}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    onFailureWebhook?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWebhook;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    pagination?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination;
     /**
      * 
      * @type {string}
-     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
      */
-    value?: string;
+    expectedETag?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBodyFrom}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    bodyFrom?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBodyFrom;
+    /**
+     * 
+     * @type {{ [key: string]: string; }}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    metadataPaths?: { [key: string]: string; };
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    preflight?: string;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    flatten?: boolean;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    pendingCondition?: string;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    coalesce?: boolean;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPromText}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    promText?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPromText;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    dnsCacheTTLSeconds?: string;
+    /**
+     * 
+     * @type {{ [key: string]: string; }}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    requireResponseHeaders?: { [key: string]: string; };
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    jsonStringPath?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateLimit}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    rateLimit?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateLimit;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    jsonPointer?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    tlsConfig?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    conditionalRequests?: boolean;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    aggregation?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    transform?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMeasurementSink}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    measurementSink?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMeasurementSink;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    jsonContentType?: string;
+    /**
+     * 
+     * @type {Array<GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authentication>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    authentications?: Array<GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1Authentication>;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    trailerPath?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMinSampleCount}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    minSampleCount?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMinSampleCount;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBaseline}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    baseline?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBaseline;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    disableKeepAlives?: boolean;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateOfChange}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    rateOfChange?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateOfChange;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    grpcWeb?: boolean;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricDerivedValue}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    derivedValue?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricDerivedValue;
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    fallbackUrls?: Array<string>;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    dialTimeoutSeconds?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    tlsHandshakeTimeoutSeconds?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    xmlPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    idempotencyKeyHeader?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricLocation}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    location?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricLocation;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    rootPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    thresholdUrl?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    thresholdJsonPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    jsonPathEngine?: string;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    valueSummary?: boolean;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    preRequest?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    requestLogSize?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBand}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    band?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBand;
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    insecureHosts?: Array<string>;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    retryCondition?: string;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    forceHttp1?: boolean;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricGrafana}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    grafana?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricGrafana;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    headerMode?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    valueFormat?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    maxLatencyMs?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    correlationIdHeader?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    responseBodyTimeoutSeconds?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    coerceTo?: string;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    sse?: boolean;
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    decoders?: Array<string>;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    maxConcurrency?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    defaultValue?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    matchMode?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    weightedScore?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    proxyUrl?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricExpectedBody}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    expectedBody?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricExpectedBody;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    abortCondition?: string;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    serviceRef?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    previousRunValue?: boolean;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    measurementLogLevel?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    followLink?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    jsonPathMaxNodes?: string;
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    expectedContentTypes?: Array<string>;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRetryOnInconclusive}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    retryOnInconclusive?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRetryOnInconclusive;
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRatio}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    ratio?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRatio;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    useLastGoodOnError?: boolean;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetric
+     */
+    preflightFailurePhase?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBand
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBand {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBand
+     */
+    lowerBoundJsonPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBand
+     */
+    upperBoundJsonPath?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBaseline
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBaseline {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBaseline
+     */
+    url?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBaseline
+     */
+    jsonPath?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBodyFrom
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBodyFrom {
+    /**
+     * 
+     * @type {GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1ConfigMapKeyRef}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricBodyFrom
+     */
+    configMapKeyRef?: GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1ConfigMapKeyRef;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricDerivedValue
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricDerivedValue {
+    /**
+     * 
+     * @type {{ [key: string]: string; }}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricDerivedValue
+     */
+    paths?: { [key: string]: string; };
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricDerivedValue
+     */
+    expression?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricExpectedBody
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricExpectedBody {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricExpectedBody
+     */
+    equals?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricExpectedBody
+     */
+    regex?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricGrafana
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricGrafana {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricGrafana
+     */
+    refId?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader
+     */
+    key?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader
+     */
+    value?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricLocation
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricLocation {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricLocation
+     */
+    queryParam?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMeasurementSink
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMeasurementSink {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMeasurementSink
+     */
+    url?: string;
+    /**
+     * 
+     * @type {Array<GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMeasurementSink
+     */
+    headers?: Array<GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader>;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMinSampleCount
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMinSampleCount {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMinSampleCount
+     */
+    jsonPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMinSampleCount
+     */
+    count?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricMinSampleCount
+     */
+    window?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination
+     */
+    cursorPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination
+     */
+    cursorParam?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination
+     */
+    itemsPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination
+     */
+    maxPages?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPagination
+     */
+    maxTotalBytes?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest
+     */
+    url?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest
+     */
+    header?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest
+     */
+    fromHeader?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest
+     */
+    fromCookie?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest
+     */
+    fromJsonPath?: string;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPreRequest
+     */
+    forwardCookies?: boolean;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPromText
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPromText {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPromText
+     */
+    metric?: string;
+    /**
+     * 
+     * @type {{ [key: string]: string; }}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricPromText
+     */
+    labels?: { [key: string]: string; };
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateLimit
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateLimit {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateLimit
+     */
+    requestsPerSecond?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateLimit
+     */
+    burst?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateOfChange
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateOfChange {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRateOfChange
+     */
+    delay?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRatio
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRatio {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRatio
+     */
+    numeratorPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRatio
+     */
+    denominatorPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRatio
+     */
+    zeroDenominator?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRetryOnInconclusive
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRetryOnInconclusive {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRetryOnInconclusive
+     */
+    count?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricRetryOnInconclusive
+     */
+    delay?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef
+     */
+    name?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef
+     */
+    namespace?: string;
+    /**
+     * 
+     * @type {K8sIoApimachineryPkgUtilIntstrIntOrString}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef
+     */
+    port?: K8sIoApimachineryPkgUtilIntstrIntOrString;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricServiceRef
+     */
+    scheme?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig {
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig
+     */
+    pinnedSHA256?: Array<string>;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig
+     */
+    serverName?: string;
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig
+     */
+    spkiSHA256?: Array<string>;
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricTLSConfig
+     */
+    cipherSuites?: Array<string>;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWebhook
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWebhook {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWebhook
+     */
+    url?: string;
+    /**
+     * 
+     * @type {Array<GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWebhook
+     */
+    headers?: Array<GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricHeader>;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWebhook
+     */
+    body?: string;
+}
+/**
+ * 
+ * @export
+ * @interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore
+ */
+export interface GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore {
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore
+     */
+    componentsPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore
+     */
+    statusPath?: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore
+     */
+    weightPath?: string;
+    /**
+     * 
+     * @type {Array<string>}
+     * @memberof GithubComArgoprojArgoRolloutsPkgApisRolloutsV1alpha1WebMetricWeightedScore
+     */
+    healthyStatuses?: Array<string>;
 }
 /**
  * 
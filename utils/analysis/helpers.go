@@ -77,6 +77,15 @@ func Worst(left, right v1alpha1.AnalysisPhase) v1alpha1.AnalysisPhase {
 	return left
 }
 
+// AbortMetadataKey is the metadata key set to "true" by the metric providers on a Failed measurement which aborts its
+// analysis run: the metric fails at once, regardless of its failureLimit, which terminates the run
+const AbortMetadataKey = "abort"
+
+// IsAbortMeasurement returns whether the measurement aborts its analysis run
+func IsAbortMeasurement(measurement v1alpha1.Measurement) bool {
+	return measurement.Phase == v1alpha1.AnalysisPhaseFailed && measurement.Metadata[AbortMetadataKey] == "true"
+}
+
 // IsTerminating returns whether or not the analysis run is terminating, either because a terminate
 // was requested explicitly, or because a metric has already measured Failed, Error, or Inconclusive
 // which causes the run to end prematurely.
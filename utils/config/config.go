@@ -10,6 +10,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
 	"github.com/argoproj/argo-rollouts/utils/defaults"
 	"github.com/argoproj/argo-rollouts/utils/plugin/types"
 	v1 "k8s.io/api/core/v1"
@@ -19,9 +20,17 @@ import (
 
 // Config is the in memory representation of the configmap with some additional fields/functions for ease of use.
 type Config struct {
-	configMap *v1.ConfigMap
-	plugins   []types.PluginItem
-	lock      *sync.RWMutex
+	configMap         *v1.ConfigMap
+	plugins           []types.PluginItem
+	webMetricDefaults WebMetricDefaults
+	lock              *sync.RWMutex
+}
+
+// WebMetricDefaults are the headers and authentication inherited by every web metric. The settings of the metric take
+// precedence over them
+type WebMetricDefaults struct {
+	Headers        []v1alpha1.WebMetricHeader `json:"headers,omitempty"`
+	Authentication v1alpha1.Authentication    `json:"authentication,omitempty"`
 }
 
 var configMemoryCache *Config
@@ -69,11 +78,17 @@ func InitializeConfig(k8sClientset kubernetes.Interface, configMapName string) (
 		stepPlugins[i].Type = types.PluginTypeStep
 	}
 
+	var webMetricDefaults WebMetricDefaults
+	if err = yaml.Unmarshal([]byte(configMapCluster.Data["webMetricDefaults"]), &webMetricDefaults); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal web metric defaults while initializing: %w", err)
+	}
+
 	mutex.Lock()
 	configMemoryCache = &Config{
-		configMap: configMapCluster,
-		plugins:   slices.Concat(trafficRouterPlugins, metricProviderPlugins, stepPlugins),
-		lock:      &sync.RWMutex{},
+		configMap:         configMapCluster,
+		plugins:           slices.Concat(trafficRouterPlugins, metricProviderPlugins, stepPlugins),
+		webMetricDefaults: webMetricDefaults,
+		lock:              &sync.RWMutex{},
 	}
 	mutex.Unlock()
 
@@ -121,6 +136,13 @@ func (c *Config) GetPlugin(name string, pluginType types.PluginType) *types.Plug
 	return nil
 }
 
+// GetWebMetricDefaults returns the headers and authentication inherited by every web metric
+func (c *Config) GetWebMetricDefaults() WebMetricDefaults {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.webMetricDefaults
+}
+
 func (c *Config) ValidateConfig() error {
 	for _, pluginItem := range c.GetAllPlugins() {
 		matches := re.FindAllStringSubmatch(pluginItem.Name, -1)
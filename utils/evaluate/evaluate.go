@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/conf"
 	"github.com/antonmedv/expr/file"
 	"github.com/sirupsen/logrus"
 
@@ -16,18 +17,24 @@ import (
 )
 
 func EvaluateResult(result any, metric v1alpha1.Metric, logCtx logrus.Entry) (v1alpha1.AnalysisPhase, error) {
+	return EvaluateResultWithVars(result, nil, metric, logCtx)
+}
+
+// EvaluateResultWithVars evaluates the metric conditions like EvaluateResult, with additional variables available
+// to the expressions
+func EvaluateResultWithVars(result any, vars map[string]any, metric v1alpha1.Metric, logCtx logrus.Entry) (v1alpha1.AnalysisPhase, error) {
 	successCondition := false
 	failCondition := false
 	var err error
 
 	if metric.SuccessCondition != "" {
-		successCondition, err = EvalCondition(result, metric.SuccessCondition)
+		successCondition, err = EvalConditionWithVars(result, vars, metric.SuccessCondition)
 		if err != nil {
 			return v1alpha1.AnalysisPhaseError, err
 		}
 	}
 	if metric.FailureCondition != "" {
-		failCondition, err = EvalCondition(result, metric.FailureCondition)
+		failCondition, err = EvalConditionWithVars(result, vars, metric.FailureCondition)
 		if err != nil {
 			return v1alpha1.AnalysisPhaseError, err
 		}
@@ -92,17 +99,26 @@ func EvalTime(expression string) (time.Time, error) {
 
 // EvalCondition evaluates the condition with the resultValue as an input
 func EvalCondition(resultValue any, condition string) (bool, error) {
+	return EvalConditionWithVars(resultValue, nil, condition)
+}
+
+// EvalConditionWithVars evaluates the condition with the resultValue and additional variables as an input.
+// Variables cannot override the result or the builtin functions. Nil variables are typed as any, so that conditions
+// like "previous == nil || result > previous" compile
+func EvalConditionWithVars(resultValue any, vars map[string]any, condition string) (bool, error) {
 	var err error
 
-	env := map[string]any{
-		"result":  valueFromPointer(resultValue),
-		"asInt":   asInt,
-		"asFloat": asFloat,
-		"isNaN":   math.IsNaN,
-		"isInf":   isInf,
-		"isNil":   isNilFunc(resultValue),
-		"default": defaultFunc(resultValue),
+	env := make(map[string]any, len(vars)+7)
+	for k, v := range vars {
+		env[k] = v
 	}
+	env["result"] = valueFromPointer(resultValue)
+	env["asInt"] = asInt
+	env["asFloat"] = asFloat
+	env["isNaN"] = math.IsNaN
+	env["isInf"] = isInf
+	env["isNil"] = isNilFunc(resultValue)
+	env["default"] = defaultFunc(resultValue)
 
 	unwrapFileErr := func(e error) error {
 		if fileErr, ok := err.(*file.Error); ok {
@@ -111,7 +127,14 @@ func EvalCondition(resultValue any, condition string) (bool, error) {
 		return e
 	}
 
-	program, err := expr.Compile(condition, expr.Env(env))
+	options := []expr.Option{expr.Env(env)}
+	for k, v := range vars {
+		if v == nil && env[k] == nil {
+			options = append(options, anyVariable(k))
+		}
+	}
+
+	program, err := expr.Compile(condition, options...)
 	if err != nil {
 		return false, unwrapFileErr(err)
 	}
@@ -129,6 +152,13 @@ func EvalCondition(resultValue any, condition string) (bool, error) {
 	}
 }
 
+// anyVariable types a variable as any, instead of the nil type its nil value has in the environment
+func anyVariable(name string) expr.Option {
+	return func(c *conf.Config) {
+		c.Types[name] = conf.Tag{Type: reflect.TypeOf((*any)(nil)).Elem()}
+	}
+}
+
 func isInf(f float64) bool {
 	return math.IsInf(f, 0)
 }
@@ -114,6 +114,36 @@ func TestEvaluateConditionWithFailure(t *testing.T) {
 	assert.False(t, b)
 }
 
+func TestEvaluateConditionWithVars(t *testing.T) {
+	vars := map[string]any{"statusCode": 200, "result": "shadowed"}
+	b, err := EvalConditionWithVars(1, vars, "result == 1 && statusCode == 200")
+	assert.Nil(t, err)
+	assert.True(t, b)
+
+	// nil variables can be guarded
+	condition := "previous == nil || result > previous * 2"
+	b, err = EvalConditionWithVars(1.0, map[string]any{"previous": nil}, condition)
+	assert.Nil(t, err)
+	assert.True(t, b)
+	b, err = EvalConditionWithVars(1.0, map[string]any{"previous": 1.0}, condition)
+	assert.Nil(t, err)
+	assert.False(t, b)
+}
+
+func TestEvaluateResultWithVars(t *testing.T) {
+	logCtx := logrus.WithField("test", "test")
+	metric := v1alpha1.Metric{
+		SuccessCondition: "result > baseline",
+		FailureCondition: "result <= baseline",
+	}
+	status, err := EvaluateResultWithVars(2, map[string]any{"baseline": 1}, metric, *logCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.AnalysisPhaseSuccessful, status)
+	status, err = EvaluateResultWithVars(1, map[string]any{"baseline": 1}, metric, *logCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.AnalysisPhaseFailed, status)
+}
+
 func TestErrorWithNonBoolReturn(t *testing.T) {
 	b, err := EvalCondition(true, "1")
 	assert.Equal(t, fmt.Errorf("expected bool, but got int"), err)